@@ -22,13 +22,17 @@ type ResourceType string
 type ResourceCategory string
 
 const (
-	Dashboard ResourceCategory = "Dashboard"
-	Component ResourceCategory = "Component"
+	Dashboard  ResourceCategory = "Dashboard"
+	Component  ResourceCategory = "Component"
+	Datasource ResourceCategory = "Datasource"
 )
 
 const (
 	Read  ActionType = "read"
 	Write ActionType = "write"
+	// Query/Edit represent the permissions of datasource, edit permission includes query permission.
+	Query ActionType = "query"
+	Edit  ActionType = "edit"
 )
 
 const (
@@ -17,6 +17,8 @@
 
 package accesscontrol
 
+import "strings"
+
 type RoleType string
 
 const (
@@ -28,10 +30,23 @@ const (
 	RoleAnonymous RoleType = "Anonymous"
 )
 
+// teamRolePrefix represents the prefix of team role.
+const teamRolePrefix = "team:"
+
 func (rt RoleType) String() string {
 	return string(rt)
 }
 
+// TeamRole returns the role of team, which is the subject of resource policies for team members.
+func TeamRole(teamUID string) RoleType {
+	return RoleType(teamRolePrefix + teamUID)
+}
+
+// TeamUID returns the uid of team if role is team role.
+func (rt RoleType) TeamUID() (string, bool) {
+	return strings.CutPrefix(string(rt), teamRolePrefix)
+}
+
 type Role struct {
 	RoleType RoleType
 	Extends  RoleType
@@ -31,6 +31,7 @@ import (
 	"github.com/lindb/linsight/http/deps"
 	dbpkg "github.com/lindb/linsight/pkg/db"
 	"github.com/lindb/linsight/plugin/datasource"
+	"github.com/lindb/linsight/plugin/datasource/sqldb"
 	provisioningdeps "github.com/lindb/linsight/provisioning/deps"
 	provisionservice "github.com/lindb/linsight/provisioning/service"
 	"github.com/lindb/linsight/service"
@@ -155,6 +156,14 @@ func buildDeps(db dbpkg.DB, cfg *config.Server) *deps.API {
 	userSrv := service.NewUserService(db, orgSrv)
 	starSrv := service.NewStarService(db)
 	tagSrv := service.NewTagService(db)
+	sqliteOpts := &sqldb.SQLiteOptions{Dir: cfg.SQLiteDir}
+	if cfg.Database != nil && cfg.Database.Type == "sqlite" {
+		// the database of linsight cannot be queried by SQLite datasource
+		sqliteOpts.ExcludeFiles = append(sqliteOpts.ExcludeFiles, cfg.Database.DSN)
+	}
+	sqldb.SetSQLiteOptions(sqliteOpts)
+	datasourceMgr := datasource.NewDatasourceManager(cfg.SecretKey)
+	datasourceSrv := service.NewDatasourceService(db, datasourceMgr, authorizeSrv, cfg.SecretKey)
 	return &deps.API{
 		Config:          cfg,
 		OrgSrv:          orgSrv,
@@ -165,11 +174,12 @@ func buildDeps(db dbpkg.DB, cfg *config.Server) *deps.API {
 		AuthorizeSrv:    authorizeSrv,
 		AuthenticateSrv: service.NewAuthenticateService(userSrv, db),
 		TagSrv:          tagSrv,
-		DatasourceSrv:   service.NewDatasourceService(db),
+		DatasourceSrv:   datasourceSrv,
 		DashboardSrv:    service.NewDashboardService(starSrv, tagSrv, db),
 		ChartSrv:        service.NewChartService(db),
+		DataQuerySrv:    service.NewDataQueryService(datasourceSrv, datasourceMgr, cfg.Query),
 
-		DatasourceMgr: datasource.NewDatasourceManager(),
+		DatasourceMgr: datasourceMgr,
 	}
 }
 
@@ -0,0 +1,36 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import "github.com/lindb/common/pkg/ltoml"
+
+// Query represents the data query configuration.
+type Query struct {
+	// MaxConcurrency is the max number of queries executed concurrently for one data query request.
+	MaxConcurrency int         `env:"MAX_CONCURRENCY" toml:"max-concurrency"`
+	Cache          *QueryCache `envPrefix:"CACHE_" toml:"cache"`
+}
+
+// QueryCache represents the configuration of data query result cache.
+type QueryCache struct {
+	Enabled bool `env:"ENABLED" toml:"enabled"`
+	// TTL is the time to live of cached result.
+	TTL ltoml.Duration `env:"TTL" toml:"ttl"`
+	// MaxMemory is the memory budget of cache, evicts least recently used result if exceeded.
+	MaxMemory ltoml.Size `env:"MAX_MEMORY" toml:"max-memory"`
+}
@@ -53,8 +53,15 @@ type Server struct {
 	Database     *Database       `envPrefix:"LINSIGHT_DATABASE_" toml:"database"`
 	HTTP         *HTTP           `envPrefix:"LINSIGHT_HTTP_" toml:"http"`
 	Cookie       *Cookie         `envPrefix:"LINSIGHT_COOKIE_" toml:"cookie"`
+	Query        *Query          `envPrefix:"LINSIGHT_QUERY_" toml:"query"`
 	Provisioning string          `envPrefix:"LINSIGHT_PROVISIONING" toml:"provisioning"`
 	Logger       *logger.Setting `envPrefix:"LINSIGHT_LOGGER_" toml:"logger"`
+	// SecretKey is used to encrypt the secrets of datasource, secrets cannot be saved if not set,
+	// secrets saved cannot be decrypted after it changed.
+	SecretKey string `env:"LINSIGHT_SECRET_KEY" toml:"secret-key"`
+	// SQLiteDir represents the directory which the database files of SQLite datasource must be in,
+	// SQLite datasource is disabled if not set.
+	SQLiteDir string `env:"LINSIGHT_SQLITE_DIR" toml:"sqlite-dir"`
 }
 
 func NewDefaultServer() *Server {
@@ -75,6 +82,14 @@ func NewDefaultServer() *Server {
 			Name:   constant.LinSightCookie,
 			MaxAge: ltoml.Duration(time.Hour * 24 * 30),
 		},
+		Query: &Query{
+			MaxConcurrency: 8,
+			Cache: &QueryCache{
+				Enabled:   true,
+				TTL:       ltoml.Duration(time.Second * 30),
+				MaxMemory: 64 * 1024 * 1024,
+			},
+		},
 		Logger: logger.NewDefaultSetting(),
 	}
 }
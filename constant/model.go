@@ -22,4 +22,6 @@ const (
 	UID = "uid"
 	// Limit represents pagination's default limit.
 	Limit = 20
+	// TestParam represents the query param which tests data source before saving it.
+	TestParam = "test"
 )
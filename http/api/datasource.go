@@ -18,12 +18,18 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	httppkg "github.com/lindb/common/pkg/http"
 
+	"github.com/lindb/linsight/accesscontrol"
 	"github.com/lindb/linsight/constant"
 	apideps "github.com/lindb/linsight/http/deps"
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
 )
 
 // DatasourceAPI represents data source related api handlers.
@@ -45,6 +51,10 @@ func (api *DatasourceAPI) CreateDatasource(c *gin.Context) {
 		httppkg.Error(c, err)
 		return
 	}
+	if err := api.testBeforeSave(c, ds); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
 	uid, err := api.deps.DatasourceSrv.CreateDatasource(c.Request.Context(), ds)
 	if err != nil {
 		httppkg.Error(c, err)
@@ -60,6 +70,13 @@ func (api *DatasourceAPI) UpdateDatasource(c *gin.Context) {
 		httppkg.Error(c, err)
 		return
 	}
+	if !checkDatasourcePermission(c, api.deps, ds.UID, accesscontrol.Edit) {
+		return
+	}
+	if err := api.testBeforeSave(c, ds); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
 	if err := api.deps.DatasourceSrv.UpdateDatasource(c.Request.Context(), ds); err != nil {
 		httppkg.Error(c, err)
 		return
@@ -70,6 +87,9 @@ func (api *DatasourceAPI) UpdateDatasource(c *gin.Context) {
 // DeleteDatasource deletes data source by uid.
 func (api *DatasourceAPI) DeleteDatasourceByUID(c *gin.Context) {
 	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Edit) {
+		return
+	}
 	if err := api.deps.DatasourceSrv.DeleteDatasourceByUID(c.Request.Context(), uid); err != nil {
 		httppkg.Error(c, err)
 		return
@@ -80,6 +100,9 @@ func (api *DatasourceAPI) DeleteDatasourceByUID(c *gin.Context) {
 // GetDatasource returns data source by uid.
 func (api *DatasourceAPI) GetDatasourceByUID(c *gin.Context) {
 	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Query) {
+		return
+	}
 	ds, err := api.deps.DatasourceSrv.GetDatasourceByUID(c.Request.Context(), uid)
 	if err != nil {
 		//TODO: check not found???
@@ -98,3 +121,110 @@ func (api *DatasourceAPI) GetDatasources(c *gin.Context) {
 	}
 	httppkg.OK(c, dataSources)
 }
+
+// CheckHealth checks if data source is working by uid.
+func (api *DatasourceAPI) CheckHealth(c *gin.Context) {
+	ctx := c.Request.Context()
+	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Edit) {
+		return
+	}
+	ds, err := api.deps.DatasourceSrv.GetDatasourceByUID(ctx, uid)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	cli, err := api.deps.DatasourceMgr.GetPlugin(ds)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	if err := checkHealth(ctx, cli); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, "Data source is working")
+}
+
+// GetDatasourcePermissions returns the permissions of data source by uid.
+func (api *DatasourceAPI) GetDatasourcePermissions(c *gin.Context) {
+	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Edit) {
+		return
+	}
+	permissions, err := api.deps.DatasourceSrv.GetDatasourcePermissions(c.Request.Context(), uid)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, permissions)
+}
+
+// SaveDatasourcePermissions replaces the permissions of data source by uid.
+func (api *DatasourceAPI) SaveDatasourcePermissions(c *gin.Context) {
+	var permissions []model.DatasourcePermission
+	if err := c.ShouldBind(&permissions); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Edit) {
+		return
+	}
+	if err := api.deps.DatasourceSrv.SaveDatasourcePermissions(c.Request.Context(), uid, permissions); err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	httppkg.OK(c, "Data source permissions saved")
+}
+
+// testBeforeSave checks if data source is working before saving it, if "test" param is true.
+func (api *DatasourceAPI) testBeforeSave(c *gin.Context, ds *model.Datasource) error {
+	if test, _ := strconv.ParseBool(c.Query(constant.TestParam)); !test {
+		return nil
+	}
+	testDS := *ds
+	if ds.UID != "" {
+		// tests the data source being updated with the stored secrets, the stored secrets are dropped
+		// if url changed(same as saving), they cannot be sent to the new url
+		stored, err := api.deps.DatasourceSrv.GetDatasourceByUID(c.Request.Context(), ds.UID)
+		if err != nil {
+			return err
+		}
+		if stored.URL == ds.URL {
+			testDS.EncryptedSecureConfig = stored.EncryptedSecureConfig
+		}
+	}
+	// data source not saved, creates a temporary plugin without cache
+	cli, err := api.deps.DatasourceMgr.NewPlugin(&testDS)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cli.Close()
+	}()
+	return checkHealth(c.Request.Context(), cli)
+}
+
+// checkHealth checks the health of data source by datasource plugin.
+func checkHealth(ctx context.Context, cli plugin.DatasourcePlugin) error {
+	if err := cli.CheckHealth(ctx); err != nil {
+		return fmt.Errorf("data source health check failure: %w", err)
+	}
+	return nil
+}
+
+// checkDatasourcePermission checks if signed user has the permission(query/edit) of data source,
+// responses forbidden if no permission.
+func checkDatasourcePermission(c *gin.Context, deps *apideps.API, uid string, action accesscontrol.ActionType) bool {
+	ok, err := deps.DatasourceSrv.CanAccessDatasource(c.Request.Context(), uid, action)
+	if err != nil {
+		httppkg.Error(c, err)
+		return false
+	}
+	if !ok {
+		httppkg.Forbidden(c)
+		return false
+	}
+	return true
+}
@@ -18,11 +18,18 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	httppkg "github.com/lindb/common/pkg/http"
 
+	"github.com/lindb/linsight/accesscontrol"
+	"github.com/lindb/linsight/constant"
 	apideps "github.com/lindb/linsight/http/deps"
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin/datasource"
+	"github.com/lindb/linsight/plugin/datasource/expression"
 )
 
 // DatasourceQueryAPI represents data source query related api handlers.
@@ -37,7 +44,7 @@ func NewDatasourceQueryAPI(deps *apideps.API) *DatasourceQueryAPI {
 	}
 }
 
-// DataQuery queries data.
+// DataQuery queries data, each query has its own result(data/error).
 func (api *DatasourceQueryAPI) DataQuery(c *gin.Context) {
 	req := &model.QueryRequest{}
 	err := c.ShouldBind(req)
@@ -45,27 +52,29 @@ func (api *DatasourceQueryAPI) DataQuery(c *gin.Context) {
 		httppkg.Error(c, err)
 		return
 	}
-
-	ctx := c.Request.Context()
-	rs := make(map[string]any)
 	for _, query := range req.Queries {
-		ds, err := api.deps.DatasourceSrv.GetDatasourceByUID(ctx, query.Datasource.UID)
-		if err != nil {
-			httppkg.Error(c, err)
+		if query == nil || query.Datasource.UID == "" {
+			badRequest(c, errors.New("query with datasource is required"))
 			return
 		}
-		cli, err := api.deps.DatasourceMgr.GetPlugin(ds)
-		if err != nil {
-			httppkg.Error(c, err)
-			return
+	}
+	// check the query permission of all data sources before querying
+	checked := make(map[string]struct{})
+	for _, query := range req.Queries {
+		uid := query.Datasource.UID
+		if _, ok := checked[uid]; ok || uid == expression.DatasourceUID {
+			continue
 		}
-		resp, err := cli.DataQuery(ctx, query, req.Range)
-		if err != nil {
-			httppkg.Error(c, err)
+		if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Query) {
 			return
 		}
-		// TODO: add refID maybe empty,go?
-		rs[query.RefID] = resp
+		checked[uid] = struct{}{}
+	}
+
+	rs, err := api.deps.DataQuerySrv.DataQuery(c.Request.Context(), req)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
 	}
 	httppkg.OK(c, rs)
 }
@@ -79,6 +88,9 @@ func (api *DatasourceQueryAPI) MetadataQuery(c *gin.Context) {
 		return
 	}
 
+	if !checkDatasourcePermission(c, api.deps, req.Datasource.UID, accesscontrol.Query) {
+		return
+	}
 	ctx := c.Request.Context()
 	ds, err := api.deps.DatasourceSrv.GetDatasourceByUID(ctx, req.Datasource.UID)
 	if err != nil {
@@ -97,3 +109,43 @@ func (api *DatasourceQueryAPI) MetadataQuery(c *gin.Context) {
 	}
 	httppkg.OK(c, resp)
 }
+
+// Proxy forwards request to the url of datasource with its credentials,
+// only the routes allowed by datasource plugin can be forwarded, privileged routes need edit permission.
+func (api *DatasourceQueryAPI) Proxy(c *gin.Context) {
+	uid := c.Param(constant.UID)
+	if !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Query) {
+		return
+	}
+	ds, err := api.deps.DatasourceSrv.GetDatasourceByUID(c.Request.Context(), uid)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	proxy, err := api.deps.DatasourceMgr.GetProxy(ds)
+	if err != nil {
+		httppkg.Error(c, err)
+		return
+	}
+	route, err := proxy.Route(c.Request.Method, c.Param("path"))
+	if err != nil {
+		httppkg.Forbidden(c)
+		return
+	}
+	if route.Privileged && !checkDatasourcePermission(c, api.deps, uid, accesscontrol.Edit) {
+		return
+	}
+	if err := proxy.Forward(c.Writer, c.Request, c.Param("path")); err != nil {
+		if errors.Is(err, datasource.ErrProxyRouteNotAllowed) {
+			httppkg.Forbidden(c)
+			return
+		}
+		httppkg.Error(c, err)
+	}
+}
+
+// badRequest responses error message and set the http status code 400.
+func badRequest(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.JSON(http.StatusBadRequest, err.Error())
+}
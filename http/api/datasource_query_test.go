@@ -32,6 +32,7 @@ import (
 
 	"github.com/lindb/common/pkg/encoding"
 
+	"github.com/lindb/linsight/accesscontrol"
 	"github.com/lindb/linsight/http/deps"
 	"github.com/lindb/linsight/model"
 	"github.com/lindb/linsight/plugin"
@@ -43,15 +44,15 @@ func TestDatasourceQueryAPI_DataQuery(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	querySrv := service.NewMockDataQueryService(ctrl)
 	dsSrv := service.NewMockDatasourceService(ctrl)
-	dsMrg := datasource.NewMockManager(ctrl)
-	query := plugin.NewMockDatasourcePlugin(ctrl)
 	r := gin.New()
 	api := NewDatasourceQueryAPI(&deps.API{
+		DataQuerySrv:  querySrv,
 		DatasourceSrv: dsSrv,
-		DatasourceMgr: dsMrg,
 	})
 	r.PUT("/datasource/query", api.DataQuery)
+	dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "uid", accesscontrol.Query).Return(true, nil).AnyTimes()
 	body := encoding.JSONMarshal(&model.QueryRequest{Queries: []*model.Query{{Datasource: model.TargetDatasource{UID: "uid"}}}})
 
 	cases := []struct {
@@ -68,33 +69,24 @@ func TestDatasourceQueryAPI_DataQuery(t *testing.T) {
 			},
 		},
 		{
-			name: "get datasource failure",
-			body: bytes.NewBuffer(body),
-			prepare: func() {
-				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(nil, fmt.Errorf("err"))
-			},
+			name: "nil query",
+			body: bytes.NewBuffer([]byte(`{"queries":[null]}`)),
 			assert: func(resp *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
 			},
 		},
 		{
-			name: "get plugin failure",
-			body: bytes.NewBuffer(body),
-			prepare: func() {
-				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
-				dsMrg.EXPECT().GetPlugin(gomock.Any()).Return(nil, fmt.Errorf("err"))
-			},
+			name: "query without datasource",
+			body: bytes.NewBuffer([]byte(`{"queries":[{"refId":"A"}]}`)),
 			assert: func(resp *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
 			},
 		},
 		{
 			name: "data query failure",
 			body: bytes.NewBuffer(body),
 			prepare: func() {
-				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
-				dsMrg.EXPECT().GetPlugin(gomock.Any()).Return(query, nil)
-				query.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+				querySrv.EXPECT().DataQuery(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
 			},
 			assert: func(resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
@@ -104,12 +96,15 @@ func TestDatasourceQueryAPI_DataQuery(t *testing.T) {
 			name: "data query successfully",
 			body: bytes.NewBuffer(body),
 			prepare: func() {
-				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
-				dsMrg.EXPECT().GetPlugin(gomock.Any()).Return(query, nil)
-				query.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+				querySrv.EXPECT().DataQuery(gomock.Any(), gomock.Any()).Return(&model.QueryResponse{
+					Results: map[string]*model.QueryResult{
+						"A": {RefID: "A", Status: model.QueryStatusError, Error: "err"},
+					},
+				}, nil)
 			},
 			assert: func(resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, `{"results":{"A":{"refId":"A","status":"error","error":"err","duration":0}}}`, resp.Body.String())
 			},
 		},
 	}
@@ -141,6 +136,7 @@ func TestDatasourceQueryAPI_MetadataQuery(t *testing.T) {
 		DatasourceMgr: dsMrg,
 	})
 	r.PUT("/datasource/query", api.MetadataQuery)
+	dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "uid", accesscontrol.Query).Return(true, nil).AnyTimes()
 	body := encoding.JSONMarshal(&model.Query{Datasource: model.TargetDatasource{UID: "uid"}})
 
 	cases := []struct {
@@ -216,3 +212,207 @@ func TestDatasourceQueryAPI_MetadataQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestDatasourceQueryAPI_Proxy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := service.NewMockDatasourceService(ctrl)
+	dsMrg := datasource.NewMockManager(ctrl)
+	proxy := datasource.NewMockProxy(ctrl)
+	r := gin.New()
+	api := NewDatasourceQueryAPI(&deps.API{
+		DatasourceSrv: dsSrv,
+		DatasourceMgr: dsMrg,
+	})
+	r.Any("/datasources/:uid/proxy/*path", api.Proxy)
+	dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "uid", accesscontrol.Query).Return(true, nil).AnyTimes()
+
+	cases := []struct {
+		name    string
+		prepare func()
+		assert  func(resp *httptest.ResponseRecorder)
+	}{
+		{
+			name: "get datasource failure",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "get proxy failure",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "route not allowed",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(proxy, nil)
+				proxy.EXPECT().Route(http.MethodPut, "/api/v1/exec").Return(plugin.ProxyRoute{}, datasource.ErrProxyRouteNotAllowed)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "privileged route without edit permission",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(proxy, nil)
+				proxy.EXPECT().Route(http.MethodPut, "/api/v1/exec").Return(plugin.ProxyRoute{Privileged: true}, nil)
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "uid", accesscontrol.Edit).Return(false, nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "request rejected by route",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(proxy, nil)
+				proxy.EXPECT().Route(http.MethodPut, "/api/v1/exec").Return(plugin.ProxyRoute{}, nil)
+				proxy.EXPECT().Forward(gomock.Any(), gomock.Any(), "/api/v1/exec").Return(datasource.ErrProxyRouteNotAllowed)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "forward failure",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(proxy, nil)
+				proxy.EXPECT().Route(http.MethodPut, "/api/v1/exec").Return(plugin.ProxyRoute{}, nil)
+				proxy.EXPECT().Forward(gomock.Any(), gomock.Any(), "/api/v1/exec").Return(fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "forward privileged route successfully",
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "uid").Return(&model.Datasource{}, nil)
+				dsMrg.EXPECT().GetProxy(gomock.Any()).Return(proxy, nil)
+				proxy.EXPECT().Route(http.MethodPut, "/api/v1/exec").Return(plugin.ProxyRoute{Privileged: true}, nil)
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "uid", accesscontrol.Edit).Return(true, nil)
+				proxy.EXPECT().Forward(gomock.Any(), gomock.Any(), "/api/v1/exec").
+					DoAndReturn(func(w http.ResponseWriter, _ *http.Request, _ string) error {
+						w.WriteHeader(http.StatusAccepted)
+						return nil
+					})
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, resp.Code)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, "/datasources/uid/proxy/api/v1/exec", http.NoBody)
+			resp := httptest.NewRecorder()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			r.ServeHTTP(resp, req)
+			tt.assert(resp)
+		})
+	}
+}
+
+func TestDatasourceQueryAPI_Permission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySrv := service.NewMockDataQueryService(ctrl)
+	dsSrv := service.NewMockDatasourceService(ctrl)
+	r := gin.New()
+	api := NewDatasourceQueryAPI(&deps.API{
+		DataQuerySrv:  querySrv,
+		DatasourceSrv: dsSrv,
+	})
+	r.PUT("/data/query", api.DataQuery)
+	r.PUT("/metadata/query", api.MetadataQuery)
+	r.Any("/datasources/:uid/proxy/*path", api.Proxy)
+
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		body    any
+		prepare func()
+		code    int
+	}{
+		{
+			name:   "check permission failure",
+			method: http.MethodPut,
+			path:   "/data/query",
+			body:   &model.QueryRequest{Queries: []*model.Query{{Datasource: model.TargetDatasource{UID: "finance"}}}},
+			prepare: func() {
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "finance", accesscontrol.Query).Return(false, fmt.Errorf("err"))
+			},
+			code: http.StatusInternalServerError,
+		},
+		{
+			name:   "data query without permission",
+			method: http.MethodPut,
+			path:   "/data/query",
+			body: &model.QueryRequest{Queries: []*model.Query{
+				{Datasource: model.TargetDatasource{UID: "public"}},
+				{Datasource: model.TargetDatasource{UID: "public"}},
+				{Datasource: model.TargetDatasource{UID: "__expr__"}},
+				{Datasource: model.TargetDatasource{UID: "finance"}},
+			}},
+			prepare: func() {
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "public", accesscontrol.Query).Return(true, nil)
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "finance", accesscontrol.Query).Return(false, nil)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:   "metadata query without permission",
+			method: http.MethodPut,
+			path:   "/metadata/query",
+			body:   &model.Query{Datasource: model.TargetDatasource{UID: "finance"}},
+			prepare: func() {
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "finance", accesscontrol.Query).Return(false, nil)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:   "proxy without permission",
+			method: http.MethodGet,
+			path:   "/datasources/finance/proxy/api/v1/state/explore",
+			prepare: func() {
+				dsSrv.EXPECT().CanAccessDatasource(gomock.Any(), "finance", accesscontrol.Query).Return(false, nil)
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			var body io.Reader = http.NoBody
+			if tt.body != nil {
+				body = bytes.NewBuffer(encoding.JSONMarshal(tt.body))
+			}
+			req, _ := http.NewRequestWithContext(context.TODO(), tt.method, tt.path, body)
+			req.Header.Set("content-type", "application/json")
+			resp := httptest.NewRecorder()
+			tt.prepare()
+			r.ServeHTTP(resp, req)
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
@@ -32,8 +32,11 @@ import (
 
 	"github.com/lindb/common/pkg/encoding"
 
+	"github.com/lindb/linsight/accesscontrol"
 	"github.com/lindb/linsight/http/deps"
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+	"github.com/lindb/linsight/plugin/datasource"
 	"github.com/lindb/linsight/service"
 )
 
@@ -42,15 +45,19 @@ func TestDatasourceAPI_CreateDatasource(t *testing.T) {
 	defer ctrl.Finish()
 
 	datasourceSrv := service.NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
 	r := gin.New()
 	api := NewDatasourceAPI(&deps.API{
 		DatasourceSrv: datasourceSrv,
+		DatasourceMgr: dsMgr,
 	})
 	r.POST("/datasource", api.CreateDatasource)
 	body := encoding.JSONMarshal(&model.Datasource{})
 
 	cases := []struct {
 		name    string
+		query   string
 		body    io.Reader
 		prepare func()
 		assert  func(resp *httptest.ResponseRecorder)
@@ -72,6 +79,44 @@ func TestDatasourceAPI_CreateDatasource(t *testing.T) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
 			},
 		},
+		{
+			name:  "get plugin failure when test before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "health check failure when test before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "create data source after test successfully",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(nil)
+				datasourceSrv.EXPECT().CreateDatasource(gomock.Any(), gomock.Any()).Return("1234", nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
 		{
 			name: "create data source successfully",
 			body: bytes.NewBuffer(body),
@@ -87,7 +132,7 @@ func TestDatasourceAPI_CreateDatasource(t *testing.T) {
 	for _, tt := range cases {
 		tt := tt
 		t.Run(tt.name, func(_ *testing.T) {
-			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPost, "/datasource", tt.body)
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPost, "/datasource"+tt.query, tt.body)
 			req.Header.Set("content-type", "application/json")
 			resp := httptest.NewRecorder()
 			if tt.prepare != nil {
@@ -104,15 +149,20 @@ func TestDatasourceAPI_UpdateDatasource(t *testing.T) {
 	defer ctrl.Finish()
 
 	datasourceSrv := service.NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
 	r := gin.New()
 	api := NewDatasourceAPI(&deps.API{
 		DatasourceSrv: datasourceSrv,
+		DatasourceMgr: dsMgr,
 	})
 	r.PUT("/datasource", api.UpdateDatasource)
+	datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
 	body := encoding.JSONMarshal(&model.Datasource{})
 
 	cases := []struct {
 		name    string
+		query   string
 		body    io.Reader
 		prepare func()
 		assert  func(resp *httptest.ResponseRecorder)
@@ -134,6 +184,96 @@ func TestDatasourceAPI_UpdateDatasource(t *testing.T) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
 			},
 		},
+		{
+			name:  "get plugin failure when test before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "health check failure when test before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "get stored data source failure when test before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(encoding.JSONMarshal(&model.Datasource{UID: "1234"})),
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "test with stored secrets before save",
+			query: "?test=true",
+			body:  bytes.NewBuffer(encoding.JSONMarshal(&model.Datasource{UID: "1234"})),
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").
+					Return(&model.Datasource{EncryptedSecureConfig: []byte("secrets")}, nil)
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).DoAndReturn(func(ds *model.Datasource) (plugin.DatasourcePlugin, error) {
+					assert.Equal(t, []byte("secrets"), ds.EncryptedSecureConfig)
+					return cli, nil
+				})
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(nil)
+				datasourceSrv.EXPECT().UpdateDatasource(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, ds *model.Datasource) error {
+						assert.Empty(t, ds.EncryptedSecureConfig)
+						return nil
+					})
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name:  "test without stored secrets before save if url changed",
+			query: "?test=true",
+			body:  bytes.NewBuffer(encoding.JSONMarshal(&model.Datasource{UID: "1234", URL: "http://other:9000"})),
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").
+					Return(&model.Datasource{URL: "http://lindb:9000", EncryptedSecureConfig: []byte("secrets")}, nil)
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).DoAndReturn(func(ds *model.Datasource) (plugin.DatasourcePlugin, error) {
+					assert.Empty(t, ds.EncryptedSecureConfig)
+					return cli, nil
+				})
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(fmt.Errorf("unauthorized"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "update data source after test successfully",
+			query: "?test=true",
+			body:  bytes.NewBuffer(body),
+			prepare: func() {
+				dsMgr.EXPECT().NewPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().Close().Return(nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(nil)
+				datasourceSrv.EXPECT().UpdateDatasource(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
 		{
 			name: "update data source successfully",
 			body: bytes.NewBuffer(body),
@@ -148,7 +288,7 @@ func TestDatasourceAPI_UpdateDatasource(t *testing.T) {
 	for _, tt := range cases {
 		tt := tt
 		t.Run(tt.name, func(_ *testing.T) {
-			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, "/datasource", tt.body)
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, "/datasource"+tt.query, tt.body)
 			req.Header.Set("content-type", "application/json")
 			resp := httptest.NewRecorder()
 			if tt.prepare != nil {
@@ -170,6 +310,7 @@ func TestDatasourceAPI_DeleteDatasourceByUID(t *testing.T) {
 		DatasourceSrv: datasourceSrv,
 	})
 	r.DELETE("/datasource/:uid", api.DeleteDatasourceByUID)
+	datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
 
 	cases := []struct {
 		name    string
@@ -220,6 +361,7 @@ func TestDatasourceAPI_GetDatasourceByUID(t *testing.T) {
 		DatasourceSrv: datasourceSrv,
 	})
 	r.GET("/datasource/:uid", api.GetDatasourceByUID)
+	datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
 
 	cases := []struct {
 		name    string
@@ -309,3 +451,225 @@ func TestDatasourceAPI_GetDatasources(t *testing.T) {
 		})
 	}
 }
+
+func TestDatasourceAPI_CheckHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datasourceSrv := service.NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	r := gin.New()
+	api := NewDatasourceAPI(&deps.API{
+		DatasourceSrv: datasourceSrv,
+		DatasourceMgr: dsMgr,
+	})
+	r.POST("/datasources/:uid/health", api.CheckHealth)
+	datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+
+	cases := []struct {
+		name    string
+		prepare func()
+		assert  func(resp *httptest.ResponseRecorder)
+	}{
+		{
+			name: "get data source failure",
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "get plugin failure",
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").Return(&model.Datasource{}, nil)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "health check failure",
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").Return(&model.Datasource{}, nil)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(fmt.Errorf("database not found"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+				assert.Contains(t, resp.Body.String(), "database not found")
+			},
+		},
+		{
+			name: "data source is working",
+			prepare: func() {
+				datasourceSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "1234").Return(&model.Datasource{}, nil)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().CheckHealth(gomock.Any()).Return(nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPost, "/datasources/1234/health", http.NoBody)
+			resp := httptest.NewRecorder()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			r.ServeHTTP(resp, req)
+			tt.assert(resp)
+		})
+	}
+}
+
+func TestDatasourceAPI_GetDatasourcePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datasourceSrv := service.NewMockDatasourceService(ctrl)
+	r := gin.New()
+	api := NewDatasourceAPI(&deps.API{
+		DatasourceSrv: datasourceSrv,
+	})
+	r.GET("/datasources/:uid/permissions", api.GetDatasourcePermissions)
+
+	cases := []struct {
+		name    string
+		prepare func()
+		assert  func(resp *httptest.ResponseRecorder)
+	}{
+		{
+			name: "check permission failure",
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(false, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "no edit permission",
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(false, nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "get permissions failure",
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(true, nil)
+				datasourceSrv.EXPECT().GetDatasourcePermissions(gomock.Any(), "1234").Return(nil, fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "get permissions successfully",
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(true, nil)
+				datasourceSrv.EXPECT().GetDatasourcePermissions(gomock.Any(), "1234").
+					Return([]model.DatasourcePermission{{TeamUID: "finance", Action: accesscontrol.Query}}, nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.JSONEq(t, `[{"teamUid":"finance","action":"query"}]`, resp.Body.String())
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodGet, "/datasources/1234/permissions", http.NoBody)
+			resp := httptest.NewRecorder()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			r.ServeHTTP(resp, req)
+			tt.assert(resp)
+		})
+	}
+}
+
+func TestDatasourceAPI_SaveDatasourcePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datasourceSrv := service.NewMockDatasourceService(ctrl)
+	r := gin.New()
+	api := NewDatasourceAPI(&deps.API{
+		DatasourceSrv: datasourceSrv,
+	})
+	r.PUT("/datasources/:uid/permissions", api.SaveDatasourcePermissions)
+	body := encoding.JSONMarshal([]model.DatasourcePermission{{TeamUID: "finance", Action: accesscontrol.Edit}})
+
+	cases := []struct {
+		name    string
+		body    io.Reader
+		prepare func()
+		assert  func(resp *httptest.ResponseRecorder)
+	}{
+		{
+			name: "request bind failure",
+			body: bytes.NewBuffer([]byte("bbc")),
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "no edit permission",
+			body: bytes.NewBuffer(body),
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(false, nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "save permissions failure",
+			body: bytes.NewBuffer(body),
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(true, nil)
+				datasourceSrv.EXPECT().SaveDatasourcePermissions(gomock.Any(), "1234", gomock.Any()).Return(fmt.Errorf("err"))
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "save permissions successfully",
+			body: bytes.NewBuffer(body),
+			prepare: func() {
+				datasourceSrv.EXPECT().CanAccessDatasource(gomock.Any(), "1234", accesscontrol.Edit).Return(true, nil)
+				datasourceSrv.EXPECT().SaveDatasourcePermissions(gomock.Any(), "1234",
+					[]model.DatasourcePermission{{TeamUID: "finance", Action: accesscontrol.Edit}}).Return(nil)
+			},
+			assert: func(resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, "/datasources/1234/permissions", tt.body)
+			req.Header.Set("content-type", "application/json")
+			resp := httptest.NewRecorder()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			r.ServeHTTP(resp, req)
+			tt.assert(resp)
+		})
+	}
+}
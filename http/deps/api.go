@@ -39,6 +39,7 @@ type API struct {
 	TagSrv       service.TagService
 	DashboardSrv service.DashboardService
 	ChartSrv     service.ChartService
+	DataQuerySrv service.DataQueryService
 
 	DatasourceMgr datasource.Manager
 }
@@ -167,14 +167,21 @@ func (r *Router) RegisterRouters() {
 
 	router.POST("/datasource",
 		middleware.Authorize(r.deps, accesscontrol.AdminAccessResource, accesscontrol.Write, r.datasourceAPI.CreateDatasource)...)
+	// edit data source requires edit permission of data source, checked by datasource api
 	router.PUT("/datasource",
-		middleware.Authorize(r.deps, accesscontrol.AdminAccessResource, accesscontrol.Write, r.datasourceAPI.UpdateDatasource)...)
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Write, r.datasourceAPI.UpdateDatasource)...)
 	router.DELETE("/datasources/:uid",
-		middleware.Authorize(r.deps, accesscontrol.AdminAccessResource, accesscontrol.Write, r.datasourceAPI.DeleteDatasourceByUID)...)
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Write, r.datasourceAPI.DeleteDatasourceByUID)...)
 	router.GET("/datasources",
 		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceAPI.GetDatasources)...)
 	router.GET("/datasources/:uid",
 		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceAPI.GetDatasourceByUID)...)
+	router.POST("/datasources/:uid/health",
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Write, r.datasourceAPI.CheckHealth)...)
+	router.GET("/datasources/:uid/permissions",
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceAPI.GetDatasourcePermissions)...)
+	router.PUT("/datasources/:uid/permissions",
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Write, r.datasourceAPI.SaveDatasourcePermissions)...)
 
 	// dashboard api
 	router.POST("/dashboards",
@@ -212,4 +219,6 @@ func (r *Router) RegisterRouters() {
 		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceQueryAPI.DataQuery)...)
 	router.PUT("/metadata/query",
 		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceQueryAPI.MetadataQuery)...)
+	router.Any("/datasources/:uid/proxy/*path",
+		middleware.Authorize(r.deps, accesscontrol.ViewerAccessResource, accesscontrol.Read, r.datasourceQueryAPI.Proxy)...)
 }
@@ -18,11 +18,25 @@
 package http
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/common/pkg/encoding"
+
+	"github.com/lindb/linsight/accesscontrol"
+	"github.com/lindb/linsight/config"
+	"github.com/lindb/linsight/constant"
 	"github.com/lindb/linsight/http/deps"
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/db"
+	"github.com/lindb/linsight/plugin/datasource"
+	"github.com/lindb/linsight/service"
 )
 
 func init() {
@@ -33,3 +47,92 @@ func TestRouter_RegisterRouters(t *testing.T) {
 	r := NewRouter(gin.New(), &deps.API{})
 	r.RegisterRouters()
 }
+
+func TestRouter_UpdateDatasourceByEditPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := db.NewMockDB(ctrl)
+	mgr := datasource.NewMockManager(ctrl)
+	authenticateSrv := service.NewMockAuthenticateService(ctrl)
+	userSrv := service.NewMockUserService(ctrl)
+	authorizeSrv := service.NewMockAuthorizeService(ctrl)
+	engine := gin.New()
+	r := NewRouter(engine, &deps.API{
+		Config:          config.NewDefaultServer(),
+		AuthenticateSrv: authenticateSrv,
+		UserSrv:         userSrv,
+		AuthorizeSrv:    authorizeSrv,
+		DatasourceSrv:   service.NewDatasourceService(mockDB, mgr, authorizeSrv, ""),
+		DatasourceMgr:   mgr,
+	})
+	r.RegisterRouters()
+
+	authenticateSrv.EXPECT().LookupToken(gomock.Any(), "token").Return(&model.UserToken{UserID: 10}, nil).AnyTimes()
+	userSrv.EXPECT().GetSignedUser(gomock.Any(), int64(10)).Return(&model.SignedUser{
+		Org:  &model.Org{BaseModel: model.BaseModel{ID: 12}},
+		User: &model.User{BaseModel: model.BaseModel{ID: 10}},
+		Role: accesscontrol.RoleViewer,
+	}, nil).AnyTimes()
+	authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleViewer, accesscontrol.ViewerAccessResource, accesscontrol.Write).
+		Return(true).AnyTimes()
+	authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleViewer, accesscontrol.AdminAccessResource, accesscontrol.Write).
+		Return(false).AnyTimes()
+	mockDB.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx db.DB) error) error {
+		return fn(mockDB)
+	}).AnyTimes()
+	// only team finance has edit permission
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").
+		Return([]model.ResourceACLParam{{Role: accesscontrol.TeamRole("finance"), Action: accesscontrol.Edit}}).AnyTimes()
+	authorizeSrv.EXPECT().CheckResourcesACL(gomock.Any()).
+		DoAndReturn(func(aclParams []model.ResourceACLParam) ([]bool, error) {
+			rs := make([]bool, len(aclParams))
+			for i, aclParam := range aclParams {
+				rs[i] = aclParam.Role == accesscontrol.TeamRole("finance") && aclParam.Action == accesscontrol.Edit
+			}
+			return rs, nil
+		}).AnyTimes()
+
+	cases := []struct {
+		name    string
+		teams   []string
+		prepare func()
+		code    int
+	}{
+		{
+			name:  "team without edit permission",
+			teams: []string{"ops"},
+			code:  http.StatusForbidden,
+		},
+		{
+			name:  "team with edit permission",
+			teams: []string{"finance"},
+			prepare: func() {
+				mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+				mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+				mgr.EXPECT().RemovePlugin("1234")
+			},
+			code: http.StatusOK,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).
+				DoAndReturn(func(out any, _ string, _ ...any) error {
+					*(out.(*[]string)) = tt.teams
+					return nil
+				})
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			body := encoding.JSONMarshal(&model.Datasource{UID: "1234", Name: "ds"})
+			req := httptest.NewRequest(http.MethodPut, constant.APIV1+"/datasource", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: constant.LinSightCookie, Value: "token"})
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
@@ -17,15 +17,30 @@
 
 package model
 
-import "gorm.io/datatypes"
+import (
+	"encoding/json"
+
+	"gorm.io/datatypes"
+
+	"github.com/lindb/linsight/accesscontrol"
+)
 
 // DatasourceType represents the type of datasource.
 type DatasourceType = string
 
 // Define all support datasource types.
 var (
-	LinDBDatasource DatasourceType = "lindb"
-	LinGoDatasource DatasourceType = "lingo"
+	LinDBDatasource         DatasourceType = "lindb"
+	LinGoDatasource         DatasourceType = "lingo"
+	PrometheusDatasource    DatasourceType = "prometheus"
+	MySQLDatasource         DatasourceType = "mysql"
+	PostgreSQLDatasource    DatasourceType = "postgres"
+	SQLiteDatasource        DatasourceType = "sqlite"
+	TestDataDatasource      DatasourceType = "testdata"
+	ElasticsearchDatasource DatasourceType = "elasticsearch"
+	OpenSearchDatasource    DatasourceType = "opensearch"
+	JaegerDatasource        DatasourceType = "jaeger"
+	ZipkinDatasource        DatasourceType = "zipkin"
 )
 
 // Datasource represents datasource information.
@@ -42,4 +57,50 @@ type Datasource struct {
 	TimeZone  string         `json:"timeZone" gorm:"column:time_zone"`
 	Config    datatypes.JSON `json:"config" gorm:"column:config"`
 	IsDefault bool           `json:"isDefault" gorm:"column:is_default"`
+
+	// SecureConfig represents the secrets(password/token etc.) which has same structure as config,
+	// it's write only, merged into the stored secrets when saving(null value deletes the secret).
+	SecureConfig datatypes.JSON `json:"secureConfig,omitempty" gorm:"-"`
+	// SecureConfigFields represents which secrets are set, key is the path of secret(e.g. http.bearerToken).
+	SecureConfigFields datatypes.JSONMap `json:"secureConfigFields" gorm:"column:secure_config_fields"`
+	// EncryptedSecureConfig represents the secrets encrypted by server secret key, only decrypted
+	// when creating datasource plugin.
+	EncryptedSecureConfig []byte `json:"-" gorm:"column:secure_config"`
+}
+
+// DatasourceQueryConfig represents the common query settings in datasource config, applied by server for all plugins.
+type DatasourceQueryConfig struct {
+	// Downsample represents the default downsampling algorithm of queries.
+	Downsample DownsampleAlgorithm `json:"downsample"`
+	// QueryTimeout represents the max duration of each query(e.g. 30s), no limit if empty.
+	QueryTimeout string `json:"queryTimeout"`
+	// MaxConcurrentQueries represents the max number of queries running concurrently, no limit if <= 0.
+	MaxConcurrentQueries int `json:"maxConcurrentQueries"`
+	// MaxTimeRange represents the max span of query time range(e.g. 30d), no limit if empty.
+	MaxTimeRange string `json:"maxTimeRange"`
+	// MaxSeries represents the max number of series returned by each query, no limit if <= 0.
+	MaxSeries int `json:"maxSeries"`
+	// MaxPoints represents the max number of points returned by each query, no limit if <= 0.
+	// Both limits are pushed down to backend if supported, otherwise checked after query completed.
+	MaxPoints int `json:"maxPoints"`
+}
+
+// GetQueryConfig returns the common query settings in datasource config.
+func (ds *Datasource) GetQueryConfig() (*DatasourceQueryConfig, error) {
+	cfg := &DatasourceQueryConfig{}
+	if len(ds.Config) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(ds.Config, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// DatasourcePermission represents the query/edit permission of role or team for datasource,
+// either role or team uid is set.
+type DatasourcePermission struct {
+	Role    accesscontrol.RoleType   `json:"role,omitempty"`
+	TeamUID string                   `json:"teamUid,omitempty"`
+	Action  accesscontrol.ActionType `json:"action" binding:"required"`
 }
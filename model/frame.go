@@ -0,0 +1,432 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// FrameType represents the shape of data frame, helps consumer to choose how to use it.
+type FrameType = string
+
+// Defines all frame types.
+var (
+	FrameTypeTimeSeries FrameType = "timeseries"
+	FrameTypeTable      FrameType = "table"
+	FrameTypeLogs       FrameType = "logs"
+	FrameTypeTrace      FrameType = "trace"
+)
+
+// FieldType represents the value type of field.
+type FieldType = string
+
+// Defines all field types.
+var (
+	// FieldTypeTime represents timestamp(milliseconds), value type: int64.
+	FieldTypeTime FieldType = "time"
+	// FieldTypeNumber represents float value, value type: float64.
+	FieldTypeNumber FieldType = "number"
+	// FieldTypeInteger represents integer value, value type: int64.
+	FieldTypeInteger FieldType = "integer"
+	// FieldTypeString represents string value, value type: string.
+	FieldTypeString FieldType = "string"
+	// FieldTypeBoolean represents boolean value, value type: bool.
+	FieldTypeBoolean FieldType = "boolean"
+	// FieldTypeOther represents any json value(object/array etc.).
+	FieldTypeOther FieldType = "other"
+)
+
+// FieldConfig represents the display config of field.
+type FieldConfig struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+}
+
+// Field represents a column of data frame, all values have same type, nil means null.
+type Field struct {
+	Name   string            `json:"name"`
+	Type   FieldType         `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Config *FieldConfig      `json:"config,omitempty"`
+	Values []any             `json:"-"`
+}
+
+// NewField creates a field with name/type/labels.
+func NewField(name string, fieldType FieldType, labels map[string]string) *Field {
+	return &Field{
+		Name:   name,
+		Type:   fieldType,
+		Labels: labels,
+	}
+}
+
+// Len returns the number of values.
+func (f *Field) Len() int {
+	return len(f.Values)
+}
+
+// Append appends a value after converting it to the value type of field, nil means null.
+func (f *Field) Append(value any) error {
+	v, err := convertValue(f.Type, value)
+	if err != nil {
+		return fmt.Errorf("field '%s': %w", f.Name, err)
+	}
+	f.Values = append(f.Values, v)
+	return nil
+}
+
+// FrameMeta represents the metadata of data frame.
+type FrameMeta struct {
+	Type FrameType `json:"type,omitempty"`
+	// Interval represents the interval of time series(milliseconds).
+	Interval int64 `json:"interval,omitempty"`
+	// TimeRange represents the time range of data.
+	TimeRange *TimeRange `json:"timeRange,omitempty"`
+	// ExecutedQuery represents the query executed by backend.
+	ExecutedQuery string `json:"executedQuery,omitempty"`
+	// Custom represents the backend specific metadata.
+	Custom map[string]any `json:"custom,omitempty"`
+}
+
+// Frame represents the columnar data which returned by datasource plugin,
+// all fields have same length.
+type Frame struct {
+	Name   string
+	RefID  string
+	Meta   *FrameMeta
+	Fields []*Field
+}
+
+// NewFrame creates a data frame with fields.
+func NewFrame(name string, fields ...*Field) *Frame {
+	return &Frame{
+		Name:   name,
+		Fields: fields,
+	}
+}
+
+// SetMeta sets the metadata of frame, returns frame itself.
+func (f *Frame) SetMeta(meta *FrameMeta) *Frame {
+	f.Meta = meta
+	return f
+}
+
+// Rows returns the number of rows.
+func (f *Frame) Rows() int {
+	if len(f.Fields) == 0 {
+		return 0
+	}
+	return f.Fields[0].Len()
+}
+
+// AppendRow appends a row, the number of values must be equal the number of fields,
+// nothing is appended if any value cannot be converted.
+func (f *Frame) AppendRow(values ...any) error {
+	if len(values) != len(f.Fields) {
+		return fmt.Errorf("row has %d values, but frame has %d fields", len(values), len(f.Fields))
+	}
+	row := make([]any, len(values))
+	for idx, field := range f.Fields {
+		v, err := convertValue(field.Type, values[idx])
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", field.Name, err)
+		}
+		row[idx] = v
+	}
+	for idx, field := range f.Fields {
+		field.Values = append(field.Values, row[idx])
+	}
+	return nil
+}
+
+// Validate checks if all fields have same length.
+func (f *Frame) Validate() error {
+	rows := f.Rows()
+	for _, field := range f.Fields {
+		if field.Len() != rows {
+			return fmt.Errorf("field '%s' has %d values, expect %d", field.Name, field.Len(), rows)
+		}
+	}
+	return nil
+}
+
+// ShiftTime moves the values of time fields and the time range of metadata by offset(milliseconds).
+func (f *Frame) ShiftTime(offset int64) {
+	for _, field := range f.Fields {
+		if field.Type != FieldTypeTime {
+			continue
+		}
+		for idx, v := range field.Values {
+			if ts, ok := v.(int64); ok {
+				field.Values[idx] = ts + offset
+			}
+		}
+	}
+	if f.Meta != nil && f.Meta.TimeRange != nil {
+		f.Meta.TimeRange = &TimeRange{From: f.Meta.TimeRange.From + offset, To: f.Meta.TimeRange.To + offset}
+	}
+}
+
+// frameSchema represents the schema part of frame json.
+type frameSchema struct {
+	Name   string     `json:"name,omitempty"`
+	RefID  string     `json:"refId,omitempty"`
+	Meta   *FrameMeta `json:"meta,omitempty"`
+	Fields []*Field   `json:"fields"`
+}
+
+// frameJSON represents the json format of frame, values are column oriented.
+type frameJSON struct {
+	Schema frameSchema `json:"schema"`
+	Data   struct {
+		Values [][]any `json:"values"`
+	} `json:"data"`
+}
+
+// frameRawJSON is used to unmarshal frame json, values are converted by field type.
+type frameRawJSON struct {
+	Schema frameSchema `json:"schema"`
+	Data   struct {
+		Values [][]json.RawMessage `json:"values"`
+	} `json:"data"`
+}
+
+// MarshalJSON encodes frame as json, NaN/Inf number is encoded as null.
+func (f *Frame) MarshalJSON() ([]byte, error) {
+	rs := frameJSON{
+		Schema: frameSchema{Name: f.Name, RefID: f.RefID, Meta: f.Meta, Fields: f.Fields},
+	}
+	if rs.Schema.Fields == nil {
+		rs.Schema.Fields = []*Field{}
+	}
+	rs.Data.Values = make([][]any, len(f.Fields))
+	for idx, field := range f.Fields {
+		values := field.Values
+		if values == nil {
+			values = []any{}
+		}
+		if field.Type == FieldTypeNumber {
+			values = make([]any, len(field.Values))
+			for i, v := range field.Values {
+				if n, ok := v.(float64); ok && (math.IsNaN(n) || math.IsInf(n, 0)) {
+					continue
+				}
+				values[i] = v
+			}
+		}
+		rs.Data.Values[idx] = values
+	}
+	return json.Marshal(&rs)
+}
+
+// UnmarshalJSON decodes frame from json, converts values by field type.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	rs := frameRawJSON{}
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return err
+	}
+	if len(rs.Data.Values) != len(rs.Schema.Fields) {
+		return fmt.Errorf("frame has %d fields, but %d value columns", len(rs.Schema.Fields), len(rs.Data.Values))
+	}
+	for idx, field := range rs.Schema.Fields {
+		field.Values = make([]any, 0, len(rs.Data.Values[idx]))
+		for _, raw := range rs.Data.Values[idx] {
+			v, err := decodeValue(field.Type, raw)
+			if err != nil {
+				return fmt.Errorf("field '%s': %w", field.Name, err)
+			}
+			field.Values = append(field.Values, v)
+		}
+	}
+	f.Name = rs.Schema.Name
+	f.RefID = rs.Schema.RefID
+	f.Meta = rs.Schema.Meta
+	f.Fields = rs.Schema.Fields
+	return f.Validate()
+}
+
+// Frames represents the data frames returned by data query.
+type Frames []*Frame
+
+// Count returns the number of series(number fields of time series frames) and non-null values of series.
+func (frames Frames) Count() (series, points int) {
+	for _, frame := range frames {
+		if frame.Meta == nil || frame.Meta.Type != FrameTypeTimeSeries {
+			continue
+		}
+		for _, field := range frame.Fields {
+			if field.Type != FieldTypeNumber {
+				continue
+			}
+			series++
+			for _, v := range field.Values {
+				if v != nil {
+					points++
+				}
+			}
+		}
+	}
+	return series, points
+}
+
+// decodeValue decodes json value by field type, keeps the precision of integer.
+func decodeValue(fieldType FieldType, raw json.RawMessage) (any, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	switch fieldType {
+	case FieldTypeTime, FieldTypeInteger:
+		v, err := strconv.ParseInt(string(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value: %s", raw)
+		}
+		return v, nil
+	case FieldTypeOther:
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return convertValue(fieldType, v)
+	}
+}
+
+// convertValue converts value to the value type of field.
+func convertValue(fieldType FieldType, value any) (any, error) {
+	value = deref(value)
+	if value == nil {
+		return nil, nil
+	}
+	switch fieldType {
+	case FieldTypeTime:
+		if t, ok := value.(time.Time); ok {
+			return t.UnixMilli(), nil
+		}
+		return toInt64(value)
+	case FieldTypeInteger:
+		return toInt64(value)
+	case FieldTypeNumber:
+		return toFloat64(value)
+	case FieldTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		case fmt.Stringer:
+			return v.String(), nil
+		}
+	case FieldTypeBoolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case FieldTypeOther:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("field type not support, type: %s", fieldType)
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, fieldType)
+}
+
+// deref returns the value which pointer points to, nil pointer returns nil.
+func deref(value any) any {
+	switch v := value.(type) {
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
+
+// toInt64 converts numeric value to int64.
+func toInt64(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("not integer value: %v", v)
+		}
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	}
+	return nil, fmt.Errorf("not integer value: %v", value)
+}
+
+// toFloat64 converts numeric value to float64.
+func toFloat64(value any) (any, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	}
+	return nil, fmt.Errorf("not numeric value: %v", value)
+}
@@ -0,0 +1,246 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// keys of arrow schema/field metadata.
+const (
+	arrowNameKey   = "name"
+	arrowRefIDKey  = "refId"
+	arrowMetaKey   = "meta"
+	arrowTypeKey   = "type"
+	arrowLabelsKey = "labels"
+	arrowConfigKey = "config"
+)
+
+// MarshalArrow encodes frames as Arrow IPC streams, one stream per frame(frames may have different schema).
+func (frames Frames) MarshalArrow() ([][]byte, error) {
+	rs := make([][]byte, 0, len(frames))
+	for _, frame := range frames {
+		data, err := frame.MarshalArrow()
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, data)
+	}
+	return rs, nil
+}
+
+// MarshalArrow encodes frame as Arrow IPC stream, frame name/meta and field type/labels/config are
+// stored in metadata, value of other type field is encoded as json string.
+func (f *Frame) MarshalArrow() ([]byte, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	fields := make([]arrow.Field, len(f.Fields))
+	for idx, field := range f.Fields {
+		dataType, err := arrowDataType(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		md := map[string]string{arrowTypeKey: field.Type}
+		if len(field.Labels) > 0 {
+			md[arrowLabelsKey] = string(mustMarshalJSON(field.Labels))
+		}
+		if field.Config != nil {
+			md[arrowConfigKey] = string(mustMarshalJSON(field.Config))
+		}
+		fields[idx] = arrow.Field{Name: field.Name, Type: dataType, Nullable: true, Metadata: arrow.MetadataFrom(md)}
+	}
+	md := map[string]string{arrowNameKey: f.Name, arrowRefIDKey: f.RefID}
+	if f.Meta != nil {
+		md[arrowMetaKey] = string(mustMarshalJSON(f.Meta))
+	}
+	schemaMD := arrow.MetadataFrom(md)
+	schema := arrow.NewSchema(fields, &schemaMD)
+
+	pool := memory.NewGoAllocator()
+	builder := array.NewRecordBuilder(pool, schema)
+	defer builder.Release()
+	for idx, field := range f.Fields {
+		if err := appendArrowValues(builder.Field(idx), field); err != nil {
+			return nil, err
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalArrow decodes frame from Arrow IPC stream which encoded by Frame.MarshalArrow.
+func UnmarshalArrow(data []byte) (*Frame, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	schema := reader.Schema()
+	frame := &Frame{}
+	md := schema.Metadata()
+	frame.Name = metadataValue(md, arrowNameKey)
+	frame.RefID = metadataValue(md, arrowRefIDKey)
+	if meta := metadataValue(md, arrowMetaKey); meta != "" {
+		frame.Meta = &FrameMeta{}
+		if err := json.Unmarshal([]byte(meta), frame.Meta); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range schema.Fields() {
+		field := NewField(f.Name, metadataValue(f.Metadata, arrowTypeKey), nil)
+		if labels := metadataValue(f.Metadata, arrowLabelsKey); labels != "" {
+			if err := json.Unmarshal([]byte(labels), &field.Labels); err != nil {
+				return nil, err
+			}
+		}
+		if cfg := metadataValue(f.Metadata, arrowConfigKey); cfg != "" {
+			field.Config = &FieldConfig{}
+			if err := json.Unmarshal([]byte(cfg), field.Config); err != nil {
+				return nil, err
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	for reader.Next() {
+		record := reader.Record()
+		for idx, field := range frame.Fields {
+			if err := readArrowValues(record.Column(idx), field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// arrowDataType returns the arrow data type of field type.
+func arrowDataType(fieldType FieldType) (arrow.DataType, error) {
+	switch fieldType {
+	case FieldTypeTime:
+		return arrow.FixedWidthTypes.Timestamp_ms, nil
+	case FieldTypeNumber:
+		return arrow.PrimitiveTypes.Float64, nil
+	case FieldTypeInteger:
+		return arrow.PrimitiveTypes.Int64, nil
+	case FieldTypeBoolean:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case FieldTypeString, FieldTypeOther:
+		return arrow.BinaryTypes.String, nil
+	default:
+		return nil, fmt.Errorf("field type not support, type: %s", fieldType)
+	}
+}
+
+// appendArrowValues appends the values of field into arrow array builder.
+func appendArrowValues(builder array.Builder, field *Field) error {
+	for _, value := range field.Values {
+		if value == nil {
+			builder.AppendNull()
+			continue
+		}
+		switch b := builder.(type) {
+		case *array.TimestampBuilder:
+			b.Append(arrow.Timestamp(value.(int64)))
+		case *array.Float64Builder:
+			b.Append(value.(float64))
+		case *array.Int64Builder:
+			b.Append(value.(int64))
+		case *array.BooleanBuilder:
+			b.Append(value.(bool))
+		case *array.StringBuilder:
+			if field.Type == FieldTypeOther {
+				data, err := json.Marshal(value)
+				if err != nil {
+					return fmt.Errorf("field '%s': %w", field.Name, err)
+				}
+				b.Append(string(data))
+			} else {
+				b.Append(value.(string))
+			}
+		}
+	}
+	return nil
+}
+
+// readArrowValues reads the values of arrow array into field.
+func readArrowValues(column arrow.Array, field *Field) error {
+	for i := 0; i < column.Len(); i++ {
+		if column.IsNull(i) {
+			field.Values = append(field.Values, nil)
+			continue
+		}
+		var value any
+		switch c := column.(type) {
+		case *array.Timestamp:
+			value = int64(c.Value(i))
+		case *array.Float64:
+			value = c.Value(i)
+		case *array.Int64:
+			value = c.Value(i)
+		case *array.Boolean:
+			value = c.Value(i)
+		case *array.String:
+			value = c.Value(i)
+			if field.Type == FieldTypeOther {
+				if err := json.Unmarshal([]byte(c.Value(i)), &value); err != nil {
+					return fmt.Errorf("field '%s': %w", field.Name, err)
+				}
+			}
+		default:
+			return fmt.Errorf("arrow data type not support, type: %s", column.DataType())
+		}
+		field.Values = append(field.Values, value)
+	}
+	return nil
+}
+
+// metadataValue returns the value of key in arrow metadata, returns empty string if not exist.
+func metadataValue(md arrow.Metadata, key string) string {
+	idx := md.FindKey(key)
+	if idx < 0 {
+		return ""
+	}
+	return md.Values()[idx]
+}
+
+// mustMarshalJSON encodes the value(which always can be encoded) as json.
+func mustMarshalJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,67 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrame_Arrow(t *testing.T) {
+	frame := NewFrame("cpu",
+		NewField("time", FieldTypeTime, nil),
+		NewField("value", FieldTypeNumber, map[string]string{"host": "a"}),
+		NewField("duration", FieldTypeInteger, nil),
+		NewField("name", FieldTypeString, nil),
+		NewField("ok", FieldTypeBoolean, nil),
+		NewField("tags", FieldTypeOther, nil),
+	).SetMeta(&FrameMeta{Type: FrameTypeTimeSeries, Interval: 60000})
+	frame.RefID = "A"
+	frame.Fields[1].Config = &FieldConfig{Unit: "percent"}
+	assert.NoError(t, frame.AppendRow(1680000000000, 1.5, 10, "a", true, map[string]any{"a": "b"}))
+	assert.NoError(t, frame.AppendRow(1680000060000, nil, nil, nil, nil, nil))
+
+	data, err := Frames{frame}.MarshalArrow()
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+
+	frame2, err := UnmarshalArrow(data[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", frame2.Name)
+	assert.Equal(t, "A", frame2.RefID)
+	assert.Equal(t, frame.Meta, frame2.Meta)
+	assert.Equal(t, map[string]string{"host": "a"}, frame2.Fields[1].Labels)
+	assert.Equal(t, &FieldConfig{Unit: "percent"}, frame2.Fields[1].Config)
+	for idx, field := range frame.Fields {
+		assert.Equal(t, field.Name, frame2.Fields[idx].Name)
+		assert.Equal(t, field.Type, frame2.Fields[idx].Type)
+		assert.Equal(t, field.Values, frame2.Fields[idx].Values)
+	}
+}
+
+func TestFrame_Arrow_Failure(t *testing.T) {
+	_, err := NewFrame("a", NewField("a", "unknown", nil)).MarshalArrow()
+	assert.Error(t, err)
+	frame := NewFrame("a", NewField("a", FieldTypeString, nil), NewField("b", FieldTypeString, nil))
+	assert.NoError(t, frame.Fields[0].Append("a"))
+	_, err = Frames{frame}.MarshalArrow()
+	assert.Error(t, err)
+	_, err = UnmarshalArrow([]byte("abc"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,247 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lindb/common/models"
+)
+
+// names of fields in time series/trace frame.
+const (
+	TimeFieldName    = "time"
+	ProcessCustomKey = "process"
+)
+
+// NewTimeSeriesFrame creates a time series frame which has time field and value fields(sorted by name),
+// each value field has same labels, rows are sorted by timestamp, missing point is null.
+func NewTimeSeriesFrame(name string, labels map[string]string, fields map[string]map[int64]float64) *Frame {
+	fieldNames := make([]string, 0, len(fields))
+	timestampSet := make(map[int64]struct{})
+	for fieldName, points := range fields {
+		fieldNames = append(fieldNames, fieldName)
+		for timestamp := range points {
+			timestampSet[timestamp] = struct{}{}
+		}
+	}
+	sort.Strings(fieldNames)
+	timestamps := make([]int64, 0, len(timestampSet))
+	for timestamp := range timestampSet {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+
+	timeField := NewField(TimeFieldName, FieldTypeTime, nil)
+	for _, timestamp := range timestamps {
+		timeField.Values = append(timeField.Values, timestamp)
+	}
+	frame := NewFrame(name, timeField)
+	for _, fieldName := range fieldNames {
+		field := NewField(fieldName, FieldTypeNumber, labels)
+		points := fields[fieldName]
+		for _, timestamp := range timestamps {
+			if value, ok := points[timestamp]; ok {
+				field.Values = append(field.Values, value)
+			} else {
+				field.Values = append(field.Values, nil)
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame.SetMeta(&FrameMeta{Type: FrameTypeTimeSeries})
+}
+
+// NewFramesFromResultSet converts the result set of LinDB(same shape for synthetic data) to time series frames,
+// one frame per series.
+func NewFramesFromResultSet(rs *models.ResultSet) Frames {
+	if rs == nil {
+		return nil
+	}
+	frames := make(Frames, 0, len(rs.Series))
+	for _, series := range rs.Series {
+		frame := NewTimeSeriesFrame(rs.MetricName, series.Tags, series.Fields)
+		frame.Meta.Interval = rs.Interval
+		if rs.StartTime > 0 || rs.EndTime > 0 {
+			frame.Meta.TimeRange = &TimeRange{From: rs.StartTime, To: rs.EndTime}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// NewFramesFromTraces converts traces to trace frames, one frame per process, one row per span,
+// the process is stored in custom metadata.
+func NewFramesFromTraces(traces []*Trace) Frames {
+	frames := make(Frames, 0, len(traces))
+	for _, trace := range traces {
+		traceID := NewField("traceId", FieldTypeString, nil)
+		parentSpanID := NewField("parentSpanId", FieldTypeString, nil)
+		spanID := NewField("spanId", FieldTypeString, nil)
+		traceState := NewField("traceState", FieldTypeString, nil)
+		name := NewField("name", FieldTypeString, nil)
+		kind := NewField("kind", FieldTypeString, nil)
+		startTime := NewField("startTime", FieldTypeInteger, nil)
+		endTime := NewField("endTime", FieldTypeInteger, nil)
+		duration := NewField("duration", FieldTypeInteger, nil)
+		tags := NewField("tags", FieldTypeOther, nil)
+		events := NewField("events", FieldTypeOther, nil)
+		links := NewField("links", FieldTypeOther, nil)
+		for _, span := range trace.Spans {
+			traceID.Values = append(traceID.Values, span.TraceID)
+			parentSpanID.Values = append(parentSpanID.Values, span.ParentSpanID)
+			spanID.Values = append(spanID.Values, span.SpanID)
+			traceState.Values = append(traceState.Values, span.TraceState)
+			name.Values = append(name.Values, span.Name)
+			kind.Values = append(kind.Values, span.Kind)
+			startTime.Values = append(startTime.Values, span.StartTime)
+			endTime.Values = append(endTime.Values, span.EndTime)
+			duration.Values = append(duration.Values, span.Duration)
+			tags.Values = append(tags.Values, otherValue(span.Tags))
+			events.Values = append(events.Values, otherValue(span.Events))
+			links.Values = append(links.Values, otherValue(span.Links))
+		}
+		frameName := ""
+		if trace.Process != nil {
+			frameName = trace.Process.ServiceName
+		}
+		frame := NewFrame(frameName, traceID, parentSpanID, spanID, traceState, name, kind,
+			startTime, endTime, duration, tags, events, links)
+		frames = append(frames, frame.SetMeta(&FrameMeta{
+			Type:   FrameTypeTrace,
+			Custom: map[string]any{ProcessCustomKey: trace.Process},
+		}))
+	}
+	return frames
+}
+
+// traceSummary represents the summary of a trace, which is merged by the spans of all processes.
+type traceSummary struct {
+	traceID   string
+	root      *Span
+	service   string
+	startTime int64
+	endTime   int64
+	spans     int
+	services  []string
+}
+
+// NewTraceSummaryFrame converts the traces(spans grouped by process) of trace search to a table frame,
+// one row per trace id, rows are sorted by start time desc, duration is nanoseconds.
+func NewTraceSummaryFrame(traces []*Trace) *Frame {
+	summaries := make(map[string]*traceSummary)
+	var traceIDs []string
+	for _, trace := range traces {
+		if trace == nil {
+			continue
+		}
+		serviceName := ""
+		if trace.Process != nil {
+			serviceName = trace.Process.ServiceName
+		}
+		for _, span := range trace.Spans {
+			if span == nil {
+				continue
+			}
+			summary, ok := summaries[span.TraceID]
+			if !ok {
+				summary = &traceSummary{traceID: span.TraceID, startTime: span.StartTime, endTime: span.EndTime}
+				summaries[span.TraceID] = summary
+				traceIDs = append(traceIDs, span.TraceID)
+			}
+			summary.spans++
+			if span.StartTime < summary.startTime {
+				summary.startTime = span.StartTime
+			}
+			if span.EndTime > summary.endTime {
+				summary.endTime = span.EndTime
+			}
+			if summary.root == nil || preferRoot(span, summary.root) {
+				summary.root = span
+				summary.service = serviceName
+			}
+			if serviceName != "" && !contains(summary.services, serviceName) {
+				summary.services = append(summary.services, serviceName)
+			}
+		}
+	}
+	sort.SliceStable(traceIDs, func(i, j int) bool {
+		return summaries[traceIDs[i]].startTime > summaries[traceIDs[j]].startTime
+	})
+	traceID := NewField("traceId", FieldTypeString, nil)
+	rootService := NewField("rootService", FieldTypeString, nil)
+	rootName := NewField("rootName", FieldTypeString, nil)
+	startTime := NewField("startTime", FieldTypeTime, nil)
+	duration := NewField("duration", FieldTypeInteger, nil)
+	duration.Config = &FieldConfig{Unit: "ns"}
+	spans := NewField("spans", FieldTypeInteger, nil)
+	services := NewField("services", FieldTypeOther, nil)
+	for _, id := range traceIDs {
+		summary := summaries[id]
+		traceID.Values = append(traceID.Values, summary.traceID)
+		rootService.Values = append(rootService.Values, summary.service)
+		rootName.Values = append(rootName.Values, summary.root.Name)
+		startTime.Values = append(startTime.Values, summary.startTime/int64(time.Millisecond))
+		duration.Values = append(duration.Values, summary.endTime-summary.startTime)
+		spans.Values = append(spans.Values, int64(summary.spans))
+		services.Values = append(services.Values, summary.services)
+	}
+	frame := NewFrame("traces", traceID, rootService, rootName, startTime, duration, spans, services)
+	return frame.SetMeta(&FrameMeta{Type: FrameTypeTable})
+}
+
+// preferRoot checks if span is more likely the root span of trace than current root,
+// prefers the span without parent, then the earliest one.
+func preferRoot(span, root *Span) bool {
+	if (span.ParentSpanID == "") != (root.ParentSpanID == "") {
+		return span.ParentSpanID == ""
+	}
+	return span.StartTime < root.StartTime
+}
+
+// contains checks if values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// otherValue returns nil if value is empty map/slice, avoids typed nil in other field.
+func otherValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return nil
+		}
+	case []*SpanEvent:
+		if len(v) == 0 {
+			return nil
+		}
+	case []*SpanLink:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/lindb/common/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrame_NewFramesFromResultSet(t *testing.T) {
+	assert.Nil(t, NewFramesFromResultSet(nil))
+	rs := &models.ResultSet{MetricName: "cpu", StartTime: 1680000000000, EndTime: 1680000060000, Interval: 60000}
+	series := models.NewSeries(map[string]string{"host": "a"}, "")
+	series.Fields["max"] = map[int64]float64{1680000060000: 2, 1680000000000: 1}
+	series.Fields["min"] = map[int64]float64{1680000060000: 0}
+	rs.AddSeries(series)
+	frames := NewFramesFromResultSet(rs)
+	assert.Len(t, frames, 1)
+	frame := frames[0]
+	assert.Equal(t, "cpu", frame.Name)
+	assert.Equal(t, &FrameMeta{
+		Type:      FrameTypeTimeSeries,
+		Interval:  60000,
+		TimeRange: &TimeRange{From: 1680000000000, To: 1680000060000},
+	}, frame.Meta)
+	assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frame.Fields[0].Values)
+	assert.Equal(t, "max", frame.Fields[1].Name)
+	assert.Equal(t, []any{1.0, 2.0}, frame.Fields[1].Values)
+	assert.Equal(t, map[string]string{"host": "a"}, frame.Fields[2].Labels)
+	assert.Equal(t, []any{nil, 0.0}, frame.Fields[2].Values)
+	assert.NoError(t, frame.Validate())
+}
+
+func TestFrame_NewFramesFromTraces(t *testing.T) {
+	process := &Process{ServiceName: "order"}
+	frames := NewFramesFromTraces([]*Trace{{
+		Process: process,
+		Spans: []*Span{
+			{TraceID: "t1", SpanID: "s1", Name: "get", StartTime: 1, EndTime: 3, Duration: 2, Tags: map[string]any{"a": "b"}},
+			{TraceID: "t1", SpanID: "s2", ParentSpanID: "s1", Name: "select"},
+		},
+	}})
+	assert.Len(t, frames, 1)
+	frame := frames[0]
+	assert.Equal(t, "order", frame.Name)
+	assert.Equal(t, FrameTypeTrace, frame.Meta.Type)
+	assert.Equal(t, process, frame.Meta.Custom[ProcessCustomKey])
+	assert.Equal(t, 2, frame.Rows())
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, []any{"", "s1"}, frame.Fields[1].Values)
+	assert.Equal(t, []any{int64(2), int64(0)}, frame.Fields[8].Values)
+	assert.Equal(t, []any{map[string]any{"a": "b"}, nil}, frame.Fields[9].Values)
+	assert.Equal(t, []any{nil, nil}, frame.Fields[10].Values)
+}
+
+func TestFrame_NewTraceSummaryFrame(t *testing.T) {
+	frame := NewTraceSummaryFrame([]*Trace{
+		{
+			Process: &Process{ServiceName: "order"},
+			Spans: []*Span{
+				{TraceID: "t1", SpanID: "s2", ParentSpanID: "s1", Name: "select", StartTime: 1e6, EndTime: 2e6},
+				{TraceID: "t1", SpanID: "s1", Name: "get", StartTime: 2e6, EndTime: 5e6},
+				{TraceID: "t2", SpanID: "s3", ParentSpanID: "s0", Name: "list", StartTime: 9e6, EndTime: 10e6},
+			},
+		},
+		{
+			Process: &Process{ServiceName: "user"},
+			Spans: []*Span{
+				{TraceID: "t1", SpanID: "s4", ParentSpanID: "s1", Name: "query", StartTime: 3e6, EndTime: 7e6},
+				{TraceID: "t2", SpanID: "s5", ParentSpanID: "s0", Name: "find", StartTime: 8e6, EndTime: 9e6},
+			},
+		},
+	})
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, FrameTypeTable, frame.Meta.Type)
+	assert.Equal(t, []any{"t2", "t1"}, frame.Fields[0].Values)
+	assert.Equal(t, []any{"user", "order"}, frame.Fields[1].Values)
+	assert.Equal(t, []any{"find", "get"}, frame.Fields[2].Values)
+	assert.Equal(t, []any{int64(8), int64(1)}, frame.Fields[3].Values)
+	assert.Equal(t, []any{int64(2e6), int64(6e6)}, frame.Fields[4].Values)
+	assert.Equal(t, []any{int64(2), int64(3)}, frame.Fields[5].Values)
+	assert.Equal(t, []any{[]string{"order", "user"}, []string{"order", "user"}}, frame.Fields[6].Values)
+
+	frame = NewTraceSummaryFrame(nil)
+	assert.Equal(t, 0, frame.Rows())
+
+	// empty spans, nil process/trace/span
+	frame = NewTraceSummaryFrame([]*Trace{
+		nil,
+		{Process: &Process{ServiceName: "order"}},
+		{Process: &Process{ServiceName: "user"}, Spans: []*Span{}},
+		{Spans: []*Span{nil, {TraceID: "t1", SpanID: "s1", Name: "get", StartTime: 2e6, EndTime: 5e6}}},
+	})
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, []any{"t1"}, frame.Fields[0].Values)
+	assert.Equal(t, []any{""}, frame.Fields[1].Values)
+	assert.Equal(t, []any{"get"}, frame.Fields[2].Values)
+	assert.Equal(t, []any{[]string(nil)}, frame.Fields[6].Values)
+}
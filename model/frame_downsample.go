@@ -0,0 +1,195 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DownsampleAlgorithm represents the algorithm which reduces the points of time series.
+type DownsampleAlgorithm = string
+
+// Defines all downsampling algorithms.
+var (
+	// DownsampleNone disables downsampling.
+	DownsampleNone DownsampleAlgorithm = "none"
+	// DownsampleLTTB selects points by largest-triangle-three-buckets, keeps the visual shape of series.
+	DownsampleLTTB DownsampleAlgorithm = "lttb"
+	// DownsampleMinMax keeps the min/max points of each bucket, keeps all peaks of series.
+	DownsampleMinMax DownsampleAlgorithm = "minmax"
+)
+
+// Downsample reduces the rows of time series frame to about max points by algorithm, the first/last rows are kept.
+// If frame has multiple number fields, the rows selected for each field are kept.
+// Frame which is not time series or has fewer rows is not changed.
+func (f *Frame) Downsample(algorithm DownsampleAlgorithm, maxPoints int) error {
+	var selectFn func(times, values []any, maxPoints int) []int
+	switch algorithm {
+	case "", DownsampleNone:
+		return nil
+	case DownsampleLTTB:
+		selectFn = lttb
+	case DownsampleMinMax:
+		selectFn = minMax
+	default:
+		return fmt.Errorf("downsampling algorithm not support: %s", algorithm)
+	}
+	if f.Meta == nil || f.Meta.Type != FrameTypeTimeSeries || maxPoints < 3 || f.Rows() <= maxPoints {
+		return nil
+	}
+	var times *Field
+	for _, field := range f.Fields {
+		if field.Type == FieldTypeTime {
+			times = field
+			break
+		}
+	}
+	if times == nil {
+		return nil
+	}
+	selected := make(map[int]struct{})
+	for _, field := range f.Fields {
+		if field.Type != FieldTypeNumber {
+			continue
+		}
+		for _, idx := range selectFn(times.Values, field.Values, maxPoints) {
+			selected[idx] = struct{}{}
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	rows := make([]int, 0, len(selected))
+	for idx := range selected {
+		rows = append(rows, idx)
+	}
+	sort.Ints(rows)
+	for _, field := range f.Fields {
+		values := make([]any, 0, len(rows))
+		for _, idx := range rows {
+			values = append(values, field.Values[idx])
+		}
+		field.Values = values
+	}
+	return nil
+}
+
+// point returns the time/value of point as float64, returns false if time or value is null.
+func point(times, values []any, idx int) (x, y float64, ok bool) {
+	ts, ok := times[idx].(int64)
+	if !ok {
+		return 0, 0, false
+	}
+	v, ok := values[idx].(float64)
+	if !ok || math.IsNaN(v) {
+		return 0, 0, false
+	}
+	return float64(ts), v, true
+}
+
+// lttb selects the points by largest-triangle-three-buckets algorithm, returns the indices of points.
+// Null point is never selected, unless all points of bucket are null(keeps the gap).
+func lttb(times, values []any, maxPoints int) []int {
+	n := len(values)
+	every := float64(n-2) / float64(maxPoints-2)
+	selected := []int{0}
+	// a is the last selected point which is not null
+	ax, ay, aOK := point(times, values, 0)
+	for i := 0; i < maxPoints-2; i++ {
+		// average point of next bucket
+		avgStart, avgEnd := int(float64(i+1)*every)+1, int(float64(i+2)*every)+1
+		if avgEnd > n {
+			avgEnd = n
+		}
+		var avgX, avgY float64
+		count := 0
+		for j := avgStart; j < avgEnd; j++ {
+			if x, y, ok := point(times, values, j); ok {
+				avgX += x
+				avgY += y
+				count++
+			}
+		}
+		if count > 0 {
+			avgX /= float64(count)
+			avgY /= float64(count)
+		}
+		rangeStart, rangeEnd := int(float64(i)*every)+1, int(float64(i+1)*every)+1
+		next, maxArea := rangeStart, -1.0
+		for j := rangeStart; j < rangeEnd; j++ {
+			x, y, ok := point(times, values, j)
+			if !ok {
+				continue
+			}
+			var area float64
+			switch {
+			case aOK && count > 0:
+				area = math.Abs((ax-avgX)*(y-ay)-(ax-x)*(avgY-ay)) / 2
+			case aOK:
+				area = math.Abs(y - ay)
+			case count > 0:
+				area = math.Abs(y - avgY)
+			}
+			if area > maxArea {
+				next, maxArea = j, area
+			}
+		}
+		selected = append(selected, next)
+		if x, y, ok := point(times, values, next); ok {
+			ax, ay, aOK = x, y, true
+		}
+	}
+	return append(selected, n-1)
+}
+
+// minMax splits the points into buckets, selects the min/max points of each bucket, returns the indices of points.
+func minMax(times, values []any, maxPoints int) []int {
+	n := len(values)
+	buckets := maxPoints / 2
+	size := (n + buckets - 1) / buckets
+	selected := []int{0, n - 1}
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		minIdx, maxIdx := -1, -1
+		var minValue, maxValue float64
+		for j := start; j < end; j++ {
+			_, y, ok := point(times, values, j)
+			if !ok {
+				continue
+			}
+			if minIdx < 0 || y < minValue {
+				minIdx, minValue = j, y
+			}
+			if maxIdx < 0 || y > maxValue {
+				maxIdx, maxValue = j, y
+			}
+		}
+		if minIdx < 0 {
+			// all points are null, keeps the gap
+			selected = append(selected, start)
+			continue
+		}
+		selected = append(selected, minIdx, maxIdx)
+	}
+	return selected
+}
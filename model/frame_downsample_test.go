@@ -0,0 +1,135 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSeriesFrame creates a time series frame with values, timestamps are 0,1000,2000...
+func newSeriesFrame(values ...[]any) *Frame {
+	frame := NewFrame("cpu", NewField(TimeFieldName, FieldTypeTime, nil))
+	for idx := range values[0] {
+		frame.Fields[0].Values = append(frame.Fields[0].Values, int64(idx*1000))
+	}
+	for _, v := range values {
+		field := NewField("value", FieldTypeNumber, nil)
+		field.Values = v
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame.SetMeta(&FrameMeta{Type: FrameTypeTimeSeries})
+}
+
+// sine returns the values of sine wave with a peak at index peak.
+func sine(n, peak int) []any {
+	values := make([]any, n)
+	for i := range values {
+		values[i] = math.Sin(float64(i) / 10)
+	}
+	values[peak] = 100.0
+	return values
+}
+
+func TestFrame_Downsample(t *testing.T) {
+	for _, algorithm := range []DownsampleAlgorithm{DownsampleLTTB, DownsampleMinMax} {
+		frame := newSeriesFrame(sine(1000, 555))
+		assert.NoError(t, frame.Downsample(algorithm, 100))
+		assert.NoError(t, frame.Validate())
+		assert.LessOrEqual(t, frame.Rows(), 102, algorithm)
+		assert.GreaterOrEqual(t, frame.Rows(), 90, algorithm)
+		times := frame.Fields[0].Values
+		// first/last points kept, timestamps sorted
+		assert.Equal(t, int64(0), times[0])
+		assert.Equal(t, int64(999000), times[len(times)-1])
+		for i := 1; i < len(times); i++ {
+			assert.Less(t, times[i-1].(int64), times[i].(int64))
+		}
+		// peak kept
+		assert.Contains(t, frame.Fields[1].Values, 100.0, algorithm)
+	}
+}
+
+func TestFrame_Downsample_MultipleFields(t *testing.T) {
+	for _, algorithm := range []DownsampleAlgorithm{DownsampleLTTB, DownsampleMinMax} {
+		frame := newSeriesFrame(sine(1000, 100), sine(1000, 900))
+		assert.NoError(t, frame.Downsample(algorithm, 100))
+		assert.NoError(t, frame.Validate())
+		assert.Less(t, frame.Rows(), 300)
+		assert.Contains(t, frame.Fields[1].Values, 100.0, algorithm)
+		assert.Contains(t, frame.Fields[2].Values, 100.0, algorithm)
+	}
+}
+
+func TestFrame_Downsample_Null(t *testing.T) {
+	for _, algorithm := range []DownsampleAlgorithm{DownsampleLTTB, DownsampleMinMax} {
+		values := sine(1000, 10)
+		// gap
+		for i := 300; i < 500; i++ {
+			values[i] = nil
+		}
+		values[0] = nil
+		values[700] = math.NaN()
+		frame := newSeriesFrame(values)
+		frame.Fields[0].Values[800] = nil
+		assert.NoError(t, frame.Downsample(algorithm, 100))
+		assert.NoError(t, frame.Validate())
+		assert.Contains(t, frame.Fields[1].Values, nil, algorithm)
+		assert.Contains(t, frame.Fields[1].Values, 100.0, algorithm)
+		assert.NotContains(t, frame.Fields[0].Values[1:len(frame.Fields[0].Values)-1], nil, algorithm)
+	}
+
+	// all null
+	values := make([]any, 100)
+	frame := newSeriesFrame(values)
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 10))
+	assert.Equal(t, 10, frame.Rows())
+}
+
+func TestFrame_Downsample_NotChanged(t *testing.T) {
+	frame := newSeriesFrame(sine(100, 10))
+	// no downsampling
+	assert.NoError(t, frame.Downsample("", 10))
+	assert.NoError(t, frame.Downsample(DownsampleNone, 10))
+	// fewer rows
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 100))
+	// invalid max points
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 2))
+	assert.Equal(t, 100, frame.Rows())
+	// not time series
+	frame.Meta.Type = FrameTypeTable
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 10))
+	assert.Equal(t, 100, frame.Rows())
+	frame.Meta = nil
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 10))
+	assert.Equal(t, 100, frame.Rows())
+	// no time field
+	frame = NewFrame("cpu", NewField("value", FieldTypeNumber, nil)).SetMeta(&FrameMeta{Type: FrameTypeTimeSeries})
+	frame.Fields[0].Values = sine(100, 10)
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 10))
+	assert.Equal(t, 100, frame.Rows())
+	// no number field
+	frame = NewFrame("cpu", NewField(TimeFieldName, FieldTypeTime, nil)).SetMeta(&FrameMeta{Type: FrameTypeTimeSeries})
+	frame.Fields[0].Values = make([]any, 100)
+	assert.NoError(t, frame.Downsample(DownsampleLTTB, 10))
+	assert.Equal(t, 100, frame.Rows())
+
+	assert.Error(t, newSeriesFrame(sine(100, 10)).Downsample("unknown", 10))
+}
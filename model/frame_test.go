@@ -0,0 +1,147 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrame_AppendRow(t *testing.T) {
+	frame := NewFrame("cpu",
+		NewField("time", FieldTypeTime, nil),
+		NewField("value", FieldTypeNumber, map[string]string{"host": "a"}),
+		NewField("count", FieldTypeInteger, nil),
+		NewField("name", FieldTypeString, nil),
+		NewField("ok", FieldTypeBoolean, nil),
+	)
+	now := time.UnixMilli(1680000000000)
+	value := 1.5
+	assert.NoError(t, frame.AppendRow(now, &value, 10, []byte("a"), true))
+	assert.NoError(t, frame.AppendRow(int64(1680000060000), nil, 20.0, "b", (*bool)(nil)))
+	assert.Error(t, frame.AppendRow(1))
+	assert.Error(t, frame.AppendRow(1.5, 1, 1, "c", false))
+	assert.Error(t, frame.AppendRow(1, "a", 1, "c", false))
+	assert.Error(t, frame.AppendRow(1, 1, 1, 1, false))
+	assert.Error(t, frame.AppendRow(1, 1, 1, "c", "false"))
+	assert.Error(t, NewField("a", "unknown", nil).Append(1))
+
+	assert.Equal(t, 2, frame.Rows())
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frame.Fields[0].Values)
+	assert.Equal(t, []any{1.5, nil}, frame.Fields[1].Values)
+	assert.Equal(t, []any{int64(10), int64(20)}, frame.Fields[2].Values)
+	assert.Equal(t, []any{"a", "b"}, frame.Fields[3].Values)
+	assert.Equal(t, []any{true, nil}, frame.Fields[4].Values)
+
+	frame.Fields[0].Values = frame.Fields[0].Values[:1]
+	assert.Error(t, frame.Validate())
+	assert.Equal(t, 0, NewFrame("empty").Rows())
+}
+
+func TestFrame_ShiftTime(t *testing.T) {
+	frame := NewFrame("cpu",
+		NewField("time", FieldTypeTime, nil),
+		NewField("value", FieldTypeNumber, nil),
+	).SetMeta(&FrameMeta{TimeRange: &TimeRange{From: 1000, To: 3000}})
+	assert.NoError(t, frame.AppendRow(int64(1000), 1.0))
+	assert.NoError(t, frame.AppendRow(nil, 2.0))
+	frame.ShiftTime(500)
+	assert.Equal(t, []any{int64(1500), nil}, frame.Fields[0].Values)
+	assert.Equal(t, []any{1.0, 2.0}, frame.Fields[1].Values)
+	assert.Equal(t, &TimeRange{From: 1500, To: 3500}, frame.Meta.TimeRange)
+
+	// no metadata
+	frame = NewFrame("cpu", NewField("time", FieldTypeTime, nil))
+	frame.ShiftTime(500)
+	assert.Nil(t, frame.Meta)
+}
+
+func TestFrames_Count(t *testing.T) {
+	series := NewFrame("cpu",
+		NewField("time", FieldTypeTime, nil),
+		NewField("idle", FieldTypeNumber, nil),
+		NewField("user", FieldTypeNumber, nil),
+	).SetMeta(&FrameMeta{Type: FrameTypeTimeSeries})
+	assert.NoError(t, series.AppendRow(int64(1000), 1.0, nil))
+	assert.NoError(t, series.AppendRow(int64(2000), 2.0, 3.0))
+	table := NewFrame("table", NewField("value", FieldTypeNumber, nil))
+	assert.NoError(t, table.AppendRow(1.0))
+
+	count, points := Frames{series, table}.Count()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 3, points)
+}
+
+func TestFrame_JSON(t *testing.T) {
+	frame := NewFrame("cpu",
+		NewField("time", FieldTypeTime, nil),
+		NewField("value", FieldTypeNumber, map[string]string{"host": "a"}),
+		NewField("duration", FieldTypeInteger, nil),
+		NewField("tags", FieldTypeOther, nil),
+	).SetMeta(&FrameMeta{Type: FrameTypeTimeSeries, Interval: 60000})
+	frame.RefID = "A"
+	assert.NoError(t, frame.AppendRow(1680000000000, 1.5, int64(1680000000000000001), map[string]any{"a": "b"}))
+	assert.NoError(t, frame.AppendRow(1680000060000, math.NaN(), nil, nil))
+	assert.NoError(t, frame.AppendRow(1680000120000, math.Inf(1), 1, nil))
+
+	data, err := json.Marshal(frame)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schema":{"name":"cpu","refId":"A","meta":{"type":"timeseries","interval":60000},"fields":[`+
+		`{"name":"time","type":"time"},{"name":"value","type":"number","labels":{"host":"a"}},`+
+		`{"name":"duration","type":"integer"},{"name":"tags","type":"other"}]},`+
+		`"data":{"values":[[1680000000000,1680000060000,1680000120000],[1.5,null,null],`+
+		`[1680000000000000001,null,1],[{"a":"b"},null,null]]}}`, string(data))
+
+	frame2 := &Frame{}
+	assert.NoError(t, json.Unmarshal(data, frame2))
+	assert.Equal(t, "cpu", frame2.Name)
+	assert.Equal(t, "A", frame2.RefID)
+	assert.Equal(t, frame.Meta, frame2.Meta)
+	assert.Equal(t, []any{int64(1680000000000000001), nil, int64(1)}, frame2.Fields[2].Values)
+	assert.Equal(t, []any{map[string]any{"a": "b"}, nil, nil}, frame2.Fields[3].Values)
+
+	// empty frame
+	data, err = json.Marshal(NewFrame("empty", NewField("a", FieldTypeString, nil)))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schema":{"name":"empty","fields":[{"name":"a","type":"string"}]},"data":{"values":[[]]}}`, string(data))
+}
+
+func TestFrame_UnmarshalJSON_Failure(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid json", data: `[]`},
+		{name: "values not match fields", data: `{"schema":{"fields":[{"name":"a","type":"time"}]},"data":{"values":[]}}`},
+		{name: "invalid integer", data: `{"schema":{"fields":[{"name":"a","type":"time"}]},"data":{"values":[[1.5]]}}`},
+		{name: "invalid number", data: `{"schema":{"fields":[{"name":"a","type":"number"}]},"data":{"values":[["a"]]}}`},
+		{name: "invalid length", data: `{"schema":{"fields":[{"name":"a","type":"string"},{"name":"b","type":"string"}]},` +
+			`"data":{"values":[["a"],[]]}}`},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, json.Unmarshal([]byte(tt.data), &Frame{}))
+		})
+	}
+}
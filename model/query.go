@@ -18,7 +18,12 @@
 package model
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lindb/linsight/pkg/datemath"
 )
 
 // TimeRange represents data query time range.
@@ -27,21 +32,154 @@ type TimeRange struct {
 	To   int64 `json:"to"`
 }
 
+// TimeExpr represents the time expression, like epoch milliseconds, now-6h, now/d or now-1w/w.
+type TimeExpr string
+
+// UnmarshalJSON decodes time expression from json string or number(epoch milliseconds).
+func (e *TimeExpr) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*e = ""
+		return nil
+	}
+	var expr string
+	if err := json.Unmarshal(data, &expr); err == nil {
+		*e = TimeExpr(expr)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("invalid time expression: %s", data)
+	}
+	*e = TimeExpr(number)
+	return nil
+}
+
+// RawTimeRange represents data query time range which from/to are time expressions.
+type RawTimeRange struct {
+	From TimeExpr `json:"from"`
+	To   TimeExpr `json:"to"`
+}
+
+// Resolve resolves time expressions as absolute time range based on current time,
+// the time is rounded in location(like now/d), the end of time range is rounded up.
+func (r RawTimeRange) Resolve(now time.Time, loc *time.Location) (TimeRange, error) {
+	from, err := datemath.Parse(string(r.From), now, loc, false)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	to, err := datemath.Parse(string(r.To), now, loc, true)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	if from > 0 && to > 0 && from > to {
+		return TimeRange{}, fmt.Errorf("invalid time range, from(%s) is after to(%s)", r.From, r.To)
+	}
+	return TimeRange{From: from, To: to}, nil
+}
+
+// DataFormat represents the encoding format of data frames in response.
+type DataFormat = string
+
+// Defines all data formats.
+var (
+	JSONDataFormat  DataFormat = "json"
+	ArrowDataFormat DataFormat = "arrow"
+)
+
 type QueryRequest struct {
-	Range   TimeRange `json:"range"`
-	Queries []*Query  `json:"queries"`
+	Range   RawTimeRange `json:"range"`
+	Queries []*Query     `json:"queries"`
+	// TimeZone represents the location which relative time is rounded in(like now/d),
+	// use the time zone of datasource if not set.
+	TimeZone string `json:"timeZone"`
+	// Format represents the encoding format of frames, default json.
+	Format DataFormat `json:"format"`
+	// Variables represents the current values of dashboard variables(string/number or list of them),
+	// which are expanded in query request by server.
+	Variables map[string]any `json:"variables"`
 }
 
 type Query struct {
 	Datasource TargetDatasource `json:"datasource" binding:"required"`
 	Request    json.RawMessage  `json:"request"`
 	RefID      string           `json:"refId"`
+	// Hide represents the query is only used by expression query, its result is not returned.
+	Hide bool `json:"hide"`
+	// MaxDataPoints represents the max number of data points of series, used to calculate interval.
+	MaxDataPoints int64 `json:"maxDataPoints"`
+	// MinInterval represents the lower limit of interval(like 10s), used to calculate interval.
+	MinInterval string `json:"minInterval"`
+	// TimeShift represents the query is executed over the time range shifted back by it(like 1d/1w),
+	// the timestamps of result are moved forward to overlay the current time range.
+	TimeShift string `json:"timeShift"`
+	// Downsample represents the algorithm which reduces the points of series to max data points,
+	// use the setting of datasource if not set.
+	Downsample DownsampleAlgorithm `json:"downsample"`
+	// IntervalMs represents the interval(milliseconds) of data points, calculated by server based on
+	// time range, max data points and min interval.
+	IntervalMs int64 `json:"intervalMs"`
 }
 
 type TargetDatasource struct {
 	UID string `json:"uid" binding:"required"`
 }
 
+// QueryStatus represents the execution status of data query.
+type QueryStatus = string
+
+// Defines all status of data query.
+var (
+	QueryStatusOK    QueryStatus = "ok"
+	QueryStatusError QueryStatus = "error"
+)
+
+// QueryResult represents the result of one query in data query request.
+type QueryResult struct {
+	RefID  string      `json:"refId"`
+	Status QueryStatus `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	// Duration is the cost of query(nanoseconds).
+	Duration time.Duration `json:"duration"`
+	Frames   Frames        `json:"frames,omitempty"`
+	// Arrow represents the frames encoded as Arrow IPC stream if request arrow format.
+	Arrow [][]byte `json:"arrow,omitempty"`
+	// Meta represents the metadata of query, helps to debug query.
+	Meta *QueryMeta `json:"meta,omitempty"`
+}
+
+// QueryMeta represents the metadata of query, like executed statements, cost and size of result.
+type QueryMeta struct {
+	Datasource *QueryDatasource `json:"datasource,omitempty"`
+	// ExecutedQueries represents the statements executed by backend.
+	ExecutedQueries []string `json:"executedQueries,omitempty"`
+	// BackendDuration is the cost of backend query(nanoseconds), zero if result returned from cache.
+	BackendDuration time.Duration `json:"backendDuration"`
+	// Series is the number of series(number fields of time series frames).
+	Series int `json:"series"`
+	// Points is the number of non-null values of series.
+	Points int `json:"points"`
+	// Rows is the number of rows of all frames.
+	Rows int `json:"rows"`
+	// Cache represents the cache status of result, nil if result cache disabled.
+	Cache *QueryCacheInfo `json:"cache,omitempty"`
+}
+
+// QueryDatasource represents the datasource which query executed on.
+type QueryDatasource struct {
+	UID  string         `json:"uid"`
+	Name string         `json:"name,omitempty"`
+	Type DatasourceType `json:"type,omitempty"`
+}
+
+// QueryCacheInfo represents the cache status of query result.
+type QueryCacheInfo struct {
+	// Hit represents the result is returned from cache(or shared with the same query in flight).
+	Hit bool `json:"hit"`
+	// CachedAt is the time when result cached(milliseconds).
+	CachedAt int64 `json:"cachedAt"`
+}
+
+// QueryResponse represents the response of data query request, results keyed by refId.
 type QueryResponse struct {
-	Results map[string]any `json:"results"`
+	Results map[string]*QueryResult `json:"results"`
 }
@@ -0,0 +1,56 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawTimeRange_UnmarshalJSON(t *testing.T) {
+	r := RawTimeRange{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"from":1680000000000,"to":"now/d"}`), &r))
+	assert.Equal(t, RawTimeRange{From: "1680000000000", To: "now/d"}, r)
+	assert.NoError(t, json.Unmarshal([]byte(`{"from":null}`), &r))
+	assert.Equal(t, RawTimeRange{To: "now/d"}, r)
+	assert.Error(t, json.Unmarshal([]byte(`{"from":true}`), &r))
+}
+
+func TestRawTimeRange_Resolve(t *testing.T) {
+	now := time.Date(2023, time.March, 30, 10, 20, 30, 0, time.UTC)
+	rs, err := RawTimeRange{From: "now-6h", To: "now"}.Resolve(now, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, TimeRange{From: now.Add(-6 * time.Hour).UnixMilli(), To: now.UnixMilli()}, rs)
+	rs, err = RawTimeRange{From: "now/d", To: "now/d"}.Resolve(now, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, TimeRange{
+		From: time.Date(2023, time.March, 30, 0, 0, 0, 0, time.UTC).UnixMilli(),
+		To:   time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC).UnixMilli() - 1,
+	}, rs)
+	rs, err = RawTimeRange{}.Resolve(now, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TimeRange{}, rs)
+
+	for _, r := range []RawTimeRange{{From: "x"}, {To: "x"}, {From: "now", To: "now-1h"}} {
+		_, err = r.Resolve(now, time.UTC)
+		assert.Error(t, err)
+	}
+}
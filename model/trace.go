@@ -0,0 +1,121 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import "strings"
+
+// SpanKind represents the kind of span.
+type SpanKind = string
+
+// Defines all span kinds.
+var (
+	SpanKindUnspecified SpanKind = "Unspecified"
+	SpanKindInternal    SpanKind = "Internal"
+	SpanKindServer      SpanKind = "Server"
+	SpanKindClient      SpanKind = "Client"
+	SpanKindProducer    SpanKind = "Producer"
+	SpanKindConsumer    SpanKind = "Consumer"
+)
+
+// Trace represents the spans of a trace which belong to same process, same shape as LinGo returns.
+type Trace struct {
+	Process *Process `json:"process"`
+	Spans   []*Span  `json:"spans"`
+}
+
+// Process represents the process(service instance) which reports spans.
+type Process struct {
+	ServiceName    string         `json:"serviceName"`
+	InstanceID     string         `json:"instanceId"`
+	ServiceVersion string         `json:"serviceVersion"`
+	SDK            string         `json:"sdk"`
+	SDKLanguage    string         `json:"sdkLanguage"`
+	SDKVersion     string         `json:"sdkVersion"`
+	Tags           map[string]any `json:"tags"`
+}
+
+// Span represents a span of trace, all timestamps/duration are nanoseconds.
+type Span struct {
+	TraceID      string         `json:"traceId"`
+	ParentSpanID string         `json:"parentSpanId"`
+	SpanID       string         `json:"spanId"`
+	TraceState   string         `json:"traceState"`
+	Name         string         `json:"name"`
+	Kind         SpanKind       `json:"kind"`
+	StartTime    int64          `json:"startTime"`
+	EndTime      int64          `json:"endTime"`
+	Duration     int64          `json:"duration"`
+	Tags         map[string]any `json:"tags"`
+	Events       []*SpanEvent   `json:"events"`
+	Links        []*SpanLink    `json:"links"`
+}
+
+// SpanEvent represents the event(log/annotation) of span.
+type SpanEvent struct {
+	Name      string         `json:"name"`
+	Timestamp int64          `json:"timestamp"`
+	Tags      map[string]any `json:"tags"`
+}
+
+// SpanLink represents the link of span.
+type SpanLink struct {
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	TraceState string         `json:"traceState"`
+	Tags       map[string]any `json:"tags"`
+}
+
+// ParseSpanKind parses span kind(case-insensitive, e.g. server/SERVER).
+func ParseSpanKind(kind string) SpanKind {
+	switch strings.ToLower(kind) {
+	case "internal":
+		return SpanKindInternal
+	case "server":
+		return SpanKindServer
+	case "client":
+		return SpanKindClient
+	case "producer":
+		return SpanKindProducer
+	case "consumer":
+		return SpanKindConsumer
+	default:
+		return SpanKindUnspecified
+	}
+}
+
+// NewProcess creates a process by service name and resource tags, uses OpenTelemetry resource
+// semantic conventions to fill the instance/version/sdk information.
+func NewProcess(serviceName string, tags map[string]any) *Process {
+	process := &Process{ServiceName: serviceName, Tags: tags}
+	process.InstanceID = tagValue(tags, "service.instance.id", "hostname", "host.name", "ip")
+	process.ServiceVersion = tagValue(tags, "service.version")
+	process.SDK = tagValue(tags, "telemetry.sdk.name")
+	process.SDKLanguage = tagValue(tags, "telemetry.sdk.language")
+	process.SDKVersion = tagValue(tags, "telemetry.sdk.version")
+	return process
+}
+
+// tagValue returns the first not empty string value of keys.
+func tagValue(tags map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := tags[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
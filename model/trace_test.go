@@ -0,0 +1,49 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace_ParseSpanKind(t *testing.T) {
+	assert.Equal(t, SpanKindServer, ParseSpanKind("SERVER"))
+	assert.Equal(t, SpanKindClient, ParseSpanKind("client"))
+	assert.Equal(t, SpanKindProducer, ParseSpanKind("producer"))
+	assert.Equal(t, SpanKindConsumer, ParseSpanKind("CONSUMER"))
+	assert.Equal(t, SpanKindInternal, ParseSpanKind("internal"))
+	assert.Equal(t, SpanKindUnspecified, ParseSpanKind(""))
+}
+
+func TestTrace_NewProcess(t *testing.T) {
+	process := NewProcess("order", map[string]any{
+		"hostname":               "host-1",
+		"service.version":        "1.0.0",
+		"telemetry.sdk.name":     "opentelemetry",
+		"telemetry.sdk.language": "go",
+		"telemetry.sdk.version":  "1.14.0",
+	})
+	assert.Equal(t, "order", process.ServiceName)
+	assert.Equal(t, "host-1", process.InstanceID)
+	assert.Equal(t, "1.0.0", process.ServiceVersion)
+	assert.Equal(t, "opentelemetry", process.SDK)
+	assert.Equal(t, "go", process.SDKLanguage)
+	assert.Equal(t, "1.14.0", process.SDKVersion)
+}
@@ -0,0 +1,185 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+//go:generate mockgen -source=./cache.go -destination=./cache_mock.go -package=cache
+
+// for testing
+var (
+	nowFn = time.Now
+)
+
+// errLoadPanic represents the error returned to waiters if load value panic.
+var errLoadPanic = errors.New("load value panic")
+
+// Cache represents the in-memory cache with ttl and memory budget,
+// evicts least recently used entries if memory budget exceeded.
+type Cache interface {
+	// Get returns the value and the time when it cached, returns false if not found or expired.
+	Get(key string) (value []byte, cachedAt time.Time, ok bool)
+	// Set caches the value, value which exceeds memory budget is ignored.
+	Set(key string, value []byte)
+	// GetOrLoad returns cached value, or loads/caches the value if not found,
+	// concurrent loads for same key are merged into one, the caller who doesn't load value gets hit as true.
+	GetOrLoad(key string, load func() ([]byte, error)) (value []byte, cachedAt time.Time, hit bool, err error)
+	// Size returns the memory used by cached entries(bytes).
+	Size() int64
+}
+
+// entry represents the cached entry.
+type entry struct {
+	key      string
+	value    []byte
+	cachedAt time.Time
+}
+
+// size returns the memory used by entry.
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// call represents the in-flight load of value.
+type call struct {
+	wg       sync.WaitGroup
+	value    []byte
+	cachedAt time.Time
+	err      error
+}
+
+// cache implements Cache interface.
+type cache struct {
+	ttl       time.Duration
+	maxMemory int64
+
+	size     int64
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]*call
+	lock     sync.Mutex
+}
+
+// NewCache creates a Cache instance.
+func NewCache(ttl time.Duration, maxMemory int64) Cache {
+	return &cache{
+		ttl:       ttl,
+		maxMemory: maxMemory,
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		inflight:  make(map[string]*call),
+	}
+}
+
+// Get returns the value and the time when it cached, returns false if not found or expired.
+func (c *cache) Get(key string) (value []byte, cachedAt time.Time, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.get(key)
+}
+
+// Set caches the value, value which exceeds memory budget is ignored.
+func (c *cache) Set(key string, value []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(key, value, nowFn())
+}
+
+// GetOrLoad returns cached value, or loads/caches the value if not found,
+// concurrent loads for same key are merged into one, the caller who doesn't load value gets hit as true.
+func (c *cache) GetOrLoad(key string, load func() ([]byte, error)) (value []byte, cachedAt time.Time, hit bool, err error) {
+	c.lock.Lock()
+	if value, cachedAt, ok := c.get(key); ok {
+		c.lock.Unlock()
+		return value, cachedAt, true, nil
+	}
+	if inflight, ok := c.inflight[key]; ok {
+		c.lock.Unlock()
+		inflight.wg.Wait()
+		return inflight.value, inflight.cachedAt, inflight.err == nil, inflight.err
+	}
+	loading := &call{}
+	loading.wg.Add(1)
+	c.inflight[key] = loading
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.inflight, key)
+		if loading.err == nil {
+			c.set(key, loading.value, loading.cachedAt)
+		}
+		c.lock.Unlock()
+		loading.wg.Done()
+	}()
+	// make sure waiters are released if load panic
+	loading.err = errLoadPanic
+	loading.value, loading.err = load()
+	loading.cachedAt = nowFn()
+	return loading.value, loading.cachedAt, false, loading.err
+}
+
+// Size returns the memory used by cached entries(bytes).
+func (c *cache) Size() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.size
+}
+
+// get returns the value if found and not expired, removes expired entry.
+func (c *cache) get(key string) (value []byte, cachedAt time.Time, ok bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	e := elem.Value.(*entry)
+	if nowFn().Sub(e.cachedAt) >= c.ttl {
+		c.remove(elem)
+		return nil, time.Time{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return e.value, e.cachedAt, true
+}
+
+// set caches the value, then evicts expired/least recently used entries if memory budget exceeded.
+func (c *cache) set(key string, value []byte, cachedAt time.Time) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	e := &entry{key: key, value: value, cachedAt: cachedAt}
+	if e.size() > c.maxMemory {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += e.size()
+	for c.size > c.maxMemory {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes the entry from cache.
+func (c *cache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+}
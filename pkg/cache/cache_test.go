@@ -0,0 +1,147 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetSet(t *testing.T) {
+	now := time.Now()
+	defer func() {
+		nowFn = time.Now
+	}()
+	nowFn = func() time.Time {
+		return now
+	}
+	c := NewCache(time.Minute, 10)
+	_, _, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Set("a", []byte("1234"))
+	value, cachedAt, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1234"), value)
+	assert.Equal(t, now, cachedAt)
+	assert.Equal(t, int64(5), c.Size())
+
+	// replace value
+	c.Set("a", []byte("123"))
+	assert.Equal(t, int64(4), c.Size())
+	// exceed memory budget
+	c.Set("b", []byte("12345678901"))
+	_, _, ok = c.Get("b")
+	assert.False(t, ok)
+	// evict least recently used
+	c.Set("b", []byte("1"))
+	_, _, ok = c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", []byte("1234"))
+	_, _, ok = c.Get("b")
+	assert.False(t, ok)
+	_, _, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), c.Size())
+
+	// expired
+	nowFn = func() time.Time {
+		return now.Add(time.Minute)
+	}
+	_, _, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, int64(5), c.Size())
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := NewCache(time.Minute, 1024)
+	// load failure
+	_, _, hit, err := c.GetOrLoad("a", func() ([]byte, error) {
+		return nil, fmt.Errorf("err")
+	})
+	assert.Error(t, err)
+	assert.False(t, hit)
+	_, _, ok := c.Get("a")
+	assert.False(t, ok)
+
+	// concurrent loads are merged
+	start := make(chan struct{})
+	loads := 0
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	hits := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, hit, err := c.GetOrLoad("a", func() ([]byte, error) {
+				<-start
+				loads++
+				return []byte("value"), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("value"), value)
+			if hit {
+				lock.Lock()
+				hits++
+				lock.Unlock()
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(start)
+	wg.Wait()
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, 9, hits)
+
+	value, _, hit, err := c.GetOrLoad("a", nil)
+	assert.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, []byte("value"), value)
+}
+
+func TestCache_GetOrLoad_Panic(t *testing.T) {
+	c := NewCache(time.Minute, 1024)
+	start := make(chan struct{})
+	errCh := make(chan error)
+	go func() {
+		defer func() {
+			_ = recover()
+		}()
+		_, _, _, _ = c.GetOrLoad("a", func() ([]byte, error) {
+			<-start
+			panic("err")
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		_, _, _, err := c.GetOrLoad("a", func() ([]byte, error) {
+			return []byte("value"), nil
+		})
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(start)
+	assert.Equal(t, errLoadPanic, <-errCh)
+	_, _, ok := c.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,151 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datemath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	now = "now"
+	// dateTimeFormat represents the format of absolute time which is parsed in location.
+	dateTimeFormat = "2006-01-02 15:04:05"
+)
+
+// operationPattern matches the operation of relative time, like -6h, +1d or /w.
+var operationPattern = regexp.MustCompile(`^(?:([+-])(\d+)|/)(ms|s|m|h|d|w|M|y)`)
+
+// Parse parses time expression to epoch milliseconds, supports:
+//
+//	epoch milliseconds: 1680000000000
+//	absolute time:      2023-03-28 10:00:00(in location) or RFC3339
+//	relative time:      now, now-6h, now/d(start of today), now-1w/w(start of last week)
+//
+// Units are ms/s/m/h/d/w/M/y, week starts on Monday. If roundUp is true, time is rounded up to
+// the end of unit(used by the end of time range), for example now/d means the end of today.
+// Empty expression returns 0.
+func Parse(expr string, current time.Time, loc *time.Location, roundUp bool) (int64, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, nil
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	if ts, err := strconv.ParseInt(expr, 10, 64); err == nil {
+		return ts, nil
+	}
+	if !strings.HasPrefix(expr, now) {
+		if t, err := time.ParseInLocation(dateTimeFormat, expr, loc); err == nil {
+			return t.UnixMilli(), nil
+		}
+		if t, err := time.Parse(time.RFC3339, expr); err == nil {
+			return t.UnixMilli(), nil
+		}
+		return 0, fmt.Errorf("invalid time expression: %s", expr)
+	}
+	t := current.In(loc)
+	operations := expr[len(now):]
+	for operations != "" {
+		matches := operationPattern.FindStringSubmatch(operations)
+		if matches == nil {
+			return 0, fmt.Errorf("invalid time expression: %s", expr)
+		}
+		operations = operations[len(matches[0]):]
+		unit := matches[3]
+		if matches[1] == "" {
+			t = roundTime(t, unit, roundUp)
+			continue
+		}
+		n, err := strconv.Atoi(matches[2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid time expression: %s", expr)
+		}
+		if matches[1] == "-" {
+			n = -n
+		}
+		t = addTime(t, unit, n)
+	}
+	return t.UnixMilli(), nil
+}
+
+// addTime adds n units to time, day/week/month/year are added by calendar of location.
+func addTime(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "ms":
+		return t.Add(time.Duration(n) * time.Millisecond)
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "M":
+		return addMonths(t, n)
+	default:
+		return addMonths(t, 12*n)
+	}
+}
+
+// addMonths adds n months to time, the day is clamped to the last day of month(like 03-31 minus 1 month is 02-28).
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	firstDay := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if lastDay := firstDay.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return firstDay.AddDate(0, 0, day-1)
+}
+
+// roundTime rounds time down to the start of unit, or up to the end of unit if roundUp is true.
+func roundTime(t time.Time, unit string, roundUp bool) time.Time {
+	var start time.Time
+	year, month, day := t.Date()
+	switch unit {
+	case "ms":
+		return t.Truncate(time.Millisecond)
+	case "s":
+		start = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	case "m":
+		start = time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, t.Location())
+	case "h":
+		start = time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case "d":
+		start = time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	case "w":
+		// week starts on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		start = time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case "M":
+		start = time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	if !roundUp {
+		return start
+	}
+	return addTime(start, unit, 1).Add(-time.Millisecond)
+}
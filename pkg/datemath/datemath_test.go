@@ -0,0 +1,82 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datemath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	// Thursday
+	current := time.Date(2023, time.March, 30, 10, 20, 30, 400*int(time.Millisecond), time.UTC)
+	at := func(loc *time.Location, year int, month time.Month, day, hour, minute, sec, ms int) int64 {
+		return time.Date(year, month, day, hour, minute, sec, ms*int(time.Millisecond), loc).UnixMilli()
+	}
+	cases := []struct {
+		expr    string
+		loc     *time.Location
+		roundUp bool
+		expect  int64
+	}{
+		{expr: "", expect: 0},
+		{expr: "1680000000000", expect: 1680000000000},
+		{expr: "2023-03-28 10:00:00", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 28, 10, 0, 0, 0)},
+		{expr: "2023-03-28 10:00:00", loc: shanghai, expect: at(shanghai, 2023, time.March, 28, 10, 0, 0, 0)},
+		{expr: "2023-03-28T10:00:00+08:00", loc: time.UTC, expect: at(shanghai, 2023, time.March, 28, 10, 0, 0, 0)},
+		{expr: "now", expect: current.UnixMilli()},
+		{expr: " now-6h ", expect: current.Add(-6 * time.Hour).UnixMilli()},
+		{expr: "now+1m-30s", expect: current.Add(30 * time.Second).UnixMilli()},
+		{expr: "now-500ms", expect: current.Add(-500 * time.Millisecond).UnixMilli()},
+		{expr: "now-1M", loc: time.UTC, expect: at(time.UTC, 2023, time.February, 28, 10, 20, 30, 400)},
+		{expr: "now-13M", loc: time.UTC, expect: at(time.UTC, 2022, time.February, 28, 10, 20, 30, 400)},
+		{expr: "now-1y", loc: time.UTC, expect: at(time.UTC, 2022, time.March, 30, 10, 20, 30, 400)},
+		{expr: "now/ms", expect: current.UnixMilli()},
+		{expr: "now/s", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 30, 10, 20, 30, 0)},
+		{expr: "now/s", loc: time.UTC, roundUp: true, expect: at(time.UTC, 2023, time.March, 30, 10, 20, 30, 999)},
+		{expr: "now/m", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 30, 10, 20, 0, 0)},
+		{expr: "now/h", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 30, 10, 0, 0, 0)},
+		{expr: "now/d", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 30, 0, 0, 0, 0)},
+		{expr: "now/d", loc: time.UTC, roundUp: true, expect: at(time.UTC, 2023, time.March, 30, 23, 59, 59, 999)},
+		{expr: "now/d", loc: shanghai, expect: at(shanghai, 2023, time.March, 30, 0, 0, 0, 0)},
+		{expr: "now-1d/d", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 29, 0, 0, 0, 0)},
+		{expr: "now/w", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 27, 0, 0, 0, 0)},
+		{expr: "now-1w/w", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 20, 0, 0, 0, 0)},
+		{expr: "now-1w/w", loc: time.UTC, roundUp: true, expect: at(time.UTC, 2023, time.March, 26, 23, 59, 59, 999)},
+		{expr: "now/M", loc: time.UTC, expect: at(time.UTC, 2023, time.March, 1, 0, 0, 0, 0)},
+		{expr: "now/M", loc: time.UTC, roundUp: true, expect: at(time.UTC, 2023, time.March, 31, 23, 59, 59, 999)},
+		{expr: "now/y", loc: time.UTC, expect: at(time.UTC, 2023, time.January, 1, 0, 0, 0, 0)},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			rs, err := Parse(tt.expr, current, tt.loc, tt.roundUp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, rs)
+		})
+	}
+
+	for _, expr := range []string{"yesterday", "now-", "now-6", "now-6x", "now/", "now-1h/", "now-99999999999999999999h"} {
+		_, err := Parse(expr, current, nil, false)
+		assert.Error(t, err, expr)
+	}
+}
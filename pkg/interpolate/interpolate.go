@@ -0,0 +1,271 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package interpolate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format represents how to format the values of variable.
+type Format = string
+
+// Defines all formats of variable value.
+var (
+	// Raw keeps values without escaping, joined by comma.
+	Raw Format = "raw"
+	// CSV joins values by comma.
+	CSV Format = "csv"
+	// Pipe joins values by pipe.
+	Pipe Format = "pipe"
+	// Regex escapes values for regular expression, multiple values are formatted as (a|b).
+	Regex Format = "regex"
+	// SingleQuote quotes each value by single quote, escapes backslash/single quote by backslash,
+	// joined by comma, like 'a','b'.
+	SingleQuote Format = "singlequote"
+	// SQLString quotes each value by single quote, escapes single quote by doubling(SQL standard),
+	// joined by comma, like 'a','b'.
+	SQLString Format = "sqlstring"
+	// JSON formats values as json array.
+	JSON Format = "json"
+)
+
+// names of built-in variables.
+const (
+	From       = "__from"
+	To         = "__to"
+	Interval   = "__interval"
+	IntervalMs = "__interval_ms"
+)
+
+// variablePattern matches the variable reference, like $host, ${host} or ${host:csv}.
+var variablePattern = regexp.MustCompile(`\$(?:\{\s*(\w+)\s*(?::\s*(\w+)\s*)?\}|(\w+))`)
+
+var (
+	singleQuoteReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	sqlStringReplacer   = strings.NewReplacer(`'`, `''`)
+)
+
+// variable represents the values of variable.
+type variable struct {
+	values []string
+	// builtin variable is generated by server, formatted as raw by default.
+	builtin bool
+}
+
+// Variables represents the variables which are expanded in query.
+type Variables struct {
+	variables map[string]*variable
+}
+
+// NewVariables creates variables from the values of dashboard variables,
+// value can be string, number, bool or list of them.
+func NewVariables(values map[string]any) (*Variables, error) {
+	vars := &Variables{variables: make(map[string]*variable, len(values))}
+	for name, value := range values {
+		var list []any
+		switch v := value.(type) {
+		case []any:
+			list = v
+		case []string:
+			for _, s := range v {
+				list = append(list, s)
+			}
+		default:
+			list = []any{v}
+		}
+		var values []string
+		for _, item := range list {
+			s, err := toString(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of variable '%s': %w", name, err)
+			}
+			values = append(values, s)
+		}
+		vars.variables[name] = &variable{values: values}
+	}
+	return vars, nil
+}
+
+// Clone returns a copy of variables, built-in variables can be set on it without affecting the original.
+func (vars *Variables) Clone() *Variables {
+	rs := &Variables{variables: make(map[string]*variable, len(vars.variables))}
+	for name, v := range vars.variables {
+		rs.variables[name] = v
+	}
+	return rs
+}
+
+// SetBuiltin sets built-in variable, overwrites the variable with same name.
+func (vars *Variables) SetBuiltin(name, value string) *Variables {
+	vars.variables[name] = &variable{values: []string{value}, builtin: true}
+	return vars
+}
+
+// Escaper escapes the value of variable for the context which it is embedded in(like string literal of sql).
+type Escaper func(value string) string
+
+// Interpolate expands the variables in text, the variable without format is formatted by default format
+// (built-in variable is formatted as raw). Unknown variable is kept.
+func (vars *Variables) Interpolate(text string, defaultFormat Format) (string, error) {
+	return vars.InterpolateWithEscaper(text, defaultFormat, nil)
+}
+
+// InterpolateWithEscaper expands the variables in text like Interpolate, the values formatted as
+// csv/pipe/regex are escaped by escaper, because they are always embedded in quoted string.
+func (vars *Variables) InterpolateWithEscaper(text string, defaultFormat Format, escaper Escaper) (string, error) {
+	var err error
+	rs := variablePattern.ReplaceAllStringFunc(text, func(s string) string {
+		if err != nil {
+			return s
+		}
+		match := variablePattern.FindStringSubmatch(s)
+		name, format := match[1]+match[3], match[2]
+		v, ok := vars.variables[name]
+		if !ok {
+			return s
+		}
+		if format == "" {
+			format = defaultFormat
+			if v.builtin {
+				format = Raw
+			}
+		}
+		var formatted string
+		formatted, err = formatValues(v.values, format, escaper)
+		return formatted
+	})
+	if err != nil {
+		return "", err
+	}
+	return rs, nil
+}
+
+// InterpolateJSON expands the variables in all string values of json.
+func (vars *Variables) InterpolateJSON(data json.RawMessage, defaultFormat Format) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	value, err := vars.interpolateValue(value, defaultFormat)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// interpolateValue expands the variables in string values of json value recursively.
+func (vars *Variables) interpolateValue(value any, defaultFormat Format) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return vars.Interpolate(v, defaultFormat)
+	case []any:
+		for idx, item := range v {
+			rs, err := vars.interpolateValue(item, defaultFormat)
+			if err != nil {
+				return nil, err
+			}
+			v[idx] = rs
+		}
+	case map[string]any:
+		for key, item := range v {
+			rs, err := vars.interpolateValue(item, defaultFormat)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rs
+		}
+	}
+	return value, nil
+}
+
+// formatValues formats the values of variable.
+func formatValues(values []string, format Format, escaper Escaper) (string, error) {
+	escape := func(values []string) []string {
+		if escaper == nil {
+			return values
+		}
+		escaped := make([]string, 0, len(values))
+		for _, v := range values {
+			escaped = append(escaped, escaper(v))
+		}
+		return escaped
+	}
+	switch format {
+	case Raw:
+		return strings.Join(values, ","), nil
+	case CSV:
+		return strings.Join(escape(values), ","), nil
+	case Pipe:
+		return strings.Join(escape(values), "|"), nil
+	case Regex:
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, regexp.QuoteMeta(v))
+		}
+		quoted = escape(quoted)
+		if len(quoted) == 1 {
+			return quoted[0], nil
+		}
+		return "(" + strings.Join(quoted, "|") + ")", nil
+	case SingleQuote:
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, "'"+singleQuoteReplacer.Replace(v)+"'")
+		}
+		return strings.Join(quoted, ","), nil
+	case SQLString:
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, "'"+sqlStringReplacer.Replace(v)+"'")
+		}
+		return strings.Join(quoted, ","), nil
+	case JSON:
+		if values == nil {
+			values = []string{}
+		}
+		data, _ := json.Marshal(values)
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("format of variable not support: %s", format)
+	}
+}
+
+// toString converts the value of variable to string.
+func toString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value type: %T", value)
+	}
+}
@@ -0,0 +1,156 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package interpolate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVariables(t *testing.T) {
+	vars, err := NewVariables(map[string]any{
+		"host":  []any{"a", "b"},
+		"ip":    []string{"1.1.1.1"},
+		"num":   float64(10),
+		"int":   5,
+		"int64": int64(6),
+		"flag":  true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, vars.variables["host"].values)
+	assert.Equal(t, []string{"1.1.1.1"}, vars.variables["ip"].values)
+	assert.Equal(t, []string{"10"}, vars.variables["num"].values)
+	assert.Equal(t, []string{"5"}, vars.variables["int"].values)
+	assert.Equal(t, []string{"6"}, vars.variables["int64"].values)
+	assert.Equal(t, []string{"true"}, vars.variables["flag"].values)
+
+	vars, err = NewVariables(map[string]any{"host": map[string]any{"a": "b"}})
+	assert.Error(t, err)
+	assert.Nil(t, vars)
+	vars, err = NewVariables(map[string]any{"host": []any{"a", nil}})
+	assert.Error(t, err)
+	assert.Nil(t, vars)
+}
+
+func TestVariables_Interpolate(t *testing.T) {
+	vars, err := NewVariables(map[string]any{
+		"host":   []any{"a", "b"},
+		"single": "it's",
+		"path":   `a\b`,
+		"dot":    "1.1",
+		"empty":  []any{},
+	})
+	assert.NoError(t, err)
+	vars.SetBuiltin(From, "1000").SetBuiltin(Interval, "10s")
+
+	cases := []struct {
+		name   string
+		text   string
+		format Format
+		expect string
+		err    bool
+	}{
+		{name: "no variables", text: "select f from cpu", format: SingleQuote, expect: "select f from cpu"},
+		{name: "unknown variable", text: "where host=$unknown and ip=${ip}", format: SingleQuote, expect: "where host=$unknown and ip=${ip}"},
+		{name: "default format", text: "where host in ($host)", format: SingleQuote, expect: "where host in ('a','b')"},
+		{name: "braces", text: "where host in (${ host })", format: SingleQuote, expect: "where host in ('a','b')"},
+		{name: "csv", text: "${host:csv}", format: SingleQuote, expect: "a,b"},
+		{name: "raw", text: "${host:raw}", format: SingleQuote, expect: "a,b"},
+		{name: "pipe", text: "${host:pipe}", format: SingleQuote, expect: "a|b"},
+		{name: "regex multi values", text: "${host:regex}", format: SingleQuote, expect: "(a|b)"},
+		{name: "regex single value", text: "${dot:regex}", format: SingleQuote, expect: `1\.1`},
+		{name: "json", text: "${host:json}", format: SingleQuote, expect: `["a","b"]`},
+		{name: "json empty", text: "${empty:json}", format: SingleQuote, expect: `[]`},
+		{name: "single quote escape", text: "$single", format: SingleQuote, expect: `'it\'s'`},
+		{name: "single quote escape backslash", text: "$path", format: SingleQuote, expect: `'a\\b'`},
+		{name: "sql string escape", text: "$single", format: SQLString, expect: `'it''s'`},
+		{name: "built-in variables are raw", text: "time > $__from and time($__interval)", format: SingleQuote, expect: "time > 1000 and time(10s)"},
+		{name: "built-in variable with format", text: "${__interval:singlequote}", format: CSV, expect: "'10s'"},
+		{name: "unknown format", text: "${host:unknown}", format: CSV, err: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := vars.Interpolate(tt.text, tt.format)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, rs)
+		})
+	}
+}
+
+func TestVariables_InterpolateWithEscaper(t *testing.T) {
+	vars, err := NewVariables(map[string]any{"host": []any{"a.b", "it's"}})
+	assert.NoError(t, err)
+	escaper := func(value string) string {
+		return strings.ReplaceAll(value, "'", "''")
+	}
+	for text, expect := range map[string]string{
+		"$host":           `'a.b','it\'s'`,
+		"'${host:csv}'":   "'a.b,it''s'",
+		"'${host:pipe}'":  "'a.b|it''s'",
+		"'${host:regex}'": `'(a\.b|it''s)'`,
+		"${host:raw}":     "a.b,it's",
+	} {
+		rs, err := vars.InterpolateWithEscaper(text, SingleQuote, escaper)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, rs, text)
+	}
+}
+
+func TestVariables_Clone(t *testing.T) {
+	vars, err := NewVariables(map[string]any{"host": "a"})
+	assert.NoError(t, err)
+	vars.SetBuiltin(Interval, "10s")
+	cloned := vars.Clone().SetBuiltin(Interval, "1m")
+	rs, err := vars.Interpolate("$host/$__interval", CSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "a/10s", rs)
+	rs, err = cloned.Interpolate("$host/$__interval", CSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "a/1m", rs)
+}
+
+func TestVariables_InterpolateJSON(t *testing.T) {
+	vars, err := NewVariables(map[string]any{"host": []any{"a", "b"}})
+	assert.NoError(t, err)
+
+	rs, err := vars.InterpolateJSON(nil, CSV)
+	assert.NoError(t, err)
+	assert.Nil(t, rs)
+
+	rs, err = vars.InterpolateJSON(json.RawMessage(`{"metric":"cpu","where":[{"key":"host","value":"$host"}],"tags":["${host:pipe}"],"limit":10}`), CSV)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"metric":"cpu","where":[{"key":"host","value":"a,b"}],"tags":["a|b"],"limit":10}`, string(rs))
+
+	rs, err = vars.InterpolateJSON(json.RawMessage(`{"tags":["${host:unknown}"]}`), CSV)
+	assert.Error(t, err)
+	assert.Nil(t, rs)
+	rs, err = vars.InterpolateJSON(json.RawMessage(`{"where":{"value":"${host:unknown}"}}`), CSV)
+	assert.Error(t, err)
+	assert.Nil(t, rs)
+	rs, err = vars.InterpolateJSON(json.RawMessage(`{`), CSV)
+	assert.Error(t, err)
+	assert.Nil(t, rs)
+}
@@ -0,0 +1,113 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package interval
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxDataPoints represents the default max number of data points in time range.
+const DefaultMaxDataPoints = 1000
+
+var (
+	day = 24 * time.Hour.Milliseconds()
+	// readableIntervals represents the readable intervals(milliseconds) which calculated interval rounded up to.
+	readableIntervals = []int64{
+		time.Second.Milliseconds(),
+		5 * time.Second.Milliseconds(),
+		10 * time.Second.Milliseconds(),
+		15 * time.Second.Milliseconds(),
+		30 * time.Second.Milliseconds(),
+		time.Minute.Milliseconds(),
+		5 * time.Minute.Milliseconds(),
+		10 * time.Minute.Milliseconds(),
+		15 * time.Minute.Milliseconds(),
+		30 * time.Minute.Milliseconds(),
+		time.Hour.Milliseconds(),
+		3 * time.Hour.Milliseconds(),
+		6 * time.Hour.Milliseconds(),
+		12 * time.Hour.Milliseconds(),
+		day,
+	}
+	units = []struct {
+		unit string
+		ms   int64
+	}{
+		{unit: "d", ms: day},
+		{unit: "h", ms: time.Hour.Milliseconds()},
+		{unit: "m", ms: time.Minute.Milliseconds()},
+		{unit: "s", ms: time.Second.Milliseconds()},
+		{unit: "ms", ms: 1},
+	}
+	intervalPattern = regexp.MustCompile(`^(\d+)(ms|s|m|h|d|w)$`)
+)
+
+// Calculate calculates the interval(milliseconds) of data points by the span of time range(milliseconds),
+// makes sure the number of points not greater than max data points(use default if <= 0), the interval is
+// rounded up to readable interval(at least 1s) and not less than min interval.
+func Calculate(span, maxDataPoints, minInterval int64) int64 {
+	if maxDataPoints <= 0 {
+		maxDataPoints = DefaultMaxDataPoints
+	}
+	interval := (span + maxDataPoints - 1) / maxDataPoints
+	rs := (interval + day - 1) / day * day
+	for _, readable := range readableIntervals {
+		if interval <= readable {
+			rs = readable
+			break
+		}
+	}
+	if rs < minInterval {
+		rs = minInterval
+	}
+	return rs
+}
+
+// Format formats interval(milliseconds) by the largest unit which divides it, like 10s/1m/1d.
+func Format(interval int64) string {
+	for _, u := range units {
+		if interval > 0 && interval%u.ms == 0 {
+			return fmt.Sprintf("%d%s", interval/u.ms, u.unit)
+		}
+	}
+	return fmt.Sprintf("%dms", interval)
+}
+
+// Parse parses interval like 500ms/10s/1m/1h/1d/1w, returns milliseconds.
+func Parse(interval string) (int64, error) {
+	matches := intervalPattern.FindStringSubmatch(interval)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid interval: %s", interval)
+	}
+	n, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval: %s", interval)
+	}
+	if matches[2] == "w" {
+		return n * 7 * day, nil
+	}
+	for _, u := range units {
+		if u.unit == matches[2] {
+			return n * u.ms, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid interval: %s", interval)
+}
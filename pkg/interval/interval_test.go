@@ -0,0 +1,82 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package interval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate(t *testing.T) {
+	hour := time.Hour.Milliseconds()
+	cases := []struct {
+		name          string
+		span          int64
+		maxDataPoints int64
+		minInterval   int64
+		expect        int64
+	}{
+		{name: "min interval is 1s", span: 60 * 1000, expect: 1000},
+		{name: "empty time range", span: 0, expect: 1000},
+		{name: "1 hour", span: hour, expect: 5000},
+		{name: "3 days", span: 72 * hour, expect: 5 * 60 * 1000},
+		{name: "30 days", span: 30 * 24 * hour, expect: hour},
+		{name: "greater than 1 day", span: 1500 * 24 * hour, expect: 2 * day},
+		{name: "multiple of 1 day", span: 2000 * 24 * hour, expect: 2 * day},
+		{name: "max data points", span: hour, maxDataPoints: 100, expect: 60 * 1000},
+		{name: "min interval", span: hour, minInterval: 60 * 1000, expect: 60 * 1000},
+		{name: "min interval less than calculated", span: hour, minInterval: 1000, expect: 5000},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Calculate(tt.span, tt.maxDataPoints, tt.minInterval))
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "500ms", Format(500))
+	assert.Equal(t, "0ms", Format(0))
+	assert.Equal(t, "10s", Format(10000))
+	assert.Equal(t, "90s", Format(90000))
+	assert.Equal(t, "5m", Format(5*60*1000))
+	assert.Equal(t, "3h", Format(3*time.Hour.Milliseconds()))
+	assert.Equal(t, "2d", Format(2*day))
+}
+
+func TestParse(t *testing.T) {
+	for interval, expect := range map[string]int64{
+		"500ms": 500,
+		"10s":   10000,
+		"5m":    5 * 60 * 1000,
+		"1h":    time.Hour.Milliseconds(),
+		"2d":    2 * day,
+		"1w":    7 * day,
+	} {
+		rs, err := Parse(interval)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, rs)
+	}
+	for _, interval := range []string{"", "1", "1y", "-1s", "1.5s", "99999999999999999999s"} {
+		_, err := Parse(interval)
+		assert.Error(t, err, interval)
+	}
+}
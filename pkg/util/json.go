@@ -0,0 +1,98 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MergeJSON merges patch json object into base json object recursively, null value of patch deletes the key.
+func MergeJSON(base, patch []byte) ([]byte, error) {
+	if len(patch) == 0 {
+		return base, nil
+	}
+	baseObj := map[string]any{}
+	if len(base) > 0 {
+		if err := json.Unmarshal(base, &baseObj); err != nil {
+			return nil, fmt.Errorf("base is not json object: %w", err)
+		}
+		if baseObj == nil {
+			// base is null
+			baseObj = map[string]any{}
+		}
+	}
+	patchObj := map[string]any{}
+	if err := json.Unmarshal(patch, &patchObj); err != nil {
+		return nil, fmt.Errorf("patch is not json object: %w", err)
+	}
+	return json.Marshal(mergeObject(baseObj, patchObj))
+}
+
+// JSONLeafPaths returns the sorted paths(keys joined by dot, e.g. http.bearerToken) of all leaf values
+// in json object.
+func JSONLeafPaths(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	obj := map[string]any{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	var paths []string
+	collectLeafPaths("", obj, &paths)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// mergeObject merges patch into base recursively.
+func mergeObject(base, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		if value == nil {
+			delete(base, key)
+			continue
+		}
+		patchChild, ok := value.(map[string]any)
+		if !ok {
+			base[key] = value
+			continue
+		}
+		baseChild, ok := base[key].(map[string]any)
+		if !ok {
+			baseChild = map[string]any{}
+		}
+		base[key] = mergeObject(baseChild, patchChild)
+	}
+	return base
+}
+
+// collectLeafPaths collects the paths of leaf values.
+func collectLeafPaths(prefix string, obj map[string]any, paths *[]string) {
+	for key, value := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if child, ok := value.(map[string]any); ok {
+			collectLeafPaths(path, child, paths)
+			continue
+		}
+		*paths = append(*paths, path)
+	}
+}
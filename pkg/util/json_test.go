@@ -0,0 +1,71 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON_MergeJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    string
+		patch   string
+		rs      string
+		wantErr bool
+	}{
+		{name: "empty patch", base: `{"a":1}`, rs: `{"a":1}`},
+		{name: "empty base", patch: `{"a":1}`, rs: `{"a":1}`},
+		{name: "null base", base: `null`, patch: `{"a":1}`, rs: `{"a":1}`},
+		{name: "invalid base", base: `[]`, patch: `{"a":1}`, wantErr: true},
+		{name: "invalid patch", base: `{}`, patch: `1`, wantErr: true},
+		{
+			name:  "merge recursively",
+			base:  `{"url":"a","http":{"timeout":"1s","basicAuth":{"user":"admin"}},"tls":{"caCert":"b"}}`,
+			patch: `{"http":{"basicAuth":{"password":"pwd"}},"tls":null,"database":{"name":"c"}}`,
+			rs: `{"url":"a","http":{"timeout":"1s","basicAuth":{"user":"admin","password":"pwd"}},` +
+				`"database":{"name":"c"}}`,
+		},
+		{name: "replace non object", base: `{"a":1}`, patch: `{"a":{"b":1}}`, rs: `{"a":{"b":1}}`},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := MergeJSON([]byte(tt.base), []byte(tt.patch))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.rs, string(rs))
+		})
+	}
+}
+
+func TestJSON_JSONLeafPaths(t *testing.T) {
+	paths, err := JSONLeafPaths(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+	_, err = JSONLeafPaths([]byte(`[]`))
+	assert.Error(t, err)
+	paths, err = JSONLeafPaths([]byte(`{"http":{"bearerToken":"a","basicAuth":{"password":"b"},"tls":{}},"key":"c"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http.basicAuth.password", "http.bearerToken", "key"}, paths)
+}
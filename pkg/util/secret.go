@@ -0,0 +1,76 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// for testing
+var (
+	randReader = rand.Reader
+)
+
+// Encrypt encrypts data by AES-GCM with the key derived from secret key, the nonce is prepended to result.
+func Encrypt(data []byte, secretKey string) ([]byte, error) {
+	gcm, err := newGCM(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt decrypts data which is encrypted by Encrypt with same secret key.
+func Decrypt(data []byte, secretKey string) ([]byte, error) {
+	gcm, err := newGCM(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("invalid encrypted data")
+	}
+	rs, err := gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt data failure, maybe secret key changed: %w", err)
+	}
+	return rs, nil
+}
+
+// newGCM creates AES-256 GCM cipher with the key derived from secret key.
+func newGCM(secretKey string) (cipher.AEAD, error) {
+	if secretKey == "" {
+		return nil, errors.New("secret key is not configured")
+	}
+	key := sha256.Sum256([]byte(secretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
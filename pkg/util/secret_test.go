@@ -0,0 +1,55 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret_EncryptDecrypt(t *testing.T) {
+	defer func() {
+		randReader = rand.Reader
+	}()
+	data, err := Encrypt([]byte("password"), "key")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "password")
+
+	rs, err := Decrypt(data, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "password", string(rs))
+
+	// secret key changed
+	_, err = Decrypt(data, "new-key")
+	assert.Error(t, err)
+	// secret key not configured
+	_, err = Encrypt([]byte("password"), "")
+	assert.Error(t, err)
+	_, err = Decrypt(data, "")
+	assert.Error(t, err)
+	// invalid data
+	_, err = Decrypt([]byte("a"), "key")
+	assert.Error(t, err)
+	// generate nonce failure
+	randReader = bytes.NewReader(nil)
+	_, err = Encrypt([]byte("password"), "key")
+	assert.Error(t, err)
+}
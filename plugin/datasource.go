@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
 )
 
 //go:generate mockgen -source=./datasource.go -destination=./datasource_mock.go -package=plugin
@@ -31,8 +32,28 @@ type NewDatasourcePlugin func(datasource *model.Datasource, cfg json.RawMessage)
 
 // DatasourcePlugin represents datasource plugin.
 type DatasourcePlugin interface {
-	// DataQuery queries data.
-	DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (any, error)
+	// DataQuery queries data, returns the data as frames.
+	DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error)
 	// MetadataQuery queries metadata.
 	MetadataQuery(ctx context.Context, req *model.Query) (any, error)
+	// CheckHealth checks if datasource is working, returns error if not.
+	CheckHealth(ctx context.Context) error
+	// Close releases the resources(connections etc.) held by datasource plugin.
+	Close() error
+}
+
+// VariableInterpolator represents the datasource plugin which expands variables of query request by itself,
+// if plugin doesn't implement it, variables in all string values of request are expanded as csv format.
+type VariableInterpolator interface {
+	// InterpolateVariables expands the variables of query request, returns new request.
+	InterpolateVariables(req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error)
+}
+
+// InterpolateVariables expands the variables of query request by datasource plugin if it implements
+// VariableInterpolator, otherwise expands variables in all string values of request as csv format.
+func InterpolateVariables(cli DatasourcePlugin, req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error) {
+	if interpolator, ok := cli.(VariableInterpolator); ok {
+		return interpolator.InterpolateVariables(req, vars)
+	}
+	return vars.InterpolateJSON(req, interpolate.CSV)
 }
@@ -0,0 +1,499 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+const (
+	defaultTimeField = "@timestamp"
+	defaultSize      = 100
+	defaultTermsSize = 10
+	// maxBuckets represents the max buckets of date histogram when interval not set.
+	maxBuckets  = 300
+	aggregation = "agg"
+	metric      = "metric"
+)
+
+// ProxyRoutes represents the Elasticsearch/OpenSearch apis which datasource proxy is allowed to forward,
+// the mapping/search apis are only allowed on the configured index of datasource.
+var ProxyRoutes = append(
+	plugin.ReadOnlyProxyRoutes("/_cat/indices", "/_cat/indices/*", "/_cluster/health"),
+	plugin.ProxyRoute{Method: http.MethodGet, Path: "/*/_mapping", Check: checkIndex},
+	plugin.ProxyRoute{Method: http.MethodHead, Path: "/*/_mapping", Check: checkIndex},
+	plugin.ProxyRoute{Method: http.MethodPost, Path: "/*/_search", Check: checkIndex},
+)
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+	newRequestFn    = http.NewRequestWithContext
+	readAllFn       = io.ReadAll
+)
+
+// client implements plugin.DatasourcePlugin for Elasticsearch/OpenSearch.
+type client struct {
+	datasouce *model.Datasource
+	cfg       *DatasourceConfig
+	httpCli   *http.Client
+
+	logger logger.Logger
+}
+
+// NewClient creates an Elasticsearch/OpenSearch client.
+func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	config := &DatasourceConfig{}
+	cfgData, _ := cfg.MarshalJSON()
+	if err0 := jsonUnmarshalFn(cfgData, config); err0 != nil {
+		return nil, err0
+	}
+	if config.TimeField == "" {
+		config.TimeField = defaultTimeField
+	}
+
+	httpCli, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		cfg:       config,
+		datasouce: datasource,
+		httpCli:   httpCli,
+		logger:    logger.GetLogger("DatasourcePlugin", "Elasticsearch"),
+	}, nil
+}
+
+// DataQuery searches raw documents or aggregates by date histogram/terms.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	dataQueryReq := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &dataQueryReq); err != nil {
+		return nil, err
+	}
+	index := dataQueryReq.Index
+	if index == "" {
+		index = cli.cfg.Index
+	}
+	if index == "" {
+		return nil, fmt.Errorf("index is required")
+	}
+	switch dataQueryReq.Metric {
+	case "", Count:
+	case Avg, Sum, Min, Max:
+		if dataQueryReq.MetricField == "" {
+			return nil, fmt.Errorf("metric field is required for metric: %s", dataQueryReq.Metric)
+		}
+	default:
+		return nil, fmt.Errorf("metric not support, metric: %s", dataQueryReq.Metric)
+	}
+	body := map[string]any{
+		"query": cli.buildQuery(dataQueryReq.Query, timeRange),
+	}
+	var interval time.Duration
+	switch dataQueryReq.Type {
+	case Raw:
+		size := dataQueryReq.Size
+		if size <= 0 {
+			size = defaultSize
+		}
+		order := "desc"
+		if dataQueryReq.Sort == "asc" {
+			order = "asc"
+		}
+		body["size"] = size
+		body["sort"] = []any{map[string]any{cli.cfg.TimeField: map[string]any{"order": order}}}
+		body["track_total_hits"] = true
+	case DateHistogram:
+		var err error
+		interval, err = getInterval(dataQueryReq.Interval, timeRange)
+		if err != nil {
+			return nil, err
+		}
+		body["size"] = 0
+		body["aggs"] = map[string]any{
+			aggregation: withMetric(map[string]any{
+				"date_histogram": map[string]any{
+					"field":          cli.cfg.TimeField,
+					"fixed_interval": fmt.Sprintf("%dms", interval.Milliseconds()),
+					"min_doc_count":  0,
+					"extended_bounds": map[string]any{
+						"min": timeRange.From,
+						"max": timeRange.To,
+					},
+					"format": "epoch_millis",
+				},
+			}, dataQueryReq),
+		}
+	case Terms:
+		if dataQueryReq.Field == "" {
+			return nil, fmt.Errorf("field is required for terms aggregation")
+		}
+		size := dataQueryReq.TermsSize
+		if size <= 0 {
+			size = defaultTermsSize
+		}
+		body["size"] = 0
+		body["aggs"] = map[string]any{
+			aggregation: withMetric(map[string]any{
+				"terms": map[string]any{
+					"field": dataQueryReq.Field,
+					"size":  size,
+				},
+			}, dataQueryReq),
+		}
+	default:
+		return nil, fmt.Errorf("query type not support, type: %s", dataQueryReq.Type)
+	}
+	resp := &searchResponse{}
+	if err := cli.search(ctx, index, body, resp); err != nil {
+		return nil, err
+	}
+	cli.logger.Info("data query", logger.String("index", index), logger.String("query", dataQueryReq.Query))
+	if dataQueryReq.Type == Raw {
+		rs, err := cli.toRawData(resp)
+		if err != nil {
+			return nil, err
+		}
+		return model.Frames{rs.toFrame(index)}, nil
+	}
+	rs := &AggregationData{Interval: interval.Milliseconds()}
+	for _, bucket := range resp.Aggregations[aggregation].Buckets {
+		b, err := toBucket(bucket, dataQueryReq.Type == DateHistogram)
+		if err != nil {
+			return nil, err
+		}
+		rs.Buckets = append(rs.Buckets, b)
+	}
+	withValue := dataQueryReq.Metric != "" && dataQueryReq.Metric != Count
+	return model.Frames{rs.toFrame(index, dataQueryReq.Type == DateHistogram, withValue)}, nil
+}
+
+// MetadataQuery queries index list or fields of index.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
+		return nil, err
+	}
+	switch metadataQueryReq.Type {
+	case Indices:
+		var indices []struct {
+			Index string `json:"index"`
+		}
+		if err := cli.do(ctx, http.MethodGet, "/_cat/indices?format=json&h=index", nil, &indices); err != nil {
+			return nil, err
+		}
+		rs := make([]string, 0, len(indices))
+		for _, index := range indices {
+			rs = append(rs, index.Index)
+		}
+		sort.Strings(rs)
+		return rs, nil
+	case Fields:
+		index := metadataQueryReq.Index
+		if index == "" {
+			index = cli.cfg.Index
+		}
+		if index == "" {
+			return nil, fmt.Errorf("index is required")
+		}
+		var mappings map[string]struct {
+			Mappings struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"mappings"`
+		}
+		if err := cli.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_mapping", nil, &mappings); err != nil {
+			return nil, err
+		}
+		fields := make(map[string]string)
+		for _, mapping := range mappings {
+			if err := flattenProperties("", mapping.Mappings.Properties, fields); err != nil {
+				return nil, err
+			}
+		}
+		rs := make([]FieldInfo, 0, len(fields))
+		for name, fieldType := range fields {
+			rs = append(rs, FieldInfo{Name: name, Type: fieldType})
+		}
+		sort.Slice(rs, func(i, j int) bool {
+			return rs[i].Name < rs[j].Name
+		})
+		return rs, nil
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+}
+
+// CheckHealth checks if cluster is available, and default index exists if set.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	health := struct {
+		Status string `json:"status"`
+	}{}
+	if err := cli.do(ctx, http.MethodGet, "/_cluster/health", nil, &health); err != nil {
+		return err
+	}
+	if health.Status == "red" {
+		return fmt.Errorf("cluster health status is red")
+	}
+	if cli.cfg.Index == "" {
+		return nil
+	}
+	var indices []map[string]any
+	if err := cli.do(ctx, http.MethodGet, "/_cat/indices/"+url.PathEscape(cli.cfg.Index)+"?format=json&h=index", nil, &indices); err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		return fmt.Errorf("index not found: %s", cli.cfg.Index)
+	}
+	return nil
+}
+
+// Close closes the idle connections of http client.
+func (cli *client) Close() error {
+	cli.httpCli.CloseIdleConnections()
+	return nil
+}
+
+// buildQuery builds bool query with time range filter and query string.
+func (cli *client) buildQuery(query string, timeRange model.TimeRange) map[string]any {
+	filters := []any{
+		map[string]any{
+			"range": map[string]any{
+				cli.cfg.TimeField: map[string]any{
+					"gte":    timeRange.From,
+					"lte":    timeRange.To,
+					"format": "epoch_millis",
+				},
+			},
+		},
+	}
+	if strings.TrimSpace(query) != "" {
+		filters = append(filters, map[string]any{
+			"query_string": map[string]any{
+				"query":            query,
+				"analyze_wildcard": true,
+			},
+		})
+	}
+	return map[string]any{
+		"bool": map[string]any{
+			"filter": filters,
+		},
+	}
+}
+
+// search sends search request to index.
+func (cli *client) search(ctx context.Context, index string, body map[string]any, rs *searchResponse) error {
+	return cli.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", encoding.JSONMarshal(body), rs)
+}
+
+// do sends request to Elasticsearch/OpenSearch, then unmarshals the response.
+func (cli *client) do(ctx context.Context, method, path string, body []byte, rs any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := newRequestFn(ctx, method, strings.TrimSuffix(cli.datasouce.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := cli.httpCli.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := readAllFn(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		errResp := &errorResponse{}
+		if err := jsonUnmarshalFn(data, errResp); err != nil || errResp.Error.Reason == "" {
+			return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(data))
+		}
+		return fmt.Errorf("%s: %s", errResp.Error.Type, errResp.Error.Reason)
+	}
+	return jsonUnmarshalFn(data, rs)
+}
+
+// toRawData converts search hits to documents.
+func (cli *client) toRawData(resp *searchResponse) (*RawData, error) {
+	rs := &RawData{Documents: make([]*Document, 0, len(resp.Hits.Hits))}
+	if len(resp.Hits.Total) > 0 {
+		total := struct {
+			Value int64 `json:"value"`
+		}{}
+		if err := jsonUnmarshalFn(resp.Hits.Total, &total); err != nil {
+			// ES 6 returns total as number
+			if err := jsonUnmarshalFn(resp.Hits.Total, &total.Value); err != nil {
+				return nil, err
+			}
+		}
+		rs.Total = total.Value
+	}
+	for _, hit := range resp.Hits.Hits {
+		rs.Documents = append(rs.Documents, &Document{
+			ID:        hit.ID,
+			Index:     hit.Index,
+			Timestamp: toTimestamp(hit.Source[cli.cfg.TimeField]),
+			Source:    hit.Source,
+		})
+	}
+	return rs, nil
+}
+
+// withMetric adds metric aggregation as sub aggregation of bucket aggregation.
+func withMetric(agg map[string]any, req *DataQueryRequest) map[string]any {
+	if req.Metric == "" || req.Metric == Count {
+		return agg
+	}
+	agg["aggs"] = map[string]any{
+		metric: map[string]any{
+			req.Metric: map[string]any{"field": req.MetricField},
+		},
+	}
+	return agg
+}
+
+// toBucket converts aggregation bucket, the key of date histogram is timestamp.
+func toBucket(bucket map[string]json.RawMessage, isHistogram bool) (*Bucket, error) {
+	rs := &Bucket{}
+	if err := jsonUnmarshalFn(bucket["doc_count"], &rs.Count); err != nil {
+		return nil, err
+	}
+	if isHistogram {
+		var key int64
+		if err := jsonUnmarshalFn(bucket["key"], &key); err != nil {
+			return nil, err
+		}
+		rs.Key = key
+	} else {
+		var key any
+		if err := jsonUnmarshalFn(bucket["key"], &key); err != nil {
+			return nil, err
+		}
+		rs.Key = key
+	}
+	if m, ok := bucket[metric]; ok {
+		value := struct {
+			Value *float64 `json:"value"`
+		}{}
+		if err := jsonUnmarshalFn(m, &value); err != nil {
+			return nil, err
+		}
+		rs.Value = value.Value
+	} else {
+		count := float64(rs.Count)
+		rs.Value = &count
+	}
+	return rs, nil
+}
+
+// flattenProperties flattens index mapping properties, nested field name joined by dot.
+func flattenProperties(prefix string, properties map[string]json.RawMessage, fields map[string]string) error {
+	for name, data := range properties {
+		property := struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+			Fields     map[string]json.RawMessage `json:"fields"`
+		}{}
+		if err := jsonUnmarshalFn(data, &property); err != nil {
+			return err
+		}
+		fieldName := prefix + name
+		if len(property.Properties) > 0 {
+			if err := flattenProperties(fieldName+".", property.Properties, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		fields[fieldName] = property.Type
+		// multi-fields, e.g. message.keyword
+		if err := flattenProperties(fieldName+".", property.Fields, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getInterval returns the interval of date histogram, if not set calculates it based on time range.
+func getInterval(interval string, timeRange model.TimeRange) (time.Duration, error) {
+	if interval != "" {
+		rs, err := time.ParseDuration(interval)
+		if err != nil || rs < time.Millisecond {
+			return 0, fmt.Errorf("invalid interval: %s", interval)
+		}
+		return rs, nil
+	}
+	rs := (time.Duration(timeRange.To-timeRange.From) * time.Millisecond / maxBuckets).Truncate(time.Second)
+	if rs < time.Second {
+		return time.Second, nil
+	}
+	return rs, nil
+}
+
+// toTimestamp converts the value of time field to timestamp(ms), returns 0 if not support.
+func toTimestamp(value any) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UnixMilli()
+		}
+	}
+	return 0
+}
+
+// checkIndex checks if the index of proxy request path(/index/api) is the configured index of datasource.
+func checkIndex(_ *http.Request, path string, cfg json.RawMessage) error {
+	config := &DatasourceConfig{}
+	if len(cfg) > 0 {
+		if err := jsonUnmarshalFn(cfg, config); err != nil {
+			return err
+		}
+	}
+	index, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if config.Index == "" || index != config.Index {
+		return fmt.Errorf("only the index of datasource is allowed: %s", index)
+	}
+	return nil
+}
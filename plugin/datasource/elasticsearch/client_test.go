@@ -0,0 +1,448 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+
+	"github.com/lindb/linsight/model"
+)
+
+// recorded responses of Elasticsearch
+const (
+	rawSearchResp = `{"took":3,"timed_out":false,"hits":{"total":{"value":2,"relation":"eq"},"max_score":null,"hits":[` +
+		`{"_index":"logs-2023.03.28","_id":"1","_score":null,"_source":{"@timestamp":"2023-03-28T10:41:00.000Z","level":"error","message":"timeout"}},` +
+		`{"_index":"logs-2023.03.28","_id":"2","_score":null,"_source":{"@timestamp":1680000000000,"level":"info","message":"ok"}}]}}`
+	rawSearchES6Resp = `{"took":3,"timed_out":false,"hits":{"total":5,"hits":[]}}`
+	histogramResp    = `{"took":2,"timed_out":false,"hits":{"total":{"value":3,"relation":"eq"},"hits":[]},` +
+		`"aggregations":{"agg":{"buckets":[{"key_as_string":"1680000000000","key":1680000000000,"doc_count":2},` +
+		`{"key_as_string":"1680000060000","key":1680000060000,"doc_count":1}]}}}`
+	termsResp = `{"took":2,"timed_out":false,"hits":{"total":{"value":3,"relation":"eq"},"hits":[]},` +
+		`"aggregations":{"agg":{"doc_count_error_upper_bound":0,"sum_other_doc_count":0,"buckets":[` +
+		`{"key":"error","doc_count":2,"metric":{"value":1.5}},{"key":"info","doc_count":1,"metric":{"value":null}}]}}}`
+	errorResp    = `{"error":{"root_cause":[],"type":"search_phase_execution_exception","reason":"all shards failed"},"status":400}`
+	indicesResp  = `[{"index":"logs-2023.03.29"},{"index":"logs-2023.03.28"}]`
+	mappingsResp = `{"logs-2023.03.28":{"mappings":{"properties":{"@timestamp":{"type":"date"},` +
+		`"message":{"type":"text","fields":{"keyword":{"type":"keyword","ignore_above":256}}},` +
+		`"host":{"properties":{"name":{"type":"keyword"}}}}}}}`
+)
+
+// newElasticsearchServer creates a stand-in server that replays recorded responses.
+func newElasticsearchServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logs/_search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		req := gjson.ParseBytes(body)
+		assert.Equal(t, int64(1680000000000), req.Get("query.bool.filter.0.range.@timestamp.gte").Int())
+		switch {
+		case req.Get("query.bool.filter.1.query_string.query").String() == "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(errorResp))
+		case req.Get("query.bool.filter.1.query_string.query").String() == "es6":
+			_, _ = w.Write([]byte(rawSearchES6Resp))
+		case req.Get("aggs.agg.date_histogram").Exists():
+			assert.Equal(t, "60000ms", req.Get("aggs.agg.date_histogram.fixed_interval").String())
+			_, _ = w.Write([]byte(histogramResp))
+		case req.Get("aggs.agg.terms").Exists():
+			assert.Equal(t, "level", req.Get("aggs.agg.terms.field").String())
+			assert.Equal(t, "latency", req.Get("aggs.agg.aggs.metric.avg.field").String())
+			_, _ = w.Write([]byte(termsResp))
+		default:
+			assert.Equal(t, int64(10), req.Get("size").Int())
+			assert.Equal(t, "asc", req.Get("sort.0.@timestamp.order").String())
+			_, _ = w.Write([]byte(rawSearchResp))
+		}
+	})
+	mux.HandleFunc("/_cat/indices", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(indicesResp))
+	})
+	mux.HandleFunc("/logs/_mapping", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mappingsResp))
+	})
+	mux.HandleFunc("/_cluster/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"cluster_name":"es","status":"green"}`))
+	})
+	mux.HandleFunc("/_cat/indices/logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"index":"logs"}]`))
+	})
+	mux.HandleFunc("/_cat/indices/unknown", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/unknown/_mapping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`not found`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_NewClient(t *testing.T) {
+	defer func() {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+	}()
+	t.Run("create client failure", func(t *testing.T) {
+		jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+			return fmt.Errorf("err")
+		}
+		cli, err := NewClient(&model.Datasource{}, []byte{})
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("create client successfully", func(t *testing.T) {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		cli, err := NewClient(&model.Datasource{}, []byte(`{"index":"logs"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, defaultTimeField, cli.(*client).cfg.TimeField)
+	})
+}
+
+func TestClient_DataQuery(t *testing.T) {
+	server := newElasticsearchServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{"index":"logs"}`))
+	assert.NoError(t, err)
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680003600000}
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "query type not support",
+			req:  `{"type":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metric not support",
+			req:  `{"type":"terms","field":"level","metric":"p99"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metric field is empty",
+			req:  `{"type":"terms","field":"level","metric":"avg"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "terms field is empty",
+			req:  `{"type":"terms"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid interval",
+			req:  `{"type":"dateHistogram","interval":"abc"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "new http request failure",
+			req:  `{"type":"raw"}`,
+			prepare: func() {
+				newRequestFn = func(_ context.Context, _, _ string, _ io.Reader) (*http.Request, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "read resp body failure",
+			req:  `{"type":"raw","size":10,"sort":"asc"}`,
+			prepare: func() {
+				readAllFn = func(_ io.Reader) ([]byte, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "search failure",
+			req:  `{"type":"raw","query":"bad"}`,
+			assert: func(_ any, err error) {
+				assert.EqualError(t, err, "search_phase_execution_exception: all shards failed")
+			},
+		},
+		{
+			name: "raw search",
+			req:  `{"type":"raw","size":10,"sort":"asc"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, model.FrameTypeLogs, frame.Meta.Type)
+				assert.Equal(t, int64(2), frame.Meta.Custom["total"])
+				assert.Equal(t, 2, frame.Rows())
+				assert.Equal(t, []any{int64(1680000060000), int64(1680000000000)}, frame.Fields[0].Values)
+				assert.Equal(t, []any{"1", "2"}, frame.Fields[1].Values)
+				assert.Equal(t, "logs-2023.03.28", frame.Fields[2].Values[0])
+				assert.Equal(t, "timeout", frame.Fields[3].Values[0].(map[string]any)["message"])
+			},
+		},
+		{
+			name: "raw search with total as number",
+			req:  `{"type":"raw","size":10,"sort":"asc","query":"es6"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(5), rs.(model.Frames)[0].Meta.Custom["total"])
+			},
+		},
+		{
+			name: "date histogram",
+			req:  `{"type":"dateHistogram","index":"logs","interval":"1m"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, &model.FrameMeta{Type: model.FrameTypeTimeSeries, Interval: 60000}, frame.Meta)
+				assert.Len(t, frame.Fields, 2)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frame.Fields[0].Values)
+				assert.Equal(t, []any{2.0, 1.0}, frame.Fields[1].Values)
+			},
+		},
+		{
+			name: "terms with metric",
+			req:  `{"type":"terms","field":"level","metric":"avg","metricField":"latency"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, model.FrameTypeTable, frame.Meta.Type)
+				assert.Len(t, frame.Fields, 3)
+				assert.Equal(t, []any{"error", "info"}, frame.Fields[0].Values)
+				assert.Equal(t, []any{2.0, 1.0}, frame.Fields[1].Values)
+				assert.Equal(t, []any{1.5, nil}, frame.Fields[2].Values)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+				newRequestFn = http.NewRequestWithContext
+				readAllFn = io.ReadAll
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, timeRange)
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_DataQuery_WithoutIndex(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	rs, err := cli.DataQuery(context.TODO(), &model.Query{Request: json.RawMessage(`{"type":"raw"}`)}, model.TimeRange{})
+	assert.Error(t, err)
+	assert.Nil(t, rs)
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	server := newElasticsearchServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{"index":"logs"}`))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metadata type not support",
+			req:  `{"type":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "index list",
+			req:  `{"type":"indices"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"logs-2023.03.28", "logs-2023.03.29"}, rs)
+			},
+		},
+		{
+			name: "field list",
+			req:  `{"type":"fields"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []FieldInfo{
+					{Name: "@timestamp", Type: "date"},
+					{Name: "host.name", Type: "keyword"},
+					{Name: "message", Type: "text"},
+					{Name: "message.keyword", Type: "keyword"},
+				}, rs)
+			},
+		},
+		{
+			name: "index not found",
+			req:  `{"type":"fields","index":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_getInterval(t *testing.T) {
+	interval, err := getInterval("", model.TimeRange{From: 0, To: time.Hour.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, 12*time.Second, interval)
+	interval, err = getInterval("", model.TimeRange{From: 0, To: time.Minute.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, interval)
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := newElasticsearchServer(t)
+	defer server.Close()
+
+	cases := []struct {
+		name    string
+		url     string
+		cfg     string
+		wantErr bool
+	}{
+		{
+			name:    "cluster not available",
+			url:     server.URL + "/not_found",
+			cfg:     `{}`,
+			wantErr: true,
+		},
+		{
+			name: "cluster is available",
+			url:  server.URL,
+			cfg:  `{}`,
+		},
+		{
+			name:    "index not found",
+			url:     server.URL,
+			cfg:     `{"index":"unknown"}`,
+			wantErr: true,
+		},
+		{
+			name: "index exists",
+			url:  server.URL,
+			cfg:  `{"index":"logs"}`,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cli, err := NewClient(&model.Datasource{URL: tt.url}, []byte(tt.cfg))
+			assert.NoError(t, err)
+			err = cli.CheckHealth(context.TODO())
+			if tt.wantErr != (err != nil) {
+				t.Fatal(tt.name)
+			}
+		})
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
+
+func TestClient_checkIndex(t *testing.T) {
+	cfg := json.RawMessage(`{"index":"logs-*"}`)
+	assert.NoError(t, checkIndex(nil, "/logs-*/_search", cfg))
+	assert.NoError(t, checkIndex(nil, "/logs-*/_mapping", cfg))
+	assert.Error(t, checkIndex(nil, "/secrets/_search", cfg))
+	assert.Error(t, checkIndex(nil, "/logs-*,secrets/_search", cfg))
+	assert.Error(t, checkIndex(nil, "/_all/_search", cfg))
+	assert.Error(t, checkIndex(nil, "/logs-*/_search", nil))
+	assert.Error(t, checkIndex(nil, "/logs-*/_search", json.RawMessage(`[]`)))
+
+	// the routes on index are checked
+	for _, route := range ProxyRoutes {
+		if route.Match(http.MethodPost, "/secrets/_search") || route.Match(http.MethodGet, "/secrets/_mapping") {
+			assert.NotNil(t, route.Check)
+		}
+	}
+}
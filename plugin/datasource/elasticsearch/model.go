@@ -0,0 +1,200 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lindb/linsight/model"
+)
+
+// QueryType represents data query type for Elasticsearch/OpenSearch.
+type QueryType = string
+
+// Defines all data query types.
+var (
+	Raw           QueryType = "raw"
+	DateHistogram           = "dateHistogram"
+	Terms                   = "terms"
+)
+
+// MetadataType represents metadata type for Elasticsearch/OpenSearch.
+type MetadataType = string
+
+// Defines all metadata types.
+var (
+	Indices MetadataType = "indices"
+	Fields               = "fields"
+)
+
+// MetricType represents the metric aggregation of bucket.
+type MetricType = string
+
+// Defines all supported metric aggregations, count is doc count of bucket.
+var (
+	Count MetricType = "count"
+	Avg              = "avg"
+	Sum              = "sum"
+	Min              = "min"
+	Max              = "max"
+)
+
+// DatasourceConfig represents datasource config for Elasticsearch/OpenSearch.
+type DatasourceConfig struct {
+	// Index represents default index(pattern) of query, e.g. logs-*.
+	Index string `json:"index"`
+	// TimeField represents the time field of document(default @timestamp).
+	TimeField string `json:"timeField"`
+}
+
+// DataQueryRequest represents data query request for Elasticsearch/OpenSearch.
+type DataQueryRequest struct {
+	Type QueryType `json:"type"`
+	// Index overrides the default index of datasource.
+	Index string `json:"index"`
+	// Query represents Lucene query string, empty means match all.
+	Query string `json:"query"`
+	// Size represents max documents for raw query(default 100).
+	Size int `json:"size"`
+	// Sort represents the order of time field for raw query(asc/desc, default desc).
+	Sort string `json:"sort"`
+	// Interval represents the interval of date histogram(e.g. 1m), calculates it based on time range if not set.
+	Interval string `json:"interval"`
+	// Field represents the field of terms aggregation.
+	Field string `json:"field"`
+	// TermsSize represents max buckets of terms aggregation(default 10).
+	TermsSize int `json:"termsSize"`
+	// Metric/MetricField represents the metric aggregation of each bucket(default count).
+	Metric      MetricType `json:"metric"`
+	MetricField string     `json:"metricField"`
+}
+
+// MetadataQueryRequest represents metadata query request for Elasticsearch/OpenSearch.
+type MetadataQueryRequest struct {
+	Type  MetadataType `json:"type"`
+	Index string       `json:"index"`
+}
+
+// Document represents a document of raw search result.
+type Document struct {
+	ID        string         `json:"id"`
+	Index     string         `json:"index"`
+	Timestamp int64          `json:"timestamp"`
+	Source    map[string]any `json:"source"`
+}
+
+// RawData represents the result of raw search.
+type RawData struct {
+	Total     int64       `json:"total"`
+	Documents []*Document `json:"documents"`
+}
+
+// Bucket represents a bucket of aggregation result.
+type Bucket struct {
+	// Key represents the timestamp(ms) of date histogram or the term of terms aggregation.
+	Key   any      `json:"key"`
+	Count int64    `json:"count"`
+	Value *float64 `json:"value"`
+}
+
+// AggregationData represents the result of date histogram/terms aggregation.
+type AggregationData struct {
+	// Interval represents the interval(ms) of date histogram.
+	Interval int64     `json:"interval,omitempty"`
+	Buckets  []*Bucket `json:"buckets"`
+}
+
+// FieldInfo represents the field of index mapping.
+type FieldInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// searchResponse represents the response of _search API.
+type searchResponse struct {
+	Hits struct {
+		// Total is number(ES 6) or object(ES 7+/OpenSearch).
+		Total json.RawMessage `json:"total"`
+		Hits  []struct {
+			Index  string         `json:"_index"`
+			ID     string         `json:"_id"`
+			Source map[string]any `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Buckets []map[string]json.RawMessage `json:"buckets"`
+	} `json:"aggregations"`
+}
+
+// errorResponse represents the error response of Elasticsearch/OpenSearch.
+type errorResponse struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// toFrame converts documents to logs frame, the source of document is other type field.
+func (rs *RawData) toFrame(index string) *model.Frame {
+	timeField := model.NewField(model.TimeFieldName, model.FieldTypeTime, nil)
+	idField := model.NewField("id", model.FieldTypeString, nil)
+	indexField := model.NewField("index", model.FieldTypeString, nil)
+	sourceField := model.NewField("source", model.FieldTypeOther, nil)
+	for _, doc := range rs.Documents {
+		timeField.Values = append(timeField.Values, doc.Timestamp)
+		idField.Values = append(idField.Values, doc.ID)
+		indexField.Values = append(indexField.Values, doc.Index)
+		sourceField.Values = append(sourceField.Values, doc.Source)
+	}
+	return model.NewFrame(index, timeField, idField, indexField, sourceField).SetMeta(&model.FrameMeta{
+		Type:   model.FrameTypeLogs,
+		Custom: map[string]any{"total": rs.Total},
+	})
+}
+
+// toFrame converts buckets to frame, date histogram as time series frame, terms as table frame,
+// value field exists only if metric aggregation is used.
+func (rs *AggregationData) toFrame(index string, isHistogram, withValue bool) *model.Frame {
+	keyField := model.NewField("key", model.FieldTypeString, nil)
+	meta := &model.FrameMeta{Type: model.FrameTypeTable}
+	if isHistogram {
+		keyField = model.NewField(model.TimeFieldName, model.FieldTypeTime, nil)
+		meta = &model.FrameMeta{Type: model.FrameTypeTimeSeries, Interval: rs.Interval}
+	}
+	countField := model.NewField("count", model.FieldTypeNumber, nil)
+	valueField := model.NewField("value", model.FieldTypeNumber, nil)
+	for _, bucket := range rs.Buckets {
+		if isHistogram {
+			keyField.Values = append(keyField.Values, bucket.Key)
+		} else {
+			keyField.Values = append(keyField.Values, fmt.Sprintf("%v", bucket.Key))
+		}
+		countField.Values = append(countField.Values, float64(bucket.Count))
+		if bucket.Value == nil {
+			valueField.Values = append(valueField.Values, nil)
+		} else {
+			valueField.Values = append(valueField.Values, *bucket.Value)
+		}
+	}
+	frame := model.NewFrame(index, keyField, countField)
+	if withValue {
+		frame.Fields = append(frame.Fields, valueField)
+	}
+	return frame.SetMeta(meta)
+}
@@ -0,0 +1,226 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"github.com/lindb/common/pkg/encoding"
+
+	"github.com/lindb/linsight/model"
+)
+
+// DatasourceUID represents the uid of expression pseudo-datasource.
+const DatasourceUID = "__expr__"
+
+// valueField represents the value field name of result frame.
+const valueField = "value"
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+)
+
+// IsExpression checks if the query is an expression query.
+func IsExpression(query *model.Query) bool {
+	return query.Datasource.UID == DatasourceUID
+}
+
+// Expression represents the parsed expression query which evaluates over the results of other queries.
+type Expression struct {
+	refID        string
+	req          *DataQueryRequest
+	math         *govaluate.EvaluableExpression
+	dependencies []string
+}
+
+// Parse parses the expression query.
+func Parse(query *model.Query) (*Expression, error) {
+	data, _ := query.Request.MarshalJSON()
+	req := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(data, req); err != nil {
+		return nil, err
+	}
+	expr := &Expression{refID: query.RefID, req: req}
+	switch req.Type {
+	case Math:
+		math, dependencies, err := parseMath(req.Expression)
+		if err != nil {
+			return nil, err
+		}
+		expr.math = math
+		expr.dependencies = dependencies
+	case Reduce, Resample, Threshold:
+		refID := strings.Trim(strings.TrimSpace(req.Expression), "${}")
+		if refID == "" {
+			return nil, fmt.Errorf("expression is required")
+		}
+		expr.dependencies = []string{refID}
+	default:
+		return nil, fmt.Errorf("expression type not support, type: %s", req.Type)
+	}
+	return expr, nil
+}
+
+// Dependencies returns the refIds of queries which expression depends on.
+func (e *Expression) Dependencies() []string {
+	return e.dependencies
+}
+
+// Execute evaluates the expression over the results of dependent queries(keyed by refId).
+func (e *Expression) Execute(results map[string]model.Frames, timeRange model.TimeRange) (model.Frames, error) {
+	vars := make(map[string][]*series, len(e.dependencies))
+	for _, refID := range e.dependencies {
+		frames, ok := results[refID]
+		if !ok {
+			return nil, fmt.Errorf("query not found, refId: %s", refID)
+		}
+		values, err := fromFrames(frames)
+		if err != nil {
+			return nil, fmt.Errorf("query '%s': %w", refID, err)
+		}
+		vars[refID] = values
+	}
+	var (
+		rs       []*series
+		interval time.Duration
+		err      error
+	)
+	switch e.req.Type {
+	case Math:
+		rs, err = evalMath(e.math, e.dependencies, vars)
+	case Reduce:
+		rs, err = reduce(vars[e.dependencies[0]], e.req.Reducer)
+	case Resample:
+		rs, interval, err = resample(vars[e.dependencies[0]], e.req, timeRange)
+	case Threshold:
+		rs, err = threshold(vars[e.dependencies[0]], e.req.Evaluator)
+	}
+	if err != nil {
+		return nil, err
+	}
+	frames := toFrames(e.refID, e.req.Expression, rs)
+	if interval > 0 {
+		for _, frame := range frames {
+			frame.Meta.Interval = interval.Milliseconds()
+		}
+	}
+	return frames, nil
+}
+
+// series represents a numeric field of frame, timestamps is nil if it is a number(single value),
+// NaN value means null.
+type series struct {
+	labels     map[string]string
+	timestamps []int64
+	values     []float64
+}
+
+// isNumber checks if series is a number.
+func (s *series) isNumber() bool {
+	return s.timestamps == nil
+}
+
+// fromFrames extracts the numeric fields of frames as series,
+// the frame without time field must be one row which is converted to number.
+func fromFrames(frames model.Frames) ([]*series, error) {
+	var rs []*series
+	for _, frame := range frames {
+		timeIdx := -1
+		for idx, field := range frame.Fields {
+			if field.Type == model.FieldTypeTime {
+				timeIdx = idx
+				break
+			}
+		}
+		if timeIdx < 0 && frame.Rows() > 1 {
+			return nil, fmt.Errorf("frame '%s' is neither time series nor number", frame.Name)
+		}
+		for _, field := range frame.Fields {
+			if field.Type != model.FieldTypeNumber && field.Type != model.FieldTypeInteger {
+				continue
+			}
+			s := &series{labels: field.Labels}
+			for row, value := range field.Values {
+				if timeIdx >= 0 {
+					timestamp, ok := frame.Fields[timeIdx].Values[row].(int64)
+					if !ok {
+						continue
+					}
+					s.timestamps = append(s.timestamps, timestamp)
+				}
+				s.values = append(s.values, toFloat64(value))
+			}
+			if timeIdx >= 0 && s.timestamps == nil {
+				s.timestamps = []int64{}
+			}
+			if timeIdx < 0 && len(s.values) == 0 {
+				s.values = []float64{math.NaN()}
+			}
+			rs = append(rs, s)
+		}
+	}
+	if len(rs) == 0 {
+		return nil, fmt.Errorf("no numeric data")
+	}
+	return rs, nil
+}
+
+// toFrames converts series to frames, one frame per series, number is converted to table frame with one row.
+func toFrames(refID, expression string, values []*series) model.Frames {
+	frames := make(model.Frames, 0, len(values))
+	for _, s := range values {
+		field := model.NewField(valueField, model.FieldTypeNumber, s.labels)
+		for _, v := range s.values {
+			if math.IsNaN(v) {
+				field.Values = append(field.Values, nil)
+			} else {
+				field.Values = append(field.Values, v)
+			}
+		}
+		var frame *model.Frame
+		if s.isNumber() {
+			frame = model.NewFrame(refID, field).SetMeta(&model.FrameMeta{Type: model.FrameTypeTable})
+		} else {
+			timeField := model.NewField(model.TimeFieldName, model.FieldTypeTime, nil)
+			for _, timestamp := range s.timestamps {
+				timeField.Values = append(timeField.Values, timestamp)
+			}
+			frame = model.NewFrame(refID, timeField, field).SetMeta(&model.FrameMeta{Type: model.FrameTypeTimeSeries})
+		}
+		frame.Meta.ExecutedQuery = expression
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// toFloat64 converts the value of numeric field to float64, null is converted to NaN.
+func toFloat64(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	default:
+		return math.NaN()
+	}
+}
@@ -0,0 +1,406 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+)
+
+// newTimeSeries creates a time series frame with one value field.
+func newTimeSeries(labels map[string]string, points map[int64]float64) *model.Frame {
+	return model.NewTimeSeriesFrame("cpu", labels, map[string]map[int64]float64{"value": points})
+}
+
+// newNumber creates a number frame.
+func newNumber(labels map[string]string, value any) *model.Frame {
+	field := model.NewField("value", model.FieldTypeNumber, labels)
+	field.Values = []any{value}
+	return model.NewFrame("cpu", field)
+}
+
+func TestParse(t *testing.T) {
+	defer func() {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+	}()
+	cases := []struct {
+		name         string
+		req          string
+		prepare      func()
+		dependencies []string
+		wantErr      bool
+	}{
+		{
+			name: "unmarshal request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			wantErr: true,
+		},
+		{name: "type not support", req: `{"type":"unknown"}`, wantErr: true},
+		{name: "math without variable", req: `{"type":"math","expression":"1 + 1"}`, wantErr: true},
+		{name: "invalid math", req: `{"type":"math","expression":"$A +"}`, wantErr: true},
+		{name: "unknown variable", req: `{"type":"math","expression":"$A + B"}`, wantErr: true},
+		{name: "reduce without input", req: `{"type":"reduce","expression":" "}`, wantErr: true},
+		{
+			name:         "math",
+			req:          `{"type":"math","expression":"$A / ${B} * 100 + $A"}`,
+			dependencies: []string{"A", "B"},
+		},
+		{
+			name:         "reduce",
+			req:          `{"type":"reduce","expression":"$A","reducer":"last"}`,
+			dependencies: []string{"A"},
+		},
+		{
+			name:         "threshold",
+			req:          `{"type":"threshold","expression":"B"}`,
+			dependencies: []string{"B"},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			expr, err := Parse(&model.Query{RefID: "C", Request: json.RawMessage(tt.req)})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dependencies, expr.Dependencies())
+		})
+	}
+}
+
+func TestIsExpression(t *testing.T) {
+	assert.True(t, IsExpression(&model.Query{Datasource: model.TargetDatasource{UID: DatasourceUID}}))
+	assert.False(t, IsExpression(&model.Query{Datasource: model.TargetDatasource{UID: "lindb"}}))
+}
+
+func TestExpression_Execute(t *testing.T) {
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680000180000}
+	results := map[string]model.Frames{
+		"A": {
+			newTimeSeries(map[string]string{"host": "a"}, map[int64]float64{1680000000000: 1, 1680000060000: 2, 1680000120000: 3}),
+			newTimeSeries(map[string]string{"host": "b"}, map[int64]float64{1680000000000: 4, 1680000060000: 5}),
+		},
+		"B": {
+			newTimeSeries(map[string]string{"host": "a"}, map[int64]float64{1680000000000: 2, 1680000060000: 0}),
+			newTimeSeries(map[string]string{"host": "b"}, map[int64]float64{1680000000000: 8, 1680000060000: 10}),
+		},
+		"N":     {newNumber(nil, 10.0)},
+		"Null":  {newNumber(nil, nil)},
+		"Table": {model.NewFrame("t", &model.Field{Name: "a", Type: model.FieldTypeNumber, Values: []any{1.0, 2.0}})},
+		"Text":  {model.NewFrame("t", &model.Field{Name: "a", Type: model.FieldTypeString, Values: []any{"a"}})},
+		"Host": {
+			newTimeSeries(map[string]string{"host": "a", "idc": "sh"}, map[int64]float64{1680000000000: 1, 1680000060000: 1}),
+			newTimeSeries(map[string]string{"host": "c", "idc": "sh"}, map[int64]float64{1680000000000: 1}),
+		},
+		"Region": {
+			newTimeSeries(map[string]string{"region": "sh"}, map[int64]float64{1680000000000: 1}),
+			newTimeSeries(map[string]string{"region": "bj"}, map[int64]float64{1680000000000: 1}),
+		},
+	}
+	for i := 0; i < 200; i++ {
+		results["Many"] = append(results["Many"],
+			newTimeSeries(map[string]string{"host": fmt.Sprintf("%d", i)}, map[int64]float64{1680000000000: 1}))
+		if i < 60 {
+			results["Empty"] = append(results["Empty"], newTimeSeries(nil, map[int64]float64{1680000000000: 1}))
+		}
+	}
+	cases := []struct {
+		name   string
+		req    string
+		assert func(frames model.Frames, err error)
+	}{
+		{
+			name: "query not found",
+			req:  `{"type":"reduce","expression":"X","reducer":"last"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.EqualError(t, err, "query not found, refId: X")
+			},
+		},
+		{
+			name: "not time series or number",
+			req:  `{"type":"reduce","expression":"Table","reducer":"last"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "no numeric data",
+			req:  `{"type":"reduce","expression":"Text","reducer":"last"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "math join by labels",
+			req:  `{"type":"math","expression":"$A / $B * 100"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, "C", frames[0].Name)
+				assert.Equal(t, model.FrameTypeTimeSeries, frames[0].Meta.Type)
+				assert.Equal(t, "$A / $B * 100", frames[0].Meta.ExecutedQuery)
+				assert.Equal(t, map[string]string{"host": "a"}, frames[0].Fields[1].Labels)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frames[0].Fields[0].Values)
+				assert.Equal(t, []any{50.0, math.Inf(1)}, frames[0].Fields[1].Values)
+				assert.Equal(t, map[string]string{"host": "b"}, frames[1].Fields[1].Labels)
+				assert.Equal(t, []any{50.0, 50.0}, frames[1].Fields[1].Values)
+			},
+		},
+		{
+			name: "math join by labels with subset",
+			req:  `{"type":"math","expression":"$A + $Host"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, frames, 1)
+				assert.Equal(t, map[string]string{"host": "a", "idc": "sh"}, frames[0].Fields[1].Labels)
+				assert.Equal(t, []any{2.0, 3.0}, frames[0].Fields[1].Values)
+			},
+		},
+		{
+			name: "math not join different labels",
+			req:  `{"type":"math","expression":"$A + $Region"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, frames)
+			},
+		},
+		{
+			name: "math too many series",
+			req:  `{"type":"math","expression":"$Many + $Empty"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "math with number and function",
+			req:  `{"type":"math","expression":"abs($A - $N) > 7"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, []any{1.0, 1.0, 0.0}, frames[0].Fields[1].Values)
+				assert.Equal(t, []any{0.0, 0.0}, frames[1].Fields[1].Values)
+			},
+		},
+		{
+			name: "math with numbers",
+			req:  `{"type":"math","expression":"$N * 2"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, model.FrameTypeTable, frames[0].Meta.Type)
+				assert.Equal(t, []any{20.0}, frames[0].Fields[0].Values)
+			},
+		},
+		{
+			name: "math with null",
+			req:  `{"type":"math","expression":"$N + $Null"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []any{nil}, frames[0].Fields[0].Values)
+			},
+		},
+		{
+			name: "math result not number",
+			req:  `{"type":"math","expression":"$N + 'a'"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "math evaluate failure",
+			req:  `{"type":"math","expression":"abs($N, 1)"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "reducer not support",
+			req:  `{"type":"reduce","expression":"A","reducer":"unknown"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "reduce",
+			req:  `{"type":"reduce","expression":"$A","reducer":"mean"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, model.FrameTypeTable, frames[0].Meta.Type)
+				assert.Equal(t, map[string]string{"host": "a"}, frames[0].Fields[0].Labels)
+				assert.Equal(t, []any{2.0}, frames[0].Fields[0].Values)
+				assert.Equal(t, []any{4.5}, frames[1].Fields[0].Values)
+			},
+		},
+		{
+			name: "reduce number",
+			req:  `{"type":"reduce","expression":"N","reducer":"max"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []any{10.0}, frames[0].Fields[0].Values)
+			},
+		},
+		{
+			name: "invalid window",
+			req:  `{"type":"resample","expression":"A","window":"abc"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "resample number",
+			req:  `{"type":"resample","expression":"N","window":"1m"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "resample",
+			req:  `{"type":"resample","expression":"A","window":"2m","reducer":"sum"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(120000), frames[0].Meta.Interval)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000120000)}, frames[0].Fields[0].Values)
+				assert.Equal(t, []any{3.0, 3.0}, frames[0].Fields[1].Values)
+			},
+		},
+		{
+			name: "resample with backfill",
+			req:  `{"type":"resample","expression":"A","window":"1m","upsampler":"backfill"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []any{4.0, 5.0, nil, nil}, frames[1].Fields[1].Values)
+			},
+		},
+		{
+			name: "resample with pad",
+			req:  `{"type":"resample","expression":"A","window":"1m","upsampler":"pad"}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []any{4.0, 5.0, 5.0, 5.0}, frames[1].Fields[1].Values)
+			},
+		},
+		{
+			name: "threshold without evaluator",
+			req:  `{"type":"threshold","expression":"A"}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "threshold with invalid params",
+			req:  `{"type":"threshold","expression":"A","evaluator":{"type":"within_range","params":[1]}}`,
+			assert: func(_ model.Frames, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "threshold",
+			req:  `{"type":"threshold","expression":"A","evaluator":{"type":"outside_range","params":[4,2]}}`,
+			assert: func(frames model.Frames, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []any{1.0, 0.0, 0.0}, frames[0].Fields[1].Values)
+				assert.Equal(t, []any{0.0, 1.0}, frames[1].Fields[1].Values)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(&model.Query{RefID: "C", Request: json.RawMessage(tt.req)})
+			assert.NoError(t, err)
+			tt.assert(expr.Execute(results, timeRange))
+		})
+	}
+}
+
+func TestResample_Failure(t *testing.T) {
+	values := []*series{{timestamps: []int64{1}, values: []float64{1}}}
+	_, _, err := resample(values, &DataQueryRequest{Window: "1m", Reducer: "unknown"}, model.TimeRange{From: 0, To: 10})
+	assert.Error(t, err)
+	_, _, err = resample(values, &DataQueryRequest{Window: "1m", Upsampler: "unknown"}, model.TimeRange{From: 0, To: 10})
+	assert.Error(t, err)
+	_, _, err = resample(values, &DataQueryRequest{Window: "1m"}, model.TimeRange{})
+	assert.Error(t, err)
+	_, _, err = resample(values, &DataQueryRequest{Window: "1us"}, model.TimeRange{From: 0, To: 10})
+	assert.Error(t, err)
+	_, _, err = resample(values, &DataQueryRequest{Window: "1ms"}, model.TimeRange{From: 0, To: 100000})
+	assert.Error(t, err)
+}
+
+func TestGetReducer(t *testing.T) {
+	values := []float64{math.NaN(), 3, 1, 2, math.NaN()}
+	cases := map[ReducerType]float64{
+		LastReducer:  2,
+		FirstReducer: 3,
+		MeanReducer:  2,
+		SumReducer:   6,
+		MinReducer:   1,
+		MaxReducer:   3,
+		CountReducer: 3,
+	}
+	for reducer, expect := range cases {
+		fn, err := getReducer(reducer)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, fn(values), reducer)
+		if reducer != CountReducer {
+			assert.True(t, math.IsNaN(fn([]float64{math.NaN()})), reducer)
+		}
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	values := []*series{{values: []float64{1, 5, math.NaN()}}}
+	cases := []struct {
+		evaluator *Evaluator
+		expect    []float64
+	}{
+		{evaluator: &Evaluator{Type: GreaterThan, Params: []float64{1}}, expect: []float64{0, 1}},
+		{evaluator: &Evaluator{Type: LessThan, Params: []float64{5}}, expect: []float64{1, 0}},
+		{evaluator: &Evaluator{Type: WithinRange, Params: []float64{0, 2}}, expect: []float64{1, 0}},
+	}
+	for _, tt := range cases {
+		rs, err := threshold(values, tt.evaluator)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expect, rs[0].values[:2])
+		assert.True(t, math.IsNaN(rs[0].values[2]))
+	}
+	_, err := threshold(values, &Evaluator{Type: GreaterThan})
+	assert.Error(t, err)
+	_, err = threshold(values, &Evaluator{Type: "unknown"})
+	assert.Error(t, err)
+}
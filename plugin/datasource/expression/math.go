@@ -0,0 +1,249 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/Knetic/govaluate"
+)
+
+// maxMathSeries represents the max series of math expression result.
+const maxMathSeries = 10000
+
+// variablePattern matches the reference of query result in math expression, like $A or ${A}.
+var variablePattern = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// mathFunctions represents the functions supported by math expression.
+var mathFunctions = map[string]govaluate.ExpressionFunction{
+	"abs":   unaryFunction("abs", math.Abs),
+	"ceil":  unaryFunction("ceil", math.Ceil),
+	"floor": unaryFunction("floor", math.Floor),
+	"round": unaryFunction("round", math.Round),
+	"log":   unaryFunction("log", math.Log),
+	"sqrt":  unaryFunction("sqrt", math.Sqrt),
+}
+
+// unaryFunction wraps a float function as the function of math expression.
+func unaryFunction(name string, fn func(float64) float64) govaluate.ExpressionFunction {
+	return func(args ...any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("function %s requires 1 argument", name)
+		}
+		v, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("function %s requires number argument", name)
+		}
+		return fn(v), nil
+	}
+}
+
+// parseMath parses math expression, returns the refIds which expression references(in order of appearance).
+func parseMath(expression string) (*govaluate.EvaluableExpression, []string, error) {
+	var dependencies []string
+	seen := make(map[string]struct{})
+	replaced := variablePattern.ReplaceAllStringFunc(expression, func(s string) string {
+		match := variablePattern.FindStringSubmatch(s)
+		refID := match[1] + match[2]
+		if _, ok := seen[refID]; !ok {
+			seen[refID] = struct{}{}
+			dependencies = append(dependencies, refID)
+		}
+		// escape refId as parameter of govaluate
+		return "[" + refID + "]"
+	})
+	if len(dependencies) == 0 {
+		return nil, nil, fmt.Errorf("math expression must reference at least one query, like $A")
+	}
+	expr, err := govaluate.NewEvaluableExpressionWithFunctions(replaced, mathFunctions)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range expr.Vars() {
+		if _, ok := seen[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown variable '%s', use $%s to reference query", name, name)
+		}
+	}
+	return expr, dependencies, nil
+}
+
+// combination represents a group of series(one series per refId) which are evaluated together.
+type combination struct {
+	// joinLabels are the labels of series from the refId which has multiple series.
+	joinLabels map[string]string
+	members    map[string]*series
+}
+
+// evalMath evaluates math expression, series of different refIds are joined by labels(the labels of one series
+// are the subset of another's), the refId which has only one series is joined with all series of other refIds.
+// Time series are joined by timestamp, number is used for each timestamp.
+func evalMath(expr *govaluate.EvaluableExpression, dependencies []string, vars map[string][]*series) ([]*series, error) {
+	combinations := []*combination{{joinLabels: map[string]string{}, members: map[string]*series{}}}
+	for _, refID := range dependencies {
+		values := vars[refID]
+		var next []*combination
+		for _, c := range combinations {
+			for _, s := range values {
+				if len(values) > 1 && !isLabelsCompatible(c.joinLabels, s.labels) {
+					continue
+				}
+				members := make(map[string]*series, len(c.members)+1)
+				for name, member := range c.members {
+					members[name] = member
+				}
+				members[refID] = s
+				joinLabels := c.joinLabels
+				if len(values) > 1 {
+					joinLabels = mergeLabels(c.joinLabels, s.labels)
+				}
+				next = append(next, &combination{joinLabels: joinLabels, members: members})
+				if len(next) > maxMathSeries {
+					return nil, fmt.Errorf("too many series joined by math expression, max: %d, "+
+						"please narrow the queries by filters or group by same labels", maxMathSeries)
+				}
+			}
+		}
+		combinations = next
+	}
+	rs := make([]*series, 0, len(combinations))
+	for _, c := range combinations {
+		s, err := evalCombination(expr, dependencies, c)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, s)
+	}
+	return rs, nil
+}
+
+// evalCombination evaluates math expression for a group of series,
+// the timestamps of result are the timestamps of first time series which present in all time series.
+func evalCombination(expr *govaluate.EvaluableExpression, dependencies []string, c *combination) (*series, error) {
+	labels := map[string]string{}
+	var timeSeries []*series
+	for _, refID := range dependencies {
+		member := c.members[refID]
+		labels = mergeLabels(labels, member.labels)
+		if !member.isNumber() {
+			timeSeries = append(timeSeries, member)
+		}
+	}
+	labels = mergeLabels(labels, c.joinLabels)
+	if len(labels) == 0 {
+		labels = nil
+	}
+	rs := &series{labels: labels}
+	params := make(map[string]any, len(c.members))
+	eval := func(valueAt func(s *series) (float64, bool)) (float64, bool, error) {
+		isNull := false
+		for name, member := range c.members {
+			v, ok := valueAt(member)
+			if !ok {
+				return 0, false, nil
+			}
+			if math.IsNaN(v) {
+				isNull = true
+			}
+			params[name] = v
+		}
+		if isNull {
+			return math.NaN(), true, nil
+		}
+		v, err := expr.Evaluate(params)
+		if err != nil {
+			return 0, false, err
+		}
+		switch result := v.(type) {
+		case float64:
+			return result, true, nil
+		case bool:
+			if result {
+				return 1, true, nil
+			}
+			return 0, true, nil
+		default:
+			return 0, false, fmt.Errorf("math expression result is not number: %v", v)
+		}
+	}
+	if len(timeSeries) == 0 {
+		v, _, err := eval(func(s *series) (float64, bool) {
+			return s.values[0], true
+		})
+		if err != nil {
+			return nil, err
+		}
+		rs.values = []float64{v}
+		return rs, nil
+	}
+	// index points of each time series by timestamp
+	indexes := make(map[*series]map[int64]float64, len(timeSeries))
+	for _, s := range timeSeries {
+		index := make(map[int64]float64, len(s.timestamps))
+		for idx, timestamp := range s.timestamps {
+			index[timestamp] = s.values[idx]
+		}
+		indexes[s] = index
+	}
+	rs.timestamps = []int64{}
+	for _, timestamp := range timeSeries[0].timestamps {
+		v, ok, err := eval(func(s *series) (float64, bool) {
+			if s.isNumber() {
+				return s.values[0], true
+			}
+			v, ok := indexes[s][timestamp]
+			return v, ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rs.timestamps = append(rs.timestamps, timestamp)
+			rs.values = append(rs.values, v)
+		}
+	}
+	return rs, nil
+}
+
+// isLabelsCompatible checks if the labels of one are the subset of another's.
+func isLabelsCompatible(a, b map[string]string) bool {
+	return isLabelsSubset(a, b) || isLabelsSubset(b, a)
+}
+
+// isLabelsSubset checks if all labels of a are in b with same values.
+func isLabelsSubset(a, b map[string]string) bool {
+	for k, v := range a {
+		if v2, ok := b[k]; !ok || v2 != v {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns the union of labels, the value of b wins if key conflict.
+func mergeLabels(a, b map[string]string) map[string]string {
+	rs := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		rs[k] = v
+	}
+	for k, v := range b {
+		rs[k] = v
+	}
+	return rs
+}
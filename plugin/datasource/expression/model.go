@@ -0,0 +1,87 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expression
+
+// ExpressionType represents the type of expression.
+type ExpressionType = string
+
+// Defines all expression types.
+var (
+	Math      ExpressionType = "math"
+	Reduce    ExpressionType = "reduce"
+	Resample  ExpressionType = "resample"
+	Threshold ExpressionType = "threshold"
+)
+
+// ReducerType represents the function which reduces series to single value.
+type ReducerType = string
+
+// Defines all reducer types.
+var (
+	LastReducer  ReducerType = "last"
+	FirstReducer ReducerType = "first"
+	MeanReducer  ReducerType = "mean"
+	SumReducer   ReducerType = "sum"
+	MinReducer   ReducerType = "min"
+	MaxReducer   ReducerType = "max"
+	CountReducer ReducerType = "count"
+)
+
+// UpsamplerType represents how to fill the window without points when resampling.
+type UpsamplerType = string
+
+// Defines all upsampler types.
+var (
+	// FillNA fills null.
+	FillNA UpsamplerType = "fillna"
+	// Pad fills the value of previous window.
+	Pad UpsamplerType = "pad"
+	// Backfill fills the value of next window.
+	Backfill UpsamplerType = "backfill"
+)
+
+// EvaluatorType represents the comparison of threshold.
+type EvaluatorType = string
+
+// Defines all evaluator types.
+var (
+	GreaterThan  EvaluatorType = "gt"
+	LessThan     EvaluatorType = "lt"
+	WithinRange  EvaluatorType = "within_range"
+	OutsideRange EvaluatorType = "outside_range"
+)
+
+// DataQueryRequest represents expression query request.
+type DataQueryRequest struct {
+	Type ExpressionType `json:"type"`
+	// Expression is the math expression(like $A / $B * 100) for math,
+	// or the refId of input query(like A or $A) for other types.
+	Expression string `json:"expression"`
+	// Reducer is used by reduce(reduces series to single value) and resample(downsampler).
+	Reducer ReducerType `json:"reducer"`
+	// Window is the interval of resampled series(like 1m).
+	Window    string        `json:"window"`
+	Upsampler UpsamplerType `json:"upsampler"`
+	Evaluator *Evaluator    `json:"evaluator"`
+}
+
+// Evaluator represents the condition of threshold.
+type Evaluator struct {
+	Type   EvaluatorType `json:"type"`
+	Params []float64     `json:"params"`
+}
@@ -0,0 +1,237 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lindb/linsight/model"
+)
+
+// maxResamplePoints represents the max points of each resampled series.
+const maxResamplePoints = 11000
+
+// reduce reduces each time series to number, number is kept.
+func reduce(values []*series, reducer ReducerType) ([]*series, error) {
+	fn, err := getReducer(reducer)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]*series, 0, len(values))
+	for _, s := range values {
+		if s.isNumber() {
+			rs = append(rs, s)
+			continue
+		}
+		rs = append(rs, &series{labels: s.labels, values: []float64{fn(s.values)}})
+	}
+	return rs, nil
+}
+
+// resample changes the interval of time series to window, the points in same window are reduced by reducer(default mean),
+// the window without points is filled by upsampler(default fillna). Timestamp of window is the start of window.
+func resample(values []*series, req *DataQueryRequest, timeRange model.TimeRange) ([]*series, time.Duration, error) {
+	window, err := time.ParseDuration(req.Window)
+	if err != nil || window <= 0 {
+		return nil, 0, fmt.Errorf("invalid window: %s", req.Window)
+	}
+	reducer := req.Reducer
+	if reducer == "" {
+		reducer = MeanReducer
+	}
+	downsample, err := getReducer(reducer)
+	if err != nil {
+		return nil, 0, err
+	}
+	upsampler := req.Upsampler
+	if upsampler == "" {
+		upsampler = FillNA
+	}
+	if upsampler != FillNA && upsampler != Pad && upsampler != Backfill {
+		return nil, 0, fmt.Errorf("upsampler not support, upsampler: %s", upsampler)
+	}
+	if timeRange.To <= timeRange.From {
+		return nil, 0, fmt.Errorf("invalid time range, from: %d, to: %d", timeRange.From, timeRange.To)
+	}
+	step := window.Milliseconds()
+	if step == 0 {
+		return nil, 0, fmt.Errorf("window must be at least 1ms: %s", req.Window)
+	}
+	start := timeRange.From - timeRange.From%step
+	points := int((timeRange.To-start)/step) + 1
+	if points > maxResamplePoints {
+		return nil, 0, fmt.Errorf("too many points after resampling: %d, max: %d", points, maxResamplePoints)
+	}
+	rs := make([]*series, 0, len(values))
+	for _, s := range values {
+		if s.isNumber() {
+			return nil, 0, fmt.Errorf("resample requires time series")
+		}
+		buckets := make([][]float64, points)
+		for idx, timestamp := range s.timestamps {
+			if timestamp < start {
+				continue
+			}
+			bucket := int((timestamp - start) / step)
+			if bucket >= points {
+				continue
+			}
+			buckets[bucket] = append(buckets[bucket], s.values[idx])
+		}
+		resampled := &series{labels: s.labels, timestamps: make([]int64, points), values: make([]float64, points)}
+		for idx := range buckets {
+			resampled.timestamps[idx] = start + int64(idx)*step
+			resampled.values[idx] = math.NaN()
+			if len(buckets[idx]) > 0 {
+				resampled.values[idx] = downsample(buckets[idx])
+			}
+		}
+		upsample(resampled.values, upsampler)
+		rs = append(rs, resampled)
+	}
+	return rs, window, nil
+}
+
+// upsample fills null values by previous(pad) or next(backfill) value.
+func upsample(values []float64, upsampler UpsamplerType) {
+	switch upsampler {
+	case Pad:
+		for idx := 1; idx < len(values); idx++ {
+			if math.IsNaN(values[idx]) {
+				values[idx] = values[idx-1]
+			}
+		}
+	case Backfill:
+		for idx := len(values) - 2; idx >= 0; idx-- {
+			if math.IsNaN(values[idx]) {
+				values[idx] = values[idx+1]
+			}
+		}
+	}
+}
+
+// threshold checks each value by evaluator, returns 1 if matched, else 0, null is kept.
+func threshold(values []*series, evaluator *Evaluator) ([]*series, error) {
+	if evaluator == nil {
+		return nil, fmt.Errorf("evaluator is required")
+	}
+	var match func(v float64) bool
+	params := evaluator.Params
+	switch evaluator.Type {
+	case GreaterThan, LessThan:
+		if len(params) != 1 {
+			return nil, fmt.Errorf("evaluator %s requires 1 param", evaluator.Type)
+		}
+		if evaluator.Type == GreaterThan {
+			match = func(v float64) bool { return v > params[0] }
+		} else {
+			match = func(v float64) bool { return v < params[0] }
+		}
+	case WithinRange, OutsideRange:
+		if len(params) != 2 {
+			return nil, fmt.Errorf("evaluator %s requires 2 params", evaluator.Type)
+		}
+		low, high := math.Min(params[0], params[1]), math.Max(params[0], params[1])
+		if evaluator.Type == WithinRange {
+			match = func(v float64) bool { return v > low && v < high }
+		} else {
+			match = func(v float64) bool { return v < low || v > high }
+		}
+	default:
+		return nil, fmt.Errorf("evaluator type not support, type: %s", evaluator.Type)
+	}
+	rs := make([]*series, 0, len(values))
+	for _, s := range values {
+		result := &series{labels: s.labels, timestamps: s.timestamps, values: make([]float64, len(s.values))}
+		for idx, v := range s.values {
+			switch {
+			case math.IsNaN(v):
+				result.values[idx] = v
+			case match(v):
+				result.values[idx] = 1
+			default:
+				result.values[idx] = 0
+			}
+		}
+		rs = append(rs, result)
+	}
+	return rs, nil
+}
+
+// getReducer returns the reduce function by type, null values are ignored,
+// returns null if no values(count returns 0).
+func getReducer(reducer ReducerType) (func(values []float64) float64, error) {
+	switch reducer {
+	case LastReducer:
+		return func(values []float64) float64 {
+			for idx := len(values) - 1; idx >= 0; idx-- {
+				if !math.IsNaN(values[idx]) {
+					return values[idx]
+				}
+			}
+			return math.NaN()
+		}, nil
+	case FirstReducer:
+		return func(values []float64) float64 {
+			for _, v := range values {
+				if !math.IsNaN(v) {
+					return v
+				}
+			}
+			return math.NaN()
+		}, nil
+	case CountReducer:
+		return func(values []float64) float64 {
+			count := 0
+			for _, v := range values {
+				if !math.IsNaN(v) {
+					count++
+				}
+			}
+			return float64(count)
+		}, nil
+	case MeanReducer, SumReducer, MinReducer, MaxReducer:
+		return func(values []float64) float64 {
+			rs, count := math.NaN(), 0
+			for _, v := range values {
+				if math.IsNaN(v) {
+					continue
+				}
+				count++
+				switch {
+				case count == 1:
+					rs = v
+				case reducer == MinReducer:
+					rs = math.Min(rs, v)
+				case reducer == MaxReducer:
+					rs = math.Max(rs, v)
+				default:
+					rs += v
+				}
+			}
+			if reducer == MeanReducer && count > 0 {
+				rs /= float64(count)
+			}
+			return rs
+		}, nil
+	default:
+		return nil, fmt.Errorf("reducer not support, reducer: %s", reducer)
+	}
+}
@@ -0,0 +1,214 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/pkg/interval"
+	"github.com/lindb/linsight/plugin"
+)
+
+// for testing
+var (
+	nowFn = time.Now
+)
+
+// guardPlugin wraps datasource plugin, applies the query limits of datasource config,
+// includes query timeout, max concurrent queries, max time range and max series/points of result.
+type guardPlugin struct {
+	plugin.DatasourcePlugin
+
+	name         string
+	timeout      time.Duration
+	maxTimeRange int64
+	maxSeries    int
+	maxPoints    int
+	slots        chan struct{}
+}
+
+// newGuardPlugin wraps datasource plugin with the query limits of datasource config, the query slots are got by
+// slotsFn(shared by the plugins of same datasource), returns the plugin itself if no limit configured.
+func newGuardPlugin(datasource *model.Datasource, cli plugin.DatasourcePlugin,
+	slotsFn func(limit int) chan struct{},
+) (plugin.DatasourcePlugin, error) {
+	cfg, err := datasource.GetQueryConfig()
+	if err != nil {
+		return nil, err
+	}
+	guard := &guardPlugin{
+		DatasourcePlugin: cli,
+		name:             datasource.Name,
+		maxSeries:        cfg.MaxSeries,
+		maxPoints:        cfg.MaxPoints,
+	}
+	if cfg.QueryTimeout != "" {
+		timeout, err := interval.Parse(cfg.QueryTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query timeout: %w", err)
+		}
+		guard.timeout = time.Duration(timeout) * time.Millisecond
+	}
+	if cfg.MaxTimeRange != "" {
+		if guard.maxTimeRange, err = interval.Parse(cfg.MaxTimeRange); err != nil {
+			return nil, fmt.Errorf("invalid max time range: %w", err)
+		}
+	}
+	if cfg.MaxConcurrentQueries > 0 {
+		if slotsFn != nil {
+			guard.slots = slotsFn(cfg.MaxConcurrentQueries)
+		} else {
+			guard.slots = make(chan struct{}, cfg.MaxConcurrentQueries)
+		}
+	}
+	if guard.timeout <= 0 && guard.maxTimeRange <= 0 && guard.slots == nil && guard.maxSeries <= 0 && guard.maxPoints <= 0 {
+		return cli, nil
+	}
+	return guard, nil
+}
+
+// DataQuery queries data if time range in limit, returns error if timeout or the size of result exceeds limit.
+// The max series/points are passed to plugin by context(plugin.QueryLimits), plugin stops reading result once
+// exceeds the limits if backend supports(LinDB limits series, SQL limits rows), otherwise the limits are only
+// checked after the whole result returned by plugin.
+func (p *guardPlugin) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	if err := p.checkTimeRange(timeRange); err != nil {
+		return nil, err
+	}
+	queryCtx, release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if p.maxSeries > 0 || p.maxPoints > 0 {
+		queryCtx = plugin.WithQueryLimits(queryCtx, plugin.QueryLimits{MaxSeries: p.maxSeries, MaxPoints: p.maxPoints})
+	}
+	frames, err := p.DatasourcePlugin.DataQuery(queryCtx, req, timeRange)
+	if err != nil {
+		return nil, p.checkTimeout(ctx, queryCtx, err)
+	}
+	if err := p.checkResult(frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// MetadataQuery queries metadata with timeout and concurrency limit.
+func (p *guardPlugin) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	queryCtx, release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	rs, err := p.DatasourcePlugin.MetadataQuery(queryCtx, req)
+	if err != nil {
+		return nil, p.checkTimeout(ctx, queryCtx, err)
+	}
+	return rs, nil
+}
+
+// CheckHealth checks if datasource is working with timeout limit.
+func (p *guardPlugin) CheckHealth(ctx context.Context) error {
+	checkCtx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	if err := p.DatasourcePlugin.CheckHealth(checkCtx); err != nil {
+		return p.checkTimeout(ctx, checkCtx, err)
+	}
+	return nil
+}
+
+// InterpolateVariables expands the variables of query request by the wrapped plugin.
+func (p *guardPlugin) InterpolateVariables(req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error) {
+	return plugin.InterpolateVariables(p.DatasourcePlugin, req, vars)
+}
+
+// withTimeout returns the context with query timeout if timeout configured.
+func (p *guardPlugin) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// acquire waits a query slot until context done(includes query timeout), returns the query context and
+// the function which releases the slot.
+func (p *guardPlugin) acquire(ctx context.Context) (context.Context, func(), error) {
+	queryCtx, cancel := p.withTimeout(ctx)
+	if p.slots == nil {
+		return queryCtx, cancel, nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return queryCtx, func() {
+			<-p.slots
+			cancel()
+		}, nil
+	case <-queryCtx.Done():
+		cancel()
+		return nil, nil, fmt.Errorf("too many concurrent queries on datasource '%s', limit: %d, %w",
+			p.name, cap(p.slots), queryCtx.Err())
+	}
+}
+
+// checkTimeout returns the timeout error if query context exceeded the query timeout, otherwise returns the error.
+func (p *guardPlugin) checkTimeout(ctx, queryCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query timeout on datasource '%s', limit: %s, %w", p.name, p.timeout, err)
+	}
+	return err
+}
+
+// checkTimeRange checks if the span of time range exceeds limit, the time range not set is ignored.
+func (p *guardPlugin) checkTimeRange(timeRange model.TimeRange) error {
+	if p.maxTimeRange <= 0 || (timeRange.From <= 0 && timeRange.To <= 0) {
+		return nil
+	}
+	to := timeRange.To
+	if to <= 0 {
+		to = nowFn().UnixMilli()
+	}
+	if span := to - timeRange.From; span > p.maxTimeRange {
+		return fmt.Errorf("time range too large on datasource '%s': %s, limit: %s",
+			p.name, interval.Format(span), interval.Format(p.maxTimeRange))
+	}
+	return nil
+}
+
+// checkResult checks if the number of series/points returned by query exceeds limit, it is post-hoc check which
+// runs after the whole result loaded into memory.
+func (p *guardPlugin) checkResult(frames model.Frames) error {
+	if p.maxSeries <= 0 && p.maxPoints <= 0 {
+		return nil
+	}
+	series, points := frames.Count()
+	if p.maxSeries > 0 && series > p.maxSeries {
+		return fmt.Errorf("too many series returned by datasource '%s': %d, limit: %d, "+
+			"please narrow the query by filters or group by", p.name, series, p.maxSeries)
+	}
+	if p.maxPoints > 0 && points > p.maxPoints {
+		return fmt.Errorf("too many points returned by datasource '%s': %d, limit: %d, "+
+			"please narrow the time range or increase the interval", p.name, points, p.maxPoints)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/plugin"
+)
+
+func TestGuardPlugin_New(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	cases := []struct {
+		name    string
+		config  string
+		guarded bool
+		wantErr bool
+	}{
+		{name: "no config", config: ``},
+		{name: "no limit", config: `{"downsample":"lttb"}`},
+		{name: "invalid config", config: `{"maxSeries":"a"}`, wantErr: true},
+		{name: "invalid timeout", config: `{"queryTimeout":"a"}`, wantErr: true},
+		{name: "invalid time range", config: `{"maxTimeRange":"a"}`, wantErr: true},
+		{name: "timeout", config: `{"queryTimeout":"10s"}`, guarded: true},
+		{name: "time range", config: `{"maxTimeRange":"30d"}`, guarded: true},
+		{name: "concurrency", config: `{"maxConcurrentQueries":10}`, guarded: true},
+		{name: "result", config: `{"maxSeries":10,"maxPoints":100}`, guarded: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newGuardPlugin(&model.Datasource{Config: []byte(tt.config)}, cli, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+				return
+			}
+			assert.NoError(t, err)
+			_, ok := p.(*guardPlugin)
+			assert.Equal(t, tt.guarded, ok)
+		})
+	}
+}
+
+func TestGuardPlugin_DataQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		nowFn = time.Now
+		ctrl.Finish()
+	}()
+	nowFn = func() time.Time {
+		return time.UnixMilli(10 * 3600 * 1000)
+	}
+
+	newFrames := func(points int) model.Frames {
+		frame := model.NewFrame("cpu",
+			model.NewField("time", model.FieldTypeTime, nil),
+			model.NewField("value", model.FieldTypeNumber, nil),
+		).SetMeta(&model.FrameMeta{Type: model.FrameTypeTimeSeries})
+		for i := 0; i < points; i++ {
+			_ = frame.AppendRow(int64(i), float64(i))
+		}
+		return model.Frames{frame}
+	}
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	p, err := newGuardPlugin(&model.Datasource{
+		Name:   "metric",
+		Config: []byte(`{"maxTimeRange":"1h","maxSeries":1,"maxPoints":2}`),
+	}, cli, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		timeRange model.TimeRange
+		prepare   func()
+		wantErr   bool
+	}{
+		{
+			name:      "time range too large",
+			timeRange: model.TimeRange{From: 1000, To: 2 * 3600 * 1000},
+			wantErr:   true,
+		},
+		{
+			name:      "time range too large, to now",
+			timeRange: model.TimeRange{From: 1000},
+			wantErr:   true,
+		},
+		{
+			name: "query failure",
+			prepare: func() {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many series",
+			prepare: func() {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(append(newFrames(1), newFrames(1)...), nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many points",
+			prepare: func() {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(newFrames(3), nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:      "query successfully",
+			timeRange: model.TimeRange{From: 9 * 3600 * 1000},
+			prepare: func() {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ *model.Query, _ model.TimeRange) (model.Frames, error) {
+						// limits passed to plugin
+						assert.Equal(t, plugin.QueryLimits{MaxSeries: 1, MaxPoints: 2}, plugin.GetQueryLimits(ctx))
+						return newFrames(2), nil
+					})
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			frames, err := p.DataQuery(context.TODO(), &model.Query{}, tt.timeRange)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, frames)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, frames, 1)
+			}
+		})
+	}
+}
+
+func TestGuardPlugin_Timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	p, err := newGuardPlugin(&model.Datasource{
+		Name:   "metric",
+		Config: []byte(`{"queryTimeout":"10ms","maxConcurrentQueries":1}`),
+	}, cli, nil)
+	assert.NoError(t, err)
+
+	waitDone := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	// query timeout
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *model.Query, _ model.TimeRange) (model.Frames, error) {
+			return nil, waitDone(ctx)
+		})
+	_, err = p.DataQuery(context.TODO(), &model.Query{}, model.TimeRange{})
+	assert.ErrorContains(t, err, "query timeout")
+
+	cli.EXPECT().MetadataQuery(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *model.Query) (any, error) {
+			return nil, waitDone(ctx)
+		})
+	_, err = p.MetadataQuery(context.TODO(), &model.Query{})
+	assert.ErrorContains(t, err, "query timeout")
+
+	cli.EXPECT().CheckHealth(gomock.Any()).DoAndReturn(waitDone)
+	assert.ErrorContains(t, p.CheckHealth(context.TODO()), "query timeout")
+
+	// canceled by caller, not timeout
+	ctx, cancel := context.WithCancel(context.TODO())
+	cli.EXPECT().CheckHealth(gomock.Any()).DoAndReturn(func(checkCtx context.Context) error {
+		cancel()
+		return waitDone(checkCtx)
+	})
+	err = p.CheckHealth(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	// no slot, wait until timeout
+	guard := p.(*guardPlugin)
+	guard.slots <- struct{}{}
+	_, err = p.DataQuery(context.TODO(), &model.Query{}, model.TimeRange{})
+	assert.ErrorContains(t, err, "too many concurrent queries")
+	_, err = p.MetadataQuery(context.TODO(), &model.Query{})
+	assert.ErrorContains(t, err, "too many concurrent queries")
+	<-guard.slots
+
+	// slot released after query
+	cli.EXPECT().MetadataQuery(gomock.Any(), gomock.Any()).Return("ok", nil).Times(2)
+	for i := 0; i < 2; i++ {
+		rs, err := p.MetadataQuery(context.TODO(), &model.Query{})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", rs)
+	}
+	cli.EXPECT().CheckHealth(gomock.Any()).Return(nil)
+	assert.NoError(t, p.CheckHealth(context.TODO()))
+}
+
+func TestGuardPlugin_InterpolateVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	p, err := newGuardPlugin(&model.Datasource{Config: []byte(`{"maxSeries":10}`)}, cli, nil)
+	assert.NoError(t, err)
+	interpolator, ok := p.(plugin.VariableInterpolator)
+	assert.True(t, ok)
+	vars, err := interpolate.NewVariables(map[string]any{"host": []any{"a", "b"}})
+	assert.NoError(t, err)
+	req, err := interpolator.InterpolateVariables(json.RawMessage(`{"host":"$host"}`), vars)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"host":"a,b"}`, string(req))
+}
@@ -0,0 +1,246 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+const (
+	childOf    = "CHILD_OF"
+	spanKind   = "span.kind"
+	logEvent   = "event"
+	defaultLog = "log"
+)
+
+// ProxyRoutes represents the Jaeger apis which datasource proxy is allowed to forward.
+var ProxyRoutes = plugin.ReadOnlyProxyRoutes("/api/**")
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+	newRequestFn    = http.NewRequestWithContext
+	readAllFn       = io.ReadAll
+)
+
+// client implements plugin.DatasourcePlugin for Jaeger query API.
+type client struct {
+	datasouce *model.Datasource
+	httpCli   *http.Client
+
+	logger logger.Logger
+}
+
+// NewClient creates a Jaeger client.
+func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	httpCli, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		datasouce: datasource,
+		httpCli:   httpCli,
+		logger:    logger.GetLogger("DatasourcePlugin", "Jaeger"),
+	}, nil
+}
+
+// DataQuery queries trace by trace id, returns the spans grouped by process.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	traceQueryReq := &GetTraceRequest{}
+	if err := jsonUnmarshalFn(data, &traceQueryReq); err != nil {
+		return nil, err
+	}
+	if traceQueryReq.TraceID == "" {
+		return nil, fmt.Errorf("trace id is required")
+	}
+	var traces []*jaegerTrace
+	if err := cli.get(ctx, "/api/traces/"+url.PathEscape(traceQueryReq.TraceID), &traces); err != nil {
+		return nil, err
+	}
+	var rs []*model.Trace
+	for _, trace := range traces {
+		rs = append(rs, toTraces(trace)...)
+	}
+	return model.NewFramesFromTraces(rs), nil
+}
+
+// MetadataQuery queries service/operation list.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
+		return nil, err
+	}
+	var path string
+	switch metadataQueryReq.Type {
+	case Services:
+		path = "/api/services"
+	case Operations:
+		if metadataQueryReq.Service == "" {
+			return nil, fmt.Errorf("service is required")
+		}
+		path = fmt.Sprintf("/api/services/%s/operations", url.PathEscape(metadataQueryReq.Service))
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+	rs := []string{}
+	if err := cli.get(ctx, path, &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// CheckHealth checks if Jaeger query API answers.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	var rs []string
+	return cli.get(ctx, "/api/services", &rs)
+}
+
+// Close closes the idle connections of http client.
+func (cli *client) Close() error {
+	cli.httpCli.CloseIdleConnections()
+	return nil
+}
+
+// get sends get request to Jaeger query API, then unmarshals the data of response.
+func (cli *client) get(ctx context.Context, path string, data any) error {
+	httpReq, err := newRequestFn(ctx, http.MethodGet, strings.TrimSuffix(cli.datasouce.URL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.httpCli.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := readAllFn(resp.Body)
+	if err != nil {
+		return err
+	}
+	rs := &response{}
+	if err := jsonUnmarshalFn(body, rs); err != nil {
+		return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	if len(rs.Errors) > 0 {
+		return fmt.Errorf("%d: %s", rs.Errors[0].Code, rs.Errors[0].Msg)
+	}
+	return jsonUnmarshalFn(rs.Data, data)
+}
+
+// toTraces converts Jaeger trace to traces grouped by process.
+func toTraces(trace *jaegerTrace) []*model.Trace {
+	var rs []*model.Trace
+	traces := make(map[string]*model.Trace)
+	for _, span := range trace.Spans {
+		t, ok := traces[span.ProcessID]
+		if !ok {
+			t = &model.Trace{}
+			if process, ok := trace.Processes[span.ProcessID]; ok {
+				t.Process = model.NewProcess(process.ServiceName, toTags(process.Tags))
+			} else {
+				t.Process = model.NewProcess("", nil)
+			}
+			traces[span.ProcessID] = t
+			rs = append(rs, t)
+		}
+		t.Spans = append(t.Spans, toSpan(span))
+	}
+	return rs
+}
+
+// toSpan converts Jaeger span(microseconds) to span(nanoseconds).
+func toSpan(span *jaegerSpan) *model.Span {
+	tags := toTags(span.Tags)
+	kind := model.SpanKindUnspecified
+	if v, ok := tags[spanKind].(string); ok {
+		kind = model.ParseSpanKind(v)
+		delete(tags, spanKind)
+	}
+	rs := &model.Span{
+		TraceID:   span.TraceID,
+		SpanID:    span.SpanID,
+		Name:      span.OperationName,
+		Kind:      kind,
+		StartTime: span.StartTime * int64(time.Microsecond),
+		EndTime:   (span.StartTime + span.Duration) * int64(time.Microsecond),
+		Duration:  span.Duration * int64(time.Microsecond),
+		Tags:      tags,
+	}
+	// first CHILD_OF reference is parent, if not exist uses first reference(FOLLOWS_FROM)
+	parentIdx := -1
+	for idx, ref := range span.References {
+		if ref.RefType == childOf && ref.TraceID == span.TraceID {
+			parentIdx = idx
+			break
+		}
+	}
+	if parentIdx < 0 && len(span.References) > 0 && span.References[0].TraceID == span.TraceID {
+		parentIdx = 0
+	}
+	for idx, ref := range span.References {
+		if idx == parentIdx {
+			rs.ParentSpanID = ref.SpanID
+			continue
+		}
+		rs.Links = append(rs.Links, &model.SpanLink{
+			TraceID: ref.TraceID,
+			SpanID:  ref.SpanID,
+			Tags:    map[string]any{"refType": ref.RefType},
+		})
+	}
+	for _, log := range span.Logs {
+		fields := toTags(log.Fields)
+		name := defaultLog
+		if v, ok := fields[logEvent].(string); ok {
+			name = v
+			delete(fields, logEvent)
+		}
+		rs.Events = append(rs.Events, &model.SpanEvent{
+			Name:      name,
+			Timestamp: log.Timestamp * int64(time.Microsecond),
+			Tags:      fields,
+		})
+	}
+	return rs
+}
+
+// toTags converts key/value list to map.
+func toTags(kvs []*keyValue) map[string]any {
+	rs := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		rs[kv.Key] = kv.Value
+	}
+	return rs
+}
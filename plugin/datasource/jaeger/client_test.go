@@ -0,0 +1,292 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+)
+
+const traceResp = `{"data":[{"traceID":"t1","spans":[` +
+	`{"traceID":"t1","spanID":"s1","operationName":"GET /order","references":[],"startTime":1680000000000000,"duration":2000,` +
+	`"tags":[{"key":"span.kind","type":"string","value":"server"},{"key":"http.status_code","type":"int64","value":200}],` +
+	`"logs":[],"processID":"p1"},` +
+	`{"traceID":"t1","spanID":"s2","operationName":"SELECT","references":[{"refType":"CHILD_OF","traceID":"t1","spanID":"s1"},` +
+	`{"refType":"FOLLOWS_FROM","traceID":"t0","spanID":"s0"}],"startTime":1680000000000500,"duration":1000,` +
+	`"tags":[{"key":"span.kind","type":"string","value":"client"}],` +
+	`"logs":[{"timestamp":1680000000000600,"fields":[{"key":"event","type":"string","value":"retry"},{"key":"attempt","type":"int64","value":1}]}],` +
+	`"processID":"p2"}],` +
+	`"processes":{"p1":{"serviceName":"order","tags":[{"key":"hostname","type":"string","value":"host-1"}]},` +
+	`"p2":{"serviceName":"db","tags":[]}}}],"total":0,"limit":0,"offset":0,"errors":null}`
+
+// newJaegerServer creates a stand-in server that speaks the Jaeger query API.
+func newJaegerServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/traces/t1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(traceResp))
+	})
+	mux.HandleFunc("/api/traces/not_found", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"code":404,"msg":"trace not found"}]}`))
+	})
+	mux.HandleFunc("/api/services", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":["db","order"],"errors":null}`))
+	})
+	mux.HandleFunc("/api/services/order/operations", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":["GET /order"],"errors":null}`))
+	})
+	mux.HandleFunc("/api/bad", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`bad`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_DataQuery(t *testing.T) {
+	server := newJaegerServer()
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "trace id is empty",
+			req:  `{}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "new http request failure",
+			req:  `{"traceId":"t1"}`,
+			prepare: func() {
+				newRequestFn = func(_ context.Context, _, _ string, _ io.Reader) (*http.Request, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "read resp body failure",
+			req:  `{"traceId":"t1"}`,
+			prepare: func() {
+				readAllFn = func(_ io.Reader) ([]byte, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "trace not found",
+			req:  `{"traceId":"not_found"}`,
+			assert: func(_ any, err error) {
+				assert.EqualError(t, err, "404: trace not found")
+			},
+		},
+		{
+			name: "get trace successfully",
+			req:  `{"traceId":"t1"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frames := rs.(model.Frames)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, "order", frames[0].Name)
+				assert.Equal(t, model.FrameTypeTrace, frames[0].Meta.Type)
+				assert.Equal(t, "host-1", frames[0].Meta.Custom[model.ProcessCustomKey].(*model.Process).InstanceID)
+				assert.Equal(t, []any{"s1"}, frames[0].Fields[2].Values)
+				assert.Equal(t, "db", frames[1].Name)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+				newRequestFn = http.NewRequestWithContext
+				readAllFn = io.ReadAll
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, model.TimeRange{})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	server := newJaegerServer()
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metadata type not support",
+			req:  `{"type":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "service list",
+			req:  `{"type":"services"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"db", "order"}, rs)
+			},
+		},
+		{
+			name: "operation list without service",
+			req:  `{"type":"operations"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "operation list",
+			req:  `{"type":"operations","service":"order"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"GET /order"}, rs)
+			},
+		},
+		{
+			name: "unexpected response",
+			req:  `{"type":"operations","service":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := newJaegerServer()
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.CheckHealth(context.TODO()))
+
+	cli, err = NewClient(&model.Datasource{URL: server.URL + "/not_found"}, nil)
+	assert.NoError(t, err)
+	assert.Error(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
+
+func TestToTraces(t *testing.T) {
+	resp := &response{}
+	assert.NoError(t, json.Unmarshal([]byte(traceResp), resp))
+	var jaegerTraces []*jaegerTrace
+	assert.NoError(t, json.Unmarshal(resp.Data, &jaegerTraces))
+	traces := toTraces(jaegerTraces[0])
+	assert.Len(t, traces, 2)
+	assert.Equal(t, "order", traces[0].Process.ServiceName)
+	assert.Equal(t, "host-1", traces[0].Process.InstanceID)
+	assert.Equal(t, &model.Span{
+		TraceID:   "t1",
+		SpanID:    "s1",
+		Name:      "GET /order",
+		Kind:      model.SpanKindServer,
+		StartTime: 1680000000000000000,
+		EndTime:   1680000000002000000,
+		Duration:  2000000,
+		Tags:      map[string]any{"http.status_code": float64(200)},
+	}, traces[0].Spans[0])
+	assert.Equal(t, "db", traces[1].Process.ServiceName)
+	span := traces[1].Spans[0]
+	assert.Equal(t, "s1", span.ParentSpanID)
+	assert.Equal(t, model.SpanKindClient, span.Kind)
+	assert.Equal(t, []*model.SpanLink{{TraceID: "t0", SpanID: "s0", Tags: map[string]any{"refType": "FOLLOWS_FROM"}}}, span.Links)
+	assert.Equal(t, []*model.SpanEvent{{
+		Name: "retry", Timestamp: 1680000000000600000, Tags: map[string]any{"attempt": float64(1)},
+	}}, span.Events)
+}
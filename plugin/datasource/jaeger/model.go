@@ -0,0 +1,90 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jaeger
+
+import "encoding/json"
+
+// MetadataType represents metadata type for Jaeger.
+type MetadataType = string
+
+// Defines all Jaeger's metadata types.
+var (
+	Services   MetadataType = "services"
+	Operations              = "operations"
+)
+
+// GetTraceRequest represents get trace data request params.
+type GetTraceRequest struct {
+	TraceID string `json:"traceId"`
+}
+
+// MetadataQueryRequest represents metadata query request for Jaeger.
+type MetadataQueryRequest struct {
+	Type    MetadataType `json:"type"`
+	Service string       `json:"service"`
+}
+
+// response represents the response envelope of Jaeger query API.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+// jaegerTrace represents the trace of Jaeger query API(microseconds).
+type jaegerTrace struct {
+	TraceID   string                    `json:"traceID"`
+	Spans     []*jaegerSpan             `json:"spans"`
+	Processes map[string]*jaegerProcess `json:"processes"`
+}
+
+type jaegerSpan struct {
+	TraceID       string             `json:"traceID"`
+	SpanID        string             `json:"spanID"`
+	OperationName string             `json:"operationName"`
+	References    []*jaegerReference `json:"references"`
+	StartTime     int64              `json:"startTime"`
+	Duration      int64              `json:"duration"`
+	Tags          []*keyValue        `json:"tags"`
+	Logs          []*jaegerLog       `json:"logs"`
+	ProcessID     string             `json:"processID"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerLog struct {
+	Timestamp int64       `json:"timestamp"`
+	Fields    []*keyValue `json:"fields"`
+}
+
+type jaegerProcess struct {
+	ServiceName string      `json:"serviceName"`
+	Tags        []*keyValue `json:"tags"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
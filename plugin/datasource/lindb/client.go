@@ -35,6 +35,7 @@ package lindb
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	lincli "github.com/lindb/client_go"
@@ -43,6 +44,8 @@ import (
 	"github.com/lindb/common/pkg/timeutil"
 
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/pkg/interval"
 	"github.com/lindb/linsight/plugin"
 )
 
@@ -84,33 +87,105 @@ func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.Data
 		}
 	}
 
+	options, err := newClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &client{
 		cfg:       config,
 		datasouce: datasource,
 		location:  location,
-		client:    lincli.NewClient(datasource.URL),
+		client:    lincli.NewClientWithOptions(datasource.URL, options),
 		logger:    logger.GetLogger("DatasourcePlugin", "LinDB"),
 	}, nil
 }
 
-// DataQuery queries metric data.
-func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (any, error) {
+// newClientOptions creates LinDB client options by the http client options of datasource config,
+// LinDB client only supports tls and request timeout.
+func newClientOptions(cfg json.RawMessage) (*lincli.Options, error) {
+	httpOptions, err := plugin.GetHTTPClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if httpOptions.HasAuthOrHeaders() {
+		return nil, fmt.Errorf("authentication and custom headers are not supported by LinDB datasource")
+	}
+	options := lincli.DefaultOptions()
+	timeout, err := httpOptions.RequestTimeout()
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		// LinDB client's timeout is seconds
+		options.SetReqTimeout(int64((timeout + time.Second - 1) / time.Second))
+	}
+	tlsConfig, err := httpOptions.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		options.SetTLSConfig(tlsConfig)
+	}
+	return options, nil
+}
+
+// DataQuery queries metric data, returns one time series frame per series.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
 	data, _ := req.Request.MarshalJSON()
 	dataQueryReq := &DataQueryRequest{}
 	if err := jsonUnmarshalFn(data, &dataQueryReq); err != nil {
 		return nil, err
 	}
+	if dataQueryReq.Interval == "" && !dataQueryReq.Stats && req.IntervalMs > 0 {
+		// use the interval calculated by server, avoid returning too many points,
+		// stats query aggregates whole time range if interval not set
+		dataQueryReq.Interval = interval.Format(req.IntervalMs)
+	}
+	if maxSeries := plugin.GetQueryLimits(ctx).MaxSeries; maxSeries > 0 &&
+		(dataQueryReq.Limit <= 0 || dataQueryReq.Limit > maxSeries) {
+		// returns one more series than limit, so exceeding limit can be detected
+		dataQueryReq.Limit = maxSeries + 1
+	}
 	sql, err := buildDataQuerySQLFn(dataQueryReq, cli.formatTime(timeRange.From), cli.formatTime(timeRange.To))
 	if err != nil {
 		return nil, err
 	}
+	plugin.RecordExecutedQuery(ctx, sql)
 	query := cli.client.DataQuery()
 	rs, err := query.DataQuery(ctx, cli.cfg.Database, sql)
 	if err != nil {
 		return nil, err
 	}
 	cli.logger.Info("data query", logger.String("database", cli.cfg.Database), logger.String("sql", sql))
-	return rs, nil
+	frames := model.NewFramesFromResultSet(rs)
+	for _, frame := range frames {
+		frame.Meta.ExecutedQuery = sql
+	}
+	return frames, nil
+}
+
+// InterpolateVariables expands the variables of raw LinQL, the values are quoted by single quote by default
+// and escaped as string literal for other formats except raw, other fields of request are kept.
+func (cli *client) InterpolateVariables(req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error) {
+	request := make(map[string]json.RawMessage)
+	if err := jsonUnmarshalFn(req, &request); err != nil {
+		return nil, err
+	}
+	raw, ok := request["sql"]
+	if !ok {
+		return req, nil
+	}
+	var sql string
+	if err := jsonUnmarshalFn(raw, &sql); err != nil {
+		return nil, err
+	}
+	sql, err := vars.InterpolateWithEscaper(sql, interpolate.SingleQuote, quoteReplacer.Replace)
+	if err != nil {
+		return nil, err
+	}
+	request["sql"], _ = json.Marshal(sql)
+	return json.Marshal(request)
 }
 
 // MetadataQuery queries metric metadata.
@@ -134,6 +209,31 @@ func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, er
 	return rs, nil
 }
 
+// CheckHealth pings broker by showing databases, then checks if database exists.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	if cli.cfg.Database == "" {
+		return fmt.Errorf("database is required")
+	}
+	query := cli.client.DataQuery()
+	rs, err := query.MetadataQuery(ctx, "", "show databases")
+	if err != nil {
+		return err
+	}
+	if databases, ok := rs.Values.([]any); ok {
+		for _, database := range databases {
+			if database == cli.cfg.Database {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("database not found: %s", cli.cfg.Database)
+}
+
+// Close does nothing, because LinDB client creates http client per query.
+func (cli *client) Close() error {
+	return nil
+}
+
 func (cli *client) formatTime(timestamp int64) string {
 	if timestamp <= 0 {
 		return ""
@@ -25,12 +25,15 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lindb/common/models"
 	"github.com/lindb/common/pkg/encoding"
 	"github.com/lindb/common/pkg/logger"
 	"github.com/lindb/common/pkg/timeutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/plugin"
 	"github.com/lindb/linsight/plugin/datasource/lindb/lincli"
 )
 
@@ -67,6 +70,45 @@ func TestClient_NewClient(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, cli)
 	})
+	t.Run("invalid http options", func(t *testing.T) {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		cli, err := NewClient(&model.Datasource{}, []byte(`{"http":{"timeout":"a"}}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+}
+
+func TestClient_newClientOptions(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     string
+		timeout int64
+		tls     bool
+		wantErr bool
+	}{
+		{name: "invalid config", cfg: `{"http":1}`, wantErr: true},
+		{name: "auth not support", cfg: `{"http":{"bearerToken":"token"}}`, wantErr: true},
+		{name: "headers not support", cfg: `{"http":{"headers":{"X-Org":"1"}}}`, wantErr: true},
+		{name: "invalid timeout", cfg: `{"http":{"timeout":"a"}}`, wantErr: true},
+		{name: "invalid tls", cfg: `{"http":{"tls":{"caCert":"a"}}}`, wantErr: true},
+		{name: "default options", cfg: `{}`, timeout: 30},
+		{name: "timeout rounds up to seconds", cfg: `{"http":{"timeout":"1500ms"}}`, timeout: 2},
+		{name: "tls", cfg: `{"http":{"tls":{"insecureSkipVerify":true}}}`, timeout: 30, tls: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := newClientOptions([]byte(tt.cfg))
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, options)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.timeout, options.HTTPOptions().ReqTimeout())
+			assert.Equal(t, tt.tls, options.HTTPOptions().TLSConfig() != nil)
+		})
+	}
 }
 
 func TestClient_DataQuery(t *testing.T) {
@@ -155,6 +197,84 @@ func TestClient_DataQuery(t *testing.T) {
 	}
 }
 
+func TestClient_DataQuery_Interval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCli := lincli.NewMockClient(ctrl)
+	dq := lincli.NewMockDataQuery(ctrl)
+	mockCli.EXPECT().DataQuery().Return(dq).AnyTimes()
+	cli := &client{
+		cfg:      &DatasourceConfig{},
+		location: time.Local,
+		logger:   logger.GetLogger("Test", "LinDB"),
+		client:   mockCli,
+	}
+	cases := []struct {
+		name    string
+		request string
+		limits  plugin.QueryLimits
+		sql     string
+	}{
+		{
+			name:    "limit series by max series",
+			request: `{"metric":"cpu","fields":["usage"],"groupBy":["host"],"limit":100}`,
+			limits:  plugin.QueryLimits{MaxSeries: 10},
+			sql:     "SELECT usage FROM 'cpu' GROUP BY host,time(1h) LIMIT 11",
+		},
+		{
+			name:    "limit of request less than max series",
+			request: `{"metric":"cpu","fields":["usage"],"groupBy":["host"],"limit":5}`,
+			limits:  plugin.QueryLimits{MaxSeries: 10},
+			sql:     "SELECT usage FROM 'cpu' GROUP BY host,time(1h) LIMIT 5",
+		},
+		{
+			name:    "raw sql not limited",
+			request: `{"sql":"select usage from cpu"}`,
+			limits:  plugin.QueryLimits{MaxSeries: 10},
+			sql:     "select usage from cpu",
+		},
+		{
+			name:    "use calculated interval",
+			request: `{"metric":"cpu","fields":["usage"]}`,
+			sql:     "SELECT usage FROM 'cpu' GROUP BY time(1h)",
+		},
+		{
+			name:    "stats without interval",
+			request: `{"metric":"cpu","fields":["usage"],"stats":true}`,
+			sql:     "SELECT usage FROM 'cpu' GROUP BY time()",
+		},
+		{
+			name:    "stats with interval",
+			request: `{"metric":"cpu","fields":["usage"],"stats":true,"interval":"1m"}`,
+			sql:     "SELECT usage FROM 'cpu' GROUP BY time(1m)",
+		},
+		{
+			name:    "interval of request",
+			request: `{"metric":"cpu","fields":["usage"],"interval":"1m"}`,
+			sql:     "SELECT usage FROM 'cpu' GROUP BY time(1m)",
+		},
+		{
+			name:    "raw sql",
+			request: `{"sql":"select usage from cpu"}`,
+			sql:     "select usage from cpu",
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dq.EXPECT().DataQuery(gomock.Any(), gomock.Any(), tt.sql).Return(nil, nil)
+			ctx, executedQueries := plugin.WithExecutedQueries(plugin.WithQueryLimits(context.TODO(), tt.limits))
+			_, err := cli.DataQuery(ctx, &model.Query{
+				Request:    json.RawMessage(tt.request),
+				IntervalMs: time.Hour.Milliseconds(),
+			}, model.TimeRange{})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tt.sql}, executedQueries.Queries())
+		})
+	}
+}
+
 func TestClient_MetadataQuery(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -239,3 +359,99 @@ func TestClient_MetadataQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_CheckHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCli := lincli.NewMockClient(ctrl)
+	dq := lincli.NewMockDataQuery(ctrl)
+	mockCli.EXPECT().DataQuery().Return(dq).AnyTimes()
+
+	cases := []struct {
+		name     string
+		database string
+		prepare  func()
+		wantErr  bool
+	}{
+		{
+			name:    "database is empty",
+			wantErr: true,
+		},
+		{
+			name:     "ping broker failure",
+			database: "_internal",
+			prepare: func() {
+				dq.EXPECT().MetadataQuery(gomock.Any(), "", "show databases").Return(nil, fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name:     "database not found",
+			database: "_internal",
+			prepare: func() {
+				dq.EXPECT().MetadataQuery(gomock.Any(), "", "show databases").
+					Return(&models.Metadata{Values: []any{"lindb"}}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "database exists",
+			database: "_internal",
+			prepare: func() {
+				dq.EXPECT().MetadataQuery(gomock.Any(), "", "show databases").
+					Return(&models.Metadata{Values: []any{"lindb", "_internal"}}, nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			cli := &client{
+				cfg:    &DatasourceConfig{Database: tt.database},
+				logger: logger.GetLogger("Test", "LinDB"),
+				client: mockCli,
+			}
+			err := cli.CheckHealth(context.TODO())
+			if tt.wantErr != (err != nil) {
+				t.Fatal(tt.name)
+			}
+		})
+	}
+}
+
+func TestClient_InterpolateVariables(t *testing.T) {
+	p, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	cli := p.(plugin.VariableInterpolator)
+	vars, err := interpolate.NewVariables(map[string]any{"host": []any{"a", "b'c"}})
+	assert.NoError(t, err)
+	vars.SetBuiltin(interpolate.Interval, "10s")
+
+	// builder mode, request not changed
+	rs, err := cli.InterpolateVariables(json.RawMessage(`{"metric":"cpu","where":[{"key":"host","value":"$host"}]}`), vars)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"metric":"cpu","where":[{"key":"host","value":"$host"}]}`, string(rs))
+	// raw mode
+	rs, err = cli.InterpolateVariables(json.RawMessage(
+		`{"sql":"select usage from cpu where host in ($host) and ip =~ '${host:regex}' group by time($__interval)","limit":10}`), vars)
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"sql":"select usage from cpu where host in ('a','b\\'c') and ip =~ '(a|b\\'c)' group by time(10s)","limit":10}`, string(rs))
+	// failure
+	for _, req := range []string{`{`, `{"sql":1}`, `{"sql":"${host:unknown}"}`} {
+		rs, err = cli.InterpolateVariables(json.RawMessage(req), vars)
+		assert.Error(t, err)
+		assert.Nil(t, rs)
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
@@ -20,20 +20,39 @@ package lindb
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+// intervalPattern matches the interval of time bucket, like 10s/1m/1h/1d.
+var intervalPattern = regexp.MustCompile(`^\d+(ms|s|m|h|d)$`)
+
+// selectPattern matches the select statement of LinQL.
+var selectPattern = regexp.MustCompile(`(?i)^select\s`)
+
 // buildDataQuerySQL builds LinDB query language based on data query request and time range.
+// If raw LinQL is set, returns it directly, only select statement is allowed.
 func buildDataQuerySQL(req *DataQueryRequest, from, to string) (string, error) {
+	if sql := strings.TrimSpace(req.SQL); sql != "" {
+		if !selectPattern.MatchString(sql) {
+			return "", fmt.Errorf("raw LinQL must be select statement: %s", sql)
+		}
+		return sql, nil
+	}
 	groupBy := req.GroupBy
-	if req.Stats {
+	if req.Stats && req.Interval == "" {
 		groupBy = append(groupBy, "time()")
 	}
 	builder := New().Select(req.Fields...).
 		Metric(req.Metric).
 		Namespace(req.Namespace).
 		Where(req.Where...).
-		GroupBy(groupBy...)
+		GroupBy(groupBy...).
+		Interval(req.Interval).
+		Having(req.Having...).
+		OrderBy(req.OrderBy...).
+		Limit(req.Limit)
 
 	builder.TimeRange(from, to)
 	sql, err := builder.ToSQL()
@@ -50,6 +69,10 @@ type DataQueryBuilder struct {
 	namespace string
 	groupBy   []string
 	where     []Expr
+	interval  string
+	having    []HavingExpr
+	orderBy   []OrderBy
+	limit     int
 }
 
 // New creates a builder.
@@ -78,7 +101,7 @@ func (b *DataQueryBuilder) Namespace(namespace string) *DataQueryBuilder {
 // Where sets where conditions.
 func (b *DataQueryBuilder) Where(where ...Expr) *DataQueryBuilder {
 	for _, e := range where {
-		if e.Key == "" || e.Op == "" {
+		if e.isEmpty() {
 			// ignore key/op empty
 			continue
 		}
@@ -93,6 +116,30 @@ func (b *DataQueryBuilder) GroupBy(groupBy ...string) *DataQueryBuilder {
 	return b
 }
 
+// Interval sets the interval of time bucket, group by time(interval).
+func (b *DataQueryBuilder) Interval(interval string) *DataQueryBuilder {
+	b.interval = interval
+	return b
+}
+
+// Having sets the conditions on aggregated value of field.
+func (b *DataQueryBuilder) Having(having ...HavingExpr) *DataQueryBuilder {
+	b.having = append(b.having, having...)
+	return b
+}
+
+// OrderBy sets the sort fields.
+func (b *DataQueryBuilder) OrderBy(orderBy ...OrderBy) *DataQueryBuilder {
+	b.orderBy = append(b.orderBy, orderBy...)
+	return b
+}
+
+// Limit sets the max number of series, no limit if 0.
+func (b *DataQueryBuilder) Limit(limit int) *DataQueryBuilder {
+	b.limit = limit
+	return b
+}
+
 // TimeRange sets time range.
 func (b *DataQueryBuilder) TimeRange(from, to string) *DataQueryBuilder {
 	b.setTimeCondition(from, GtEq)
@@ -116,39 +163,74 @@ func (b *DataQueryBuilder) ToSQL() (sql string, err error) {
 
 	sqlBuf.WriteString(strings.Join(b.fields, ","))
 	sqlBuf.WriteString(" FROM ")
-	fmt.Fprintf(sqlBuf, "'%s'", b.metric)
+	sqlBuf.WriteString(quote(b.metric))
 
 	if len(b.namespace) > 0 {
 		sqlBuf.WriteString(" ON ")
-		fmt.Fprintf(sqlBuf, "'%s'", b.namespace)
+		sqlBuf.WriteString(quote(b.namespace))
 	}
 
 	if len(b.where) > 0 {
+		where, err0 := joinConditions(b.where, And, false)
+		if err0 != nil {
+			return "", err0
+		}
 		sqlBuf.WriteString(" WHERE ")
-		sqlBuf.WriteString(b.joinWhere(" AND "))
+		sqlBuf.WriteString(where)
 	}
 
-	if len(b.groupBy) > 0 {
+	groupBy := b.groupBy
+	if b.interval != "" {
+		if !intervalPattern.MatchString(b.interval) {
+			return "", fmt.Errorf("invalid interval: %s", b.interval)
+		}
+		groupBy = append(groupBy, fmt.Sprintf("time(%s)", b.interval))
+	}
+	if len(groupBy) > 0 {
 		sqlBuf.WriteString(" GROUP BY ")
-		sqlBuf.WriteString(strings.Join(b.groupBy, ","))
+		sqlBuf.WriteString(strings.Join(groupBy, ","))
 	}
 
-	return sqlBuf.String(), nil
-}
+	if len(b.having) > 0 {
+		having := make([]string, 0, len(b.having))
+		for _, h := range b.having {
+			switch h.Op {
+			case Eq, NotEq, Gt, GtEq, Lt, LtEq:
+			default:
+				return "", fmt.Errorf("operator of having not support: %s", h.Op)
+			}
+			if h.Field == "" {
+				return "", fmt.Errorf("field of having is required")
+			}
+			having = append(having, fmt.Sprintf("%s %s %s", h.Field, h.Op, strconv.FormatFloat(h.Value, 'f', -1, 64)))
+		}
+		sqlBuf.WriteString(" HAVING ")
+		sqlBuf.WriteString(strings.Join(having, " AND "))
+	}
 
-// joinWhere builds where conditions.
-func (b *DataQueryBuilder) joinWhere(sep string) string {
-	if len(b.where) == 1 {
-		return b.where[0].String()
+	if len(b.orderBy) > 0 {
+		orderBy := make([]string, 0, len(b.orderBy))
+		for _, o := range b.orderBy {
+			if o.Field == "" {
+				return "", fmt.Errorf("field of order by is required")
+			}
+			if o.Desc {
+				orderBy = append(orderBy, o.Field+" DESC")
+			} else {
+				orderBy = append(orderBy, o.Field)
+			}
+		}
+		sqlBuf.WriteString(" ORDER BY ")
+		sqlBuf.WriteString(strings.Join(orderBy, ","))
 	}
 
-	buffer := &bytes.Buffer{}
-	fmt.Fprintf(buffer, "%s", b.where[0].String())
-	for i := 1; i < len(b.where); i++ {
-		buffer.WriteString(sep)
-		fmt.Fprintf(buffer, "%s", b.where[i].String())
+	if b.limit < 0 {
+		return "", fmt.Errorf("limit must be positive: %d", b.limit)
+	}
+	if b.limit > 0 {
+		fmt.Fprintf(sqlBuf, " LIMIT %d", b.limit)
 	}
-	return buffer.String()
+	return sqlBuf.String(), nil
 }
 
 // setTimeCondition set timestamp condition
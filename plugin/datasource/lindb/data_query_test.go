@@ -53,9 +53,92 @@ func TestDataQuery_buildSQL(t *testing.T) {
 	}, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, "SELECT load,usage FROM 'system.host.cpu' WHERE key = 'value'", sql)
+
+	// host in (a, b) or region not like 'test-*'
+	sql, err = buildDataQuerySQL(&DataQueryRequest{
+		Where: []Expr{
+			{Logic: Or, Conditions: []Expr{
+				{Key: "host", Op: In, Value: []any{"a", "b"}},
+				{Key: "region", Op: Like, Value: "test-*", Not: true},
+			}},
+			{Key: "ip", Op: Regexp, Value: "^10\\."},
+		},
+		Metric: "system.host.cpu",
+		Fields: []string{"load"},
+	}, "now()-1h", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT load FROM 'system.host.cpu' WHERE (host in ( 'a','b' ) OR region not like 'test-*') "+
+		"AND ip =~ '^10\\\\.' AND time >= 'now()-1h'", sql)
+
+	// escape metric/namespace
+	sql, err = buildDataQuerySQL(&DataQueryRequest{
+		Metric:    "cpu'",
+		Namespace: "ns'",
+		Fields:    []string{"load"},
+	}, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT load FROM 'cpu\\'' ON 'ns\\''", sql)
+}
+
+func TestDataQuery_buildSQL_TopN(t *testing.T) {
+	sql, err := buildDataQuerySQL(&DataQueryRequest{
+		Stats:    true,
+		Metric:   "system.host.cpu",
+		Fields:   []string{"max(usage)"},
+		GroupBy:  []string{"host"},
+		Interval: "1m",
+		Having:   []HavingExpr{{Field: "max(usage)", Op: Gt, Value: 80.5}, {Field: "max(usage)", Op: LtEq, Value: 100}},
+		OrderBy:  []OrderBy{{Field: "max(usage)", Desc: true}, {Field: "host"}},
+		Limit:    10,
+	}, "now()-1h", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT max(usage) FROM 'system.host.cpu' WHERE time >= 'now()-1h' GROUP BY host,time(1m) "+
+		"HAVING max(usage) > 80.5 AND max(usage) <= 100 ORDER BY max(usage) DESC,host LIMIT 10", sql)
+
+	sql, err = buildDataQuerySQL(&DataQueryRequest{
+		Metric:   "system.host.cpu",
+		Fields:   []string{"usage"},
+		Interval: "30s",
+	}, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT usage FROM 'system.host.cpu' GROUP BY time(30s)", sql)
+}
+
+func TestDataQuery_buildSQL_Raw(t *testing.T) {
+	sql, err := buildDataQuerySQL(&DataQueryRequest{
+		Metric: "system.host.cpu",
+		Fields: []string{"usage"},
+		SQL:    " select max(usage) from 'system.host.cpu' group by host ",
+	}, "now()-1h", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "select max(usage) from 'system.host.cpu' group by host", sql)
+
+	for _, raw := range []string{"show databases", "drop database db", "selected", "explain select usage from cpu"} {
+		sql, err = buildDataQuerySQL(&DataQueryRequest{SQL: raw}, "", "")
+		assert.Error(t, err)
+		assert.Empty(t, sql)
+	}
+	sql, err = buildDataQuerySQL(&DataQueryRequest{SQL: "SELECT\nusage FROM cpu"}, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT\nusage FROM cpu", sql)
 }
 
 func TestDataQuery_buildSQL_Failure(t *testing.T) {
+	for _, req := range []*DataQueryRequest{
+		{Interval: "1 minute"},
+		{Interval: "1m) ; drop"},
+		{Having: []HavingExpr{{Field: "usage", Op: Like, Value: 1}}},
+		{Having: []HavingExpr{{Op: Gt, Value: 1}}},
+		{OrderBy: []OrderBy{{Desc: true}}},
+		{Limit: -1},
+	} {
+		req.Metric = "system.host.cpu"
+		req.Fields = []string{"usage"}
+		sql, err := buildDataQuerySQL(req, "", "")
+		assert.Error(t, err)
+		assert.Empty(t, sql)
+	}
+
 	sql, err := buildDataQuerySQL(&DataQueryRequest{
 		Metric: "system.host.cpu",
 	}, "from", "2022-04-03 02:34:33")
@@ -67,4 +150,12 @@ func TestDataQuery_buildSQL_Failure(t *testing.T) {
 	}, "from", "2022-04-03 02:34:33")
 	assert.Error(t, err)
 	assert.Empty(t, sql)
+
+	sql, err = buildDataQuerySQL(&DataQueryRequest{
+		Metric: "system.host.cpu",
+		Fields: []string{"load", "usage"},
+		Where:  []Expr{{Key: "host", Op: "unknown", Value: "a"}},
+	}, "from", "2022-04-03 02:34:33")
+	assert.Error(t, err)
+	assert.Empty(t, sql)
 }
@@ -58,7 +58,7 @@ func (b *MetadataQueryBuilder) Namespace(namespace string) *MetadataQueryBuilder
 // Where sets where conditions.
 func (b *MetadataQueryBuilder) Where(where ...Expr) *MetadataQueryBuilder {
 	for _, e := range where {
-		if e.Key == "" || e.Op == "" {
+		if e.isEmpty() {
 			// ignore key/op empty
 			continue
 		}
@@ -90,24 +90,28 @@ func (b *MetadataQueryBuilder) ToSQL() (sql string, err error) {
 	case Field:
 		sqlBuf.WriteString("SHOW FIELDS")
 		sqlBuf.WriteString(" FROM ")
-		fmt.Fprintf(sqlBuf, "'%s'", b.metric)
+		sqlBuf.WriteString(quote(b.metric))
 		b.buildNamespace(sqlBuf)
 	case TagKey:
 		sqlBuf.WriteString("SHOW TAG KEYS")
 		sqlBuf.WriteString(" FROM ")
-		fmt.Fprintf(sqlBuf, "'%s'", b.metric)
+		sqlBuf.WriteString(quote(b.metric))
 		b.buildNamespace(sqlBuf)
 	case TagValue:
 		sqlBuf.WriteString("SHOW TAG VALUES")
 		sqlBuf.WriteString(" FROM ")
-		fmt.Fprintf(sqlBuf, "'%s'", b.metric)
+		sqlBuf.WriteString(quote(b.metric))
 		b.buildNamespace(sqlBuf)
 		sqlBuf.WriteString(" WITH KEY =")
-		fmt.Fprintf(sqlBuf, " '%s'", b.tagKey)
+		sqlBuf.WriteString(" " + quote(b.tagKey))
 	}
 	if len(b.where) > 0 {
+		where, err0 := joinConditions(b.where, And, false)
+		if err0 != nil {
+			return "", err0
+		}
 		sqlBuf.WriteString(" WHERE ")
-		sqlBuf.WriteString(b.joinWhere(" AND "))
+		sqlBuf.WriteString(where)
 	}
 	return sqlBuf.String(), nil
 }
@@ -116,21 +120,6 @@ func (b *MetadataQueryBuilder) ToSQL() (sql string, err error) {
 func (b *MetadataQueryBuilder) buildNamespace(sqlBuf *bytes.Buffer) {
 	if len(b.namespace) > 0 {
 		sqlBuf.WriteString(" ON ")
-		fmt.Fprintf(sqlBuf, "'%s'", b.namespace)
-	}
-}
-
-// joinWhere builds where conditions.
-func (b *MetadataQueryBuilder) joinWhere(sep string) string {
-	if len(b.where) == 1 {
-		return b.where[0].String()
-	}
-
-	buffer := &bytes.Buffer{}
-	fmt.Fprintf(buffer, "%s", b.where[0].String())
-	for i := 1; i < len(b.where); i++ {
-		buffer.WriteString(sep)
-		fmt.Fprintf(buffer, "%s", b.where[i].String())
+		sqlBuf.WriteString(quote(b.namespace))
 	}
-	return buffer.String()
 }
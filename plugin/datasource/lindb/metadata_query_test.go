@@ -75,4 +75,25 @@ func TestMetadata_TagValue(t *testing.T) {
 	sql, err = buildMetadataQuerySQL(&MetadataQueryRequest{Type: TagValue, Metric: "cpu", TagKey: "ip", Namespace: "ns"})
 	assert.NoError(t, err)
 	assert.Equal(t, "SHOW TAG VALUES FROM 'cpu' ON 'ns' WITH KEY = 'ip'", sql)
+
+	sql, err = buildMetadataQuerySQL(&MetadataQueryRequest{
+		Type:   TagValue,
+		Metric: "cpu",
+		TagKey: "ip",
+		Where: []Expr{{Logic: Or, Conditions: []Expr{
+			{Key: "region", Op: Eq, Value: "bj"},
+			{Key: "region", Op: Eq, Value: "sh"},
+		}}, {Key: "host", Op: In, Value: []any{"a"}, Not: true}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SHOW TAG VALUES FROM 'cpu' WITH KEY = 'ip' WHERE (region = 'bj' OR region = 'sh') AND host not in ( 'a' )", sql)
+
+	sql, err = buildMetadataQuerySQL(&MetadataQueryRequest{
+		Type:   TagValue,
+		Metric: "cpu",
+		TagKey: "ip",
+		Where:  []Expr{{Key: "ip", Op: "unknown", Value: "1.1.*"}},
+	})
+	assert.Error(t, err)
+	assert.Empty(t, sql)
 }
@@ -39,11 +39,42 @@ var (
 type Operator = string
 
 var (
-	Eq   Operator = "="
-	In            = "in"
-	GtEq          = ">="
-	LtEq          = "<="
-	Like          = "like"
+	Eq        Operator = "="
+	NotEq              = "!="
+	In                 = "in"
+	NotIn              = "not in"
+	Gt                 = ">"
+	GtEq               = ">="
+	Lt                 = "<"
+	LtEq               = "<="
+	Like               = "like"
+	NotLike            = "not like"
+	Regexp             = "=~"
+	NotRegexp          = "!~"
+)
+
+// negatedOperators represents the operator which negates the condition of operator.
+var negatedOperators = map[Operator]Operator{
+	Eq:        NotEq,
+	NotEq:     Eq,
+	In:        NotIn,
+	NotIn:     In,
+	Like:      NotLike,
+	NotLike:   Like,
+	Regexp:    NotRegexp,
+	NotRegexp: Regexp,
+	Gt:        LtEq,
+	LtEq:      Gt,
+	Lt:        GtEq,
+	GtEq:      Lt,
+}
+
+// Logic represents the logical operator which joins conditions.
+type Logic = string
+
+var (
+	And Logic = "and"
+	Or  Logic = "or"
 )
 
 // DatasourceConfig represents datasource config for LinDB.
@@ -59,6 +90,29 @@ type DataQueryRequest struct {
 	GroupBy   []string `json:"groupBy"`
 	Where     []Expr   `json:"where"`
 	Stats     bool     `json:"stats"`
+	// Interval is the interval of time bucket(like 1m), group by time(interval) if set.
+	Interval string `json:"interval"`
+	// Having filters the series by aggregated value of field.
+	Having  []HavingExpr `json:"having"`
+	OrderBy []OrderBy    `json:"orderBy"`
+	// Limit is the max number of series returned.
+	Limit int `json:"limit"`
+	// SQL is the raw LinQL which is executed instead of the query built by fields above if set,
+	// variables of it are expanded by server.
+	SQL string `json:"sql"`
+}
+
+// HavingExpr represents the condition on aggregated value of field, like max(usage) > 80.
+type HavingExpr struct {
+	Field string   `json:"field"`
+	Op    Operator `json:"operator"`
+	Value float64  `json:"value"`
+}
+
+// OrderBy represents the sort field of series.
+type OrderBy struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
 }
 
 // MetadataQueryRequest represents metadata query request for LinDB.
@@ -70,38 +124,146 @@ type MetadataQueryRequest struct {
 	Where     []Expr       `json:"where"`
 }
 
-// Expr represents where condition express.
+// Expr represents where condition express, it is a group of conditions(in parentheses) if conditions not empty.
 type Expr struct {
 	Key   string   `json:"key"`
 	Op    Operator `json:"operator"`
 	Value any      `json:"value"`
-	raw   bool
+	// Not negates the condition.
+	Not bool `json:"not,omitempty"`
+	// Logic joins the conditions of group, default and.
+	Logic      Logic  `json:"logic,omitempty"`
+	Conditions []Expr `json:"conditions,omitempty"`
+	raw        bool
 }
 
-// String returns the string value of expr.
+// String returns the string value of expr, returns empty string if expr invalid.
 func (e Expr) String() string {
+	sql, _ := e.toSQL(false)
+	return sql
+}
+
+// isEmpty checks if expr has no condition(key/op empty and no sub conditions).
+func (e Expr) isEmpty() bool {
+	if len(e.Conditions) == 0 {
+		return e.Key == "" || e.Op == ""
+	}
+	for _, c := range e.Conditions {
+		if !c.isEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// toSQL builds the condition, negation is pushed down to leaf conditions(De Morgan's laws),
+// because LinQL only supports negation by operator.
+func (e Expr) toSQL(negate bool) (string, error) {
+	if e.Not {
+		negate = !negate
+	}
+	if len(e.Conditions) > 0 {
+		return joinConditions(e.Conditions, e.Logic, negate)
+	}
+	op := strings.ToLower(strings.TrimSpace(e.Op))
+	if negate {
+		negated, ok := negatedOperators[op]
+		if !ok {
+			return "", fmt.Errorf("operator cannot be negated: %s", e.Op)
+		}
+		op = negated
+	}
+	if strings.ContainsAny(e.Key, " \t\n'\"`(),;") {
+		return "", fmt.Errorf("invalid key: %s", e.Key)
+	}
 	buf := &bytes.Buffer{}
 	buf.WriteString(e.Key)
-	fmt.Fprintf(buf, " %s ", e.Op)
-	var value string
-	switch v := e.Value.(type) {
-	case string:
-		value = v
-	case []any:
-		var values []string
-		for _, val := range v {
-			values = append(values, fmt.Sprintf("'%s'", val))
+	fmt.Fprintf(buf, " %s ", op)
+	switch op {
+	case In, NotIn:
+		values, ok := e.Value.([]any)
+		if !ok {
+			values = []any{e.Value}
 		}
-		value = strings.Join(values, ",")
+		if len(values) == 0 {
+			return "", fmt.Errorf("values of '%s' are required, key: %s", op, e.Key)
+		}
+		quoted := make([]string, 0, len(values))
+		for _, val := range values {
+			quoted = append(quoted, quote(formatValue(val)))
+		}
+		fmt.Fprintf(buf, "( %s )", strings.Join(quoted, ","))
+	case Eq, NotEq, Gt, GtEq, Lt, LtEq, Like, NotLike, Regexp, NotRegexp:
+		if e.raw {
+			buf.WriteString(formatValue(e.Value))
+		} else {
+			buf.WriteString(quote(formatValue(e.Value)))
+		}
+	default:
+		return "", fmt.Errorf("operator not support: %s", e.Op)
+	}
+	return buf.String(), nil
+}
+
+// joinConditions joins conditions by logic(default and), the sub group is in parentheses, empty condition is ignored.
+func joinConditions(conditions []Expr, logic Logic, negate bool) (string, error) {
+	logic = strings.ToLower(strings.TrimSpace(logic))
+	switch logic {
+	case "":
+		logic = And
+	case And, Or:
 	default:
-		value = fmt.Sprintf("%v", v)
+		return "", fmt.Errorf("logic not support: %s", logic)
+	}
+	if negate {
+		// not (a and b) => not a or not b
+		if logic == And {
+			logic = Or
+		} else {
+			logic = And
+		}
+	}
+	var sqls []string
+	for _, c := range conditions {
+		if c.isEmpty() {
+			continue
+		}
+		sql, err := c.toSQL(negate)
+		if err != nil {
+			return "", err
+		}
+		if len(c.Conditions) > 0 && len(c.nonEmptyConditions()) > 1 {
+			sql = "(" + sql + ")"
+		}
+		sqls = append(sqls, sql)
+	}
+	return strings.Join(sqls, " "+strings.ToUpper(logic)+" "), nil
+}
+
+// nonEmptyConditions returns the sub conditions which are not empty.
+func (e Expr) nonEmptyConditions() (rs []Expr) {
+	for _, c := range e.Conditions {
+		if !c.isEmpty() {
+			rs = append(rs, c)
+		}
 	}
-	if e.Op == In {
-		fmt.Fprintf(buf, "( %s )", value)
-	} else if e.raw {
-		fmt.Fprintf(buf, "%s", value)
-	} else {
-		fmt.Fprintf(buf, "'%s'", value)
+	return rs
+}
+
+// formatValue returns the string value of condition value.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
 	}
-	return buf.String()
+}
+
+// quoteReplacer escapes backslash and single quote in string literal.
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// quote returns single-quoted string literal with escaping.
+func quote(s string) string {
+	return "'" + quoteReplacer.Replace(s) + "'"
 }
@@ -29,3 +29,97 @@ func TestExpr_String(t *testing.T) {
 	assert.Equal(t, "key = value", Expr{Key: "key", Op: Eq, Value: "value", raw: true}.String())
 	assert.Equal(t, "key in ( '123','abc' )", Expr{Key: "key", Op: In, Value: []any{"123", "abc"}}.String())
 }
+
+func TestExpr_toSQL(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    Expr
+		sql     string
+		wantErr bool
+	}{
+		{name: "not in", expr: Expr{Key: "host", Op: NotIn, Value: []any{"a", "b"}}, sql: "host not in ( 'a','b' )"},
+		{name: "in with single value", expr: Expr{Key: "host", Op: In, Value: "a"}, sql: "host in ( 'a' )"},
+		{name: "in without values", expr: Expr{Key: "host", Op: In, Value: []any{}}, wantErr: true},
+		{name: "regexp", expr: Expr{Key: "host", Op: Regexp, Value: "^web-\\d+$"}, sql: "host =~ '^web-\\\\d+$'"},
+		{name: "not regexp", expr: Expr{Key: "host", Op: NotRegexp, Value: "web"}, sql: "host !~ 'web'"},
+		{name: "escape value", expr: Expr{Key: "host", Op: Eq, Value: "a' or '1'='1"}, sql: "host = 'a\\' or \\'1\\'=\\'1'"},
+		{name: "escape in values", expr: Expr{Key: "host", Op: In, Value: []any{"a'b", 1}}, sql: "host in ( 'a\\'b','1' )"},
+		{name: "invalid key", expr: Expr{Key: "host = 'a' or host", Op: Eq, Value: "b"}, wantErr: true},
+		{name: "operator not support", expr: Expr{Key: "host", Op: "~", Value: "b"}, wantErr: true},
+		{name: "not eq", expr: Expr{Key: "host", Op: Eq, Value: "a", Not: true}, sql: "host != 'a'"},
+		{name: "not like", expr: Expr{Key: "host", Op: Like, Value: "a*", Not: true}, sql: "host not like 'a*'"},
+		{name: "not in by negation", expr: Expr{Key: "host", Op: In, Value: []any{"a"}, Not: true}, sql: "host not in ( 'a' )"},
+		{name: "double negation", expr: Expr{Key: "host", Op: NotIn, Value: []any{"a"}, Not: true}, sql: "host in ( 'a' )"},
+		{name: "not gt", expr: Expr{Key: "time", Op: Gt, Value: "now()", raw: true, Not: true}, sql: "time <= now()"},
+		{name: "not gt eq", expr: Expr{Key: "time", Op: GtEq, Value: "now()", raw: true, Not: true}, sql: "time < now()"},
+		{name: "not lt", expr: Expr{Key: "time", Op: Lt, Value: "now()", raw: true, Not: true}, sql: "time >= now()"},
+		{name: "not lt eq", expr: Expr{Key: "time", Op: LtEq, Value: "now()", raw: true, Not: true}, sql: "time > now()"},
+		{name: "cannot negate", expr: Expr{Key: "host", Op: "~", Value: "a", Not: true}, wantErr: true},
+		{
+			name: "or group",
+			expr: Expr{Logic: Or, Conditions: []Expr{
+				{Key: "host", Op: Eq, Value: "a"},
+				{},
+				{Key: "host", Op: Eq, Value: "b"},
+			}},
+			sql: "host = 'a' OR host = 'b'",
+		},
+		{
+			name: "nested group",
+			expr: Expr{Logic: "OR", Conditions: []Expr{
+				{Key: "host", Op: Eq, Value: "a"},
+				{Conditions: []Expr{
+					{Key: "region", Op: Eq, Value: "sh"},
+					{Key: "zone", Op: Like, Value: "test-*", Not: true},
+				}},
+				{Conditions: []Expr{{Key: "ip", Op: Eq, Value: "1.1.1.1"}}},
+			}},
+			sql: "host = 'a' OR (region = 'sh' AND zone not like 'test-*') OR ip = '1.1.1.1'",
+		},
+		{
+			name: "negate group",
+			expr: Expr{Not: true, Conditions: []Expr{
+				{Key: "host", Op: Eq, Value: "a"},
+				{Logic: Or, Conditions: []Expr{
+					{Key: "region", Op: Regexp, Value: "sh"},
+					{Key: "zone", Op: In, Value: []any{"a", "b"}},
+				}},
+			}},
+			sql: "host != 'a' OR (region !~ 'sh' AND zone not in ( 'a','b' ))",
+		},
+		{
+			name: "negate range group",
+			expr: Expr{Not: true, Conditions: []Expr{
+				{Key: "time", Op: GtEq, Value: "now()-1h", raw: true},
+				{Key: "time", Op: Lt, Value: "now()", raw: true},
+			}},
+			sql: "time < now()-1h OR time >= now()",
+		},
+		{name: "logic not support", expr: Expr{Logic: "xor", Conditions: []Expr{{Key: "host", Op: Eq, Value: "a"}}}, wantErr: true},
+		{
+			name:    "invalid sub condition",
+			expr:    Expr{Conditions: []Expr{{Key: "host", Op: "~", Value: "a"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := tt.expr.toSQL(false)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, tt.expr.String())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+		})
+	}
+}
+
+func TestExpr_isEmpty(t *testing.T) {
+	assert.True(t, Expr{}.isEmpty())
+	assert.True(t, Expr{Key: "host"}.isEmpty())
+	assert.True(t, Expr{Conditions: []Expr{{}, {Op: Eq}}}.isEmpty())
+	assert.False(t, Expr{Conditions: []Expr{{}, {Key: "host", Op: Eq}}}.isEmpty())
+}
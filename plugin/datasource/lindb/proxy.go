@@ -0,0 +1,83 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/lindb/linsight/plugin"
+)
+
+// maxExecBodySize represents the max size of LinQL execution request body.
+const maxExecBodySize = 1024 * 1024
+
+// readOnlyPattern matches the read only statement of LinQL(select/explain select).
+var readOnlyPattern = regexp.MustCompile(`(?i)^(explain\s+)?select\s`)
+
+// ProxyRoutes represents the LinDB apis which datasource proxy is allowed to forward, includes LinQL
+// execution(only select/explain statements) and cluster state, which need the edit permission of datasource.
+var ProxyRoutes = []plugin.ProxyRoute{
+	{Method: http.MethodPut, Path: "/api/v1/exec", Privileged: true, Check: checkExecRequest},
+	{Method: http.MethodGet, Path: "/api/v1/state/**", Privileged: true},
+	{Method: http.MethodHead, Path: "/api/v1/state/**", Privileged: true},
+}
+
+// execRequest represents the LinQL execution request of LinDB.
+type execRequest struct {
+	Database string `json:"db"`
+	SQL      string `json:"sql"`
+}
+
+// checkExecRequest checks if the LinQL of execution request is read only statement, the request is rewritten
+// with the parsed statement(json body without query params), so that LinDB cannot execute other statement.
+func checkExecRequest(req *http.Request, _ string, _ json.RawMessage) error {
+	if req.Body == nil {
+		return fmt.Errorf("LinQL is required")
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxExecBodySize+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > maxExecBodySize {
+		return fmt.Errorf("request body too large, limit: %d", maxExecBodySize)
+	}
+	param := &execRequest{}
+	if err := json.Unmarshal(body, param); err != nil {
+		return fmt.Errorf("invalid LinQL execution request: %w", err)
+	}
+	param.SQL = strings.TrimSpace(param.SQL)
+	if !readOnlyPattern.MatchString(param.SQL) {
+		return fmt.Errorf("only select/explain statement is allowed: %s", param.SQL)
+	}
+	body, err = json.Marshal(param)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = ""
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = nil
+	return nil
+}
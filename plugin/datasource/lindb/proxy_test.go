@@ -0,0 +1,82 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errReader struct{}
+
+func (errReader) Read(_ []byte) (int, error) {
+	return 0, fmt.Errorf("err")
+}
+
+func TestProxy_checkExecRequest(t *testing.T) {
+	cases := []struct {
+		name    string
+		url     string
+		body    io.Reader
+		want    string
+		wantErr bool
+	}{
+		{name: "select", url: "/api/v1/exec?sql=drop+database+_internal", body: strings.NewReader(
+			`{"db":"_internal","sql":" select heap_objects from lindb.runtime.mem "}`),
+			want: `{"db":"_internal","sql":"select heap_objects from lindb.runtime.mem"}`},
+		{name: "explain", url: "/api/v1/exec", body: strings.NewReader(
+			`{"db":"_internal","sql":"EXPLAIN SELECT heap_objects from lindb.runtime.mem"}`),
+			want: `{"db":"_internal","sql":"EXPLAIN SELECT heap_objects from lindb.runtime.mem"}`},
+		{name: "duplicated key", url: "/api/v1/exec", body: strings.NewReader(
+			`{"db":"_internal","sql":"drop database test","sql":"select f from m"}`),
+			want: `{"db":"_internal","sql":"select f from m"}`},
+		{name: "no body", url: "/api/v1/exec", wantErr: true},
+		{name: "read body failure", url: "/api/v1/exec", body: errReader{}, wantErr: true},
+		{name: "body too large", url: "/api/v1/exec", body: bytes.NewReader(make([]byte, maxExecBodySize+1)), wantErr: true},
+		{name: "invalid body", url: "/api/v1/exec", body: strings.NewReader(`sql=select`), wantErr: true},
+		{name: "drop database", url: "/api/v1/exec", body: strings.NewReader(`{"sql":"drop database test"}`), wantErr: true},
+		{name: "create database", url: "/api/v1/exec", body: strings.NewReader(`{"sql":"create database test"}`), wantErr: true},
+		{name: "show state", url: "/api/v1/exec", body: strings.NewReader(`{"sql":"show broker alive"}`), wantErr: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPut, tt.url, tt.body)
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			err = checkExecRequest(req, "/api/v1/exec", nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, req.URL.RawQuery)
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+			assert.Equal(t, int64(len(tt.want)), req.ContentLength)
+		})
+	}
+}
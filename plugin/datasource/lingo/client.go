@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/lindb/common/pkg/encoding"
@@ -39,6 +41,15 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+const (
+	defaultSearchLimit = 20
+	// maxSearchLimit represents the max number of traces returned by trace search.
+	maxSearchLimit = 1000
+)
+
+// ProxyRoutes represents the LinGo apis which datasource proxy is allowed to forward(pipeline endpoint).
+var ProxyRoutes = plugin.ReadOnlyProxyRoutes("/")
+
 // for testing
 var (
 	jsonUnmarshalFn = encoding.JSONUnmarshal
@@ -63,48 +74,180 @@ func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.Data
 		return nil, err0
 	}
 
+	httpCli, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &client{
 		cfg:       config,
 		datasouce: datasource,
-		httpCli: &http.Client{
-			Transport: &http.Transport{
-				MaxIdleConns:        10, // TODO: need add config
-				IdleConnTimeout:     30 * time.Second,
-				MaxIdleConnsPerHost: 5,
-			},
-		},
-		logger: logger.GetLogger("DatasourcePlugin", "LinDB"),
+		httpCli:   httpCli,
+		logger:    logger.GetLogger("DatasourcePlugin", "LinDB"),
 	}, nil
 }
 
-// DataQuery queries trace data from LinGo.
-func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (any, error) {
+// DataQuery queries trace data from LinGo, returns one trace frame per process if gets trace by trace id,
+// returns a table frame of trace summaries if searches traces.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
 	data, _ := req.Request.MarshalJSON()
-	traceQueryReq := &GetTraceRequest{}
+	traceQueryReq := &DataQueryRequest{}
 	if err := jsonUnmarshalFn(data, &traceQueryReq); err != nil {
 		return nil, err
 	}
-	httpReq, err := newRequestFn(ctx, "GET",
-		fmt.Sprintf("%s?pipeline=%s&traceId=%s", cli.datasouce.URL, cli.cfg.Pipeline, traceQueryReq.TraceID),
-		nil)
-	if err != nil {
+	switch traceQueryReq.Type {
+	case "", Trace:
+		var traces []*model.Trace
+		if err := cli.get(ctx, url.Values{"traceId": []string{traceQueryReq.TraceID}}, &traces); err != nil {
+			return nil, err
+		}
+		return model.NewFramesFromTraces(traces), nil
+	case Search:
+		return cli.searchTraces(ctx, traceQueryReq, timeRange)
+	default:
+		return nil, fmt.Errorf("query type not support, type: %s", traceQueryReq.Type)
+	}
+}
+
+// MetadataQuery queries service/operation list.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
 		return nil, err
 	}
+	params := url.Values{"type": []string{metadataQueryReq.Type}}
+	switch metadataQueryReq.Type {
+	case Services:
+	case Operations:
+		if metadataQueryReq.Service == "" {
+			return nil, fmt.Errorf("service is required")
+		}
+		params.Set("service", metadataQueryReq.Service)
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+	rs := []string{}
+	if err := cli.get(ctx, params, &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// searchTraces searches traces by conditions in time range, returns the summaries of traces(at most limit).
+func (cli *client) searchTraces(ctx context.Context, req *DataQueryRequest, timeRange model.TimeRange) (model.Frames, error) {
+	params := url.Values{"type": []string{Search}}
+	if req.Service != "" {
+		params.Set("service", req.Service)
+	}
+	if req.Operation != "" {
+		params.Set("operation", req.Operation)
+	}
+	var minDuration, maxDuration time.Duration
+	if req.MinDuration != "" {
+		var err error
+		if minDuration, err = time.ParseDuration(req.MinDuration); err != nil {
+			return nil, fmt.Errorf("invalid min duration: %w", err)
+		}
+		params.Set("minDuration", strconv.FormatInt(minDuration.Nanoseconds(), 10))
+	}
+	if req.MaxDuration != "" {
+		var err error
+		if maxDuration, err = time.ParseDuration(req.MaxDuration); err != nil {
+			return nil, fmt.Errorf("invalid max duration: %w", err)
+		}
+		if maxDuration < minDuration {
+			return nil, fmt.Errorf("max duration(%s) is less than min duration(%s)", req.MaxDuration, req.MinDuration)
+		}
+		params.Set("maxDuration", strconv.FormatInt(maxDuration.Nanoseconds(), 10))
+	}
+	if len(req.Tags) > 0 {
+		tags, _ := json.Marshal(req.Tags)
+		params.Set("tags", string(tags))
+	}
+	if timeRange.From > 0 {
+		params.Set("start", strconv.FormatInt(timeRange.From, 10))
+	}
+	if timeRange.To > 0 {
+		params.Set("end", strconv.FormatInt(timeRange.To, 10))
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	var traces []*model.Trace
+	if err := cli.get(ctx, params, &traces); err != nil {
+		return nil, err
+	}
+	frame := model.NewTraceSummaryFrame(traces)
+	if frame.Rows() > limit {
+		for _, field := range frame.Fields {
+			field.Values = field.Values[:limit]
+		}
+	}
+	return model.Frames{frame}, nil
+}
+
+// get sends get request with params to the pipeline endpoint, then unmarshals the response.
+func (cli *client) get(ctx context.Context, params url.Values, data any) error {
+	params.Set("pipeline", cli.cfg.Pipeline)
+	httpReq, err := newRequestFn(ctx, http.MethodGet, cli.datasouce.URL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
 	resp, err := cli.httpCli.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	d, err := readAllFn(resp.Body)
+	body, err := readAllFn(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return json.RawMessage(d), nil
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	if err := jsonUnmarshalFn(body, data); err != nil {
+		return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
 }
 
-func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
-	panic("not support")
+// CheckHealth checks if the pipeline endpoint answers.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	if cli.cfg.Pipeline == "" {
+		return fmt.Errorf("pipeline is required")
+	}
+	httpReq, err := newRequestFn(ctx, http.MethodGet,
+		fmt.Sprintf("%s?pipeline=%s", cli.datasouce.URL, cli.cfg.Pipeline), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.httpCli.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("pipeline endpoint not available, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close closes the idle connections of http client if supported.
+func (cli *client) Close() error {
+	if closer, ok := cli.httpCli.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+	return nil
 }
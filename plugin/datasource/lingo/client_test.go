@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -116,18 +118,28 @@ func TestClient_DataQuery(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unexpected response",
+			prepare: func() {
+				httpCli.EXPECT().Do(gomock.Any()).DoAndReturn(func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 500,
+						Body:       io.NopCloser(bytes.NewReader([]byte("internal error"))),
+					}, nil
+				})
+			},
+			wantErr: true,
+		},
 		{
 			name: "get trace data successfully",
 			prepare: func() {
 				httpCli.EXPECT().Do(gomock.Any()).DoAndReturn(func(_ *http.Request) (*http.Response, error) {
 					return &http.Response{
 						StatusCode: 200,
-						Body:       io.NopCloser(bytes.NewReader([]byte{})),
+						Body: io.NopCloser(bytes.NewReader([]byte(
+							`[{"process":{"serviceName":"order"},"spans":[{"traceId":"t1","spanId":"s1","startTime":1}]}]`))),
 					}, nil
 				})
-				readAllFn = func(_ io.Reader) ([]byte, error) {
-					return nil, nil
-				}
 			},
 		},
 	}
@@ -150,11 +162,277 @@ func TestClient_DataQuery(t *testing.T) {
 	}
 }
 
+func TestClient_SearchTraces(t *testing.T) {
+	var params url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params = r.URL.Query()
+		_, _ = w.Write([]byte(`[{"process":{"serviceName":"order"},"spans":[` +
+			`{"traceId":"t1","spanId":"s1","name":"get","startTime":1000000,"endTime":3000000},` +
+			`{"traceId":"t2","spanId":"s2","name":"list","startTime":2000000,"endTime":3000000}]}]`))
+	}))
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{"pipeline":"trace"}`))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		params  url.Values
+		traces  []any
+		wantErr bool
+	}{
+		{
+			name:    "invalid min duration",
+			req:     `{"type":"search","minDuration":"a"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid max duration",
+			req:     `{"type":"search","maxDuration":"a"}`,
+			wantErr: true,
+		},
+		{
+			name:    "max duration less than min duration",
+			req:     `{"type":"search","minDuration":"1s","maxDuration":"100ms"}`,
+			wantErr: true,
+		},
+		{
+			name:    "query type not support",
+			req:     `{"type":"unknown"}`,
+			wantErr: true,
+		},
+		{
+			name: "search by conditions",
+			req: `{"type":"search","service":"order","operation":"get","minDuration":"1ms","maxDuration":"1s",` +
+				`"tags":{"http.status_code":"500"},"limit":10}`,
+			params: url.Values{
+				"pipeline":    []string{"trace"},
+				"type":        []string{"search"},
+				"service":     []string{"order"},
+				"operation":   []string{"get"},
+				"minDuration": []string{"1000000"},
+				"maxDuration": []string{"1000000000"},
+				"tags":        []string{`{"http.status_code":"500"}`},
+				"start":       []string{"1000"},
+				"end":         []string{"2000"},
+				"limit":       []string{"10"},
+			},
+			traces: []any{"t2", "t1"},
+		},
+		{
+			name: "search with limit",
+			req:  `{"type":"search","limit":1}`,
+			params: url.Values{
+				"pipeline": []string{"trace"},
+				"type":     []string{"search"},
+				"start":    []string{"1000"},
+				"end":      []string{"2000"},
+				"limit":    []string{"1"},
+			},
+			traces: []any{"t2"},
+		},
+		{
+			name: "search with limit exceeds max limit",
+			req:  `{"type":"search","limit":100000}`,
+			params: url.Values{
+				"pipeline": []string{"trace"},
+				"type":     []string{"search"},
+				"start":    []string{"1000"},
+				"end":      []string{"2000"},
+				"limit":    []string{"1000"},
+			},
+			traces: []any{"t2", "t1"},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params = nil
+			frames, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, model.TimeRange{From: 1000, To: 2000})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, params)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.params, params)
+			assert.Len(t, frames, 1)
+			assert.Equal(t, model.FrameTypeTable, frames[0].Meta.Type)
+			assert.Equal(t, tt.traces, frames[0].Fields[0].Values)
+		})
+	}
+}
+
 func TestClient_MetadataQuery(t *testing.T) {
-	assert.Panics(t, func() {
-		cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
-		assert.NoError(t, err)
-		_, _ = cli.MetadataQuery(context.TODO(), nil)
-		assert.True(t, false)
-	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		switch params.Get("type") {
+		case Services:
+			_, _ = w.Write([]byte(`["order","user"]`))
+		case Operations:
+			if params.Get("service") != "order" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("service not found"))
+				return
+			}
+			_, _ = w.Write([]byte(`["get","list"]`))
+		}
+	}))
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{"pipeline":"trace"}`))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		rs      any
+		wantErr bool
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "metadata type not support",
+			req:     `{"type":"unknown"}`,
+			wantErr: true,
+		},
+		{
+			name:    "service is required",
+			req:     `{"type":"operations"}`,
+			wantErr: true,
+		},
+		{
+			name:    "service not found",
+			req:     `{"type":"operations","service":"user"}`,
+			wantErr: true,
+		},
+		{
+			name: "get services",
+			req:  `{"type":"services"}`,
+			rs:   []string{"order", "user"},
+		},
+		{
+			name: "get operations",
+			req:  `{"type":"operations","service":"order"}`,
+			rs:   []string{"get", "list"},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{Request: json.RawMessage(tt.req)})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rs, rs)
+		})
+	}
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	httpCli := NewMockHTTPClient(ctrl)
+	cli := &client{
+		cfg:       &DatasourceConfig{Pipeline: "trace"},
+		datasouce: &model.Datasource{},
+		httpCli:   httpCli,
+		logger:    logger.GetLogger("Test", "LinGo"),
+	}
+
+	cases := []struct {
+		name    string
+		prepare func()
+		wantErr bool
+	}{
+		{
+			name: "pipeline is empty",
+			prepare: func() {
+				cli.cfg.Pipeline = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "new http request failure",
+			prepare: func() {
+				newRequestFn = func(_ context.Context, _, _ string, _ io.Reader) (*http.Request, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "do http request failure",
+			prepare: func() {
+				httpCli.EXPECT().Do(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipeline endpoint not available",
+			prepare: func() {
+				httpCli.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: http.StatusBadGateway,
+					Body:       io.NopCloser(bytes.NewReader([]byte{})),
+				}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipeline endpoint answers",
+			prepare: func() {
+				httpCli.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(bytes.NewReader([]byte{})),
+				}, nil)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				newRequestFn = http.NewRequestWithContext
+				cli.cfg.Pipeline = "trace"
+			}()
+			tt.prepare()
+			err := cli.CheckHealth(context.TODO())
+			if tt.wantErr != (err != nil) {
+				t.Fatal(tt.name)
+			}
+		})
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+
+	cli = &client{httpCli: NewMockHTTPClient(ctrl)}
+	assert.NoError(t, cli.Close())
 }
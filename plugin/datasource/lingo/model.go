@@ -17,12 +17,47 @@
 
 package lingo
 
+// QueryType represents data query type for LinGo.
+type QueryType = string
+
+// Defines all data query types.
+var (
+	// Trace gets the spans of trace by trace id.
+	Trace QueryType = "trace"
+	// Search searches traces by service/operation/duration/tags in time range.
+	Search = "search"
+)
+
+// MetadataType represents metadata type for LinGo.
+type MetadataType = string
+
+// Defines all LinGo's metadata types.
+var (
+	Services   MetadataType = "services"
+	Operations              = "operations"
+)
+
 // DatasourceConfig represents datasource config for LinGo.
 type DatasourceConfig struct {
 	Pipeline string `json:"pipeline"`
 }
 
-// GetTraceRequest represents get trace data request params.
-type GetTraceRequest struct {
-	TraceID string `json:"traceId"`
+// DataQueryRequest represents data query request for LinGo, gets trace by trace id if type is empty.
+type DataQueryRequest struct {
+	Type    QueryType `json:"type"`
+	TraceID string    `json:"traceId"`
+	// Service/Operation/MinDuration/MaxDuration/Tags/Limit are the conditions of trace search,
+	// duration is like 100ms/1.5s.
+	Service     string            `json:"service"`
+	Operation   string            `json:"operation"`
+	MinDuration string            `json:"minDuration"`
+	MaxDuration string            `json:"maxDuration"`
+	Tags        map[string]string `json:"tags"`
+	Limit       int               `json:"limit"`
+}
+
+// MetadataQueryRequest represents metadata query request for LinGo.
+type MetadataQueryRequest struct {
+	Type    MetadataType `json:"type"`
+	Service string       `json:"service"`
 }
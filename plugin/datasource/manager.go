@@ -20,43 +20,271 @@ package datasource
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lindb/common/pkg/logger"
 
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/util"
 	"github.com/lindb/linsight/plugin"
+	"github.com/lindb/linsight/plugin/datasource/elasticsearch"
+	"github.com/lindb/linsight/plugin/datasource/jaeger"
 	"github.com/lindb/linsight/plugin/datasource/lindb"
 	"github.com/lindb/linsight/plugin/datasource/lingo"
+	"github.com/lindb/linsight/plugin/datasource/prometheus"
+	"github.com/lindb/linsight/plugin/datasource/sqldb"
+	"github.com/lindb/linsight/plugin/datasource/testdatasource"
+	"github.com/lindb/linsight/plugin/datasource/zipkin"
 )
 
 //go:generate mockgen -source=./manager.go -destination=./manager_mock.go -package=datasource
 
-var datasourceClients = make(map[string]plugin.NewDatasourcePlugin)
+var (
+	datasourceClients = make(map[string]plugin.NewDatasourcePlugin)
+	// proxyRoutes represents the routes which datasource proxy is allowed to forward by datasource type.
+	proxyRoutes = make(map[string][]plugin.ProxyRoute)
+)
 
 func init() {
 	datasourceClients[model.LinDBDatasource] = lindb.NewClient
 	datasourceClients[model.LinGoDatasource] = lingo.NewClient
+	datasourceClients[model.PrometheusDatasource] = prometheus.NewClient
+	datasourceClients[model.MySQLDatasource] = sqldb.NewClient
+	datasourceClients[model.PostgreSQLDatasource] = sqldb.NewClient
+	datasourceClients[model.SQLiteDatasource] = sqldb.NewClient
+	datasourceClients[model.TestDataDatasource] = testdatasource.NewClient
+	datasourceClients[model.ElasticsearchDatasource] = elasticsearch.NewClient
+	datasourceClients[model.OpenSearchDatasource] = elasticsearch.NewClient
+	datasourceClients[model.JaegerDatasource] = jaeger.NewClient
+	datasourceClients[model.ZipkinDatasource] = zipkin.NewClient
+
+	proxyRoutes[model.LinDBDatasource] = lindb.ProxyRoutes
+	proxyRoutes[model.LinGoDatasource] = lingo.ProxyRoutes
+	proxyRoutes[model.PrometheusDatasource] = prometheus.ProxyRoutes
+	proxyRoutes[model.ElasticsearchDatasource] = elasticsearch.ProxyRoutes
+	proxyRoutes[model.OpenSearchDatasource] = elasticsearch.ProxyRoutes
+	proxyRoutes[model.JaegerDatasource] = jaeger.ProxyRoutes
+	proxyRoutes[model.ZipkinDatasource] = zipkin.ProxyRoutes
 }
 
 // Manager represents datasouce plugin manager.
 type Manager interface {
-	// GetPlugin returns datasource plugin by type.
+	// GetPlugin returns datasource plugin by type, the plugin instance is cached by uid and version of datasource.
 	GetPlugin(datasouce *model.Datasource) (plugin.DatasourcePlugin, error)
+	// NewPlugin creates a datasource plugin without cache, caller need close it after used.
+	NewPlugin(datasouce *model.Datasource) (plugin.DatasourcePlugin, error)
+	// GetProxy returns the proxy which forwards request to datasource with its credentials,
+	// the proxy is cached by uid and version of datasource.
+	GetProxy(datasource *model.Datasource) (Proxy, error)
+	// RemovePlugin evicts the cached datasource plugin/proxy by uid, then closes it after the queries in flight completed.
+	RemovePlugin(uid string)
+}
+
+// pluginInstance represents the cached datasource plugin with version.
+type pluginInstance struct {
+	version time.Time
+	plugin  *sharedPlugin
+}
+
+// proxyInstance represents the cached datasource proxy with version.
+type proxyInstance struct {
+	version time.Time
+	proxy   *proxy
 }
 
 // manager implements Manager interface.
 type manager struct {
+	plugins   map[string]*pluginInstance
+	proxies   map[string]*proxyInstance
+	secretKey string
+	lock      sync.RWMutex
+	// slots represents the query slots of datasource by uid, shared by the plugins of different versions,
+	// so that the queries in flight of old version are counted after datasource modified.
+	slots     map[string]chan struct{}
+	slotsLock sync.Mutex
+
+	logger logger.Logger
 }
 
-// NewDatasourceManager creates datasource Manager instance.
-func NewDatasourceManager() Manager {
-	return &manager{}
+// NewDatasourceManager creates datasource Manager instance, the secrets of datasource are decrypted by secret key.
+func NewDatasourceManager(secretKey string) Manager {
+	return &manager{
+		plugins:   make(map[string]*pluginInstance),
+		proxies:   make(map[string]*proxyInstance),
+		slots:     make(map[string]chan struct{}),
+		secretKey: secretKey,
+		logger:    logger.GetLogger("Plugin", "DatasourceManager"),
+	}
 }
 
-// GetPlugin returns datasource plugin by type.
+// GetPlugin returns datasource plugin by type, the plugin instance is cached by uid and version of datasource.
 func (mgr *manager) GetPlugin(datasource *model.Datasource) (plugin.DatasourcePlugin, error) {
+	if datasource.UID == "" {
+		// datasource not saved, cannot cache it
+		return mgr.NewPlugin(datasource)
+	}
+	mgr.lock.RLock()
+	instance, ok := mgr.plugins[datasource.UID]
+	mgr.lock.RUnlock()
+	// datasource may be loaded before modified(stale version), uses the newer cached version
+	if ok && !instance.version.Before(datasource.UpdatedAt) {
+		return instance.plugin, nil
+	}
+
+	mgr.lock.Lock()
+	defer mgr.lock.Unlock()
+	// double check, maybe other goroutine created it
+	instance, ok = mgr.plugins[datasource.UID]
+	if ok && !instance.version.Before(datasource.UpdatedAt) {
+		return instance.plugin, nil
+	}
+	cli, err := mgr.NewPlugin(datasource)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		// datasource modified, close the old version after the queries in flight completed
+		instance.plugin.evict()
+	}
+	shared := newSharedPlugin(datasource.UID, cli, func() {
+		mgr.closePlugin(datasource.UID, cli)
+	})
+	mgr.plugins[datasource.UID] = &pluginInstance{
+		version: datasource.UpdatedAt,
+		plugin:  shared,
+	}
+	return shared, nil
+}
+
+// NewPlugin creates a datasource plugin without cache, caller need close it after used.
+// The plugin is guarded by the query limits of datasource config, and gets the config merged with secrets.
+func (mgr *manager) NewPlugin(datasource *model.Datasource) (plugin.DatasourcePlugin, error) {
 	newCliFn, ok := datasourceClients[datasource.Type]
 	if !ok {
 		return nil, fmt.Errorf("datasouce not support, type: %s", datasource.Type)
 	}
-	// FIXME: add cache
-	return newCliFn(datasource, json.RawMessage(datasource.Config))
+	cfg, err := mgr.pluginConfig(datasource)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := newCliFn(datasource, cfg)
+	if err != nil {
+		return nil, err
+	}
+	guarded, err := newGuardPlugin(datasource, cli, func(limit int) chan struct{} {
+		return mgr.getSlots(datasource.UID, limit)
+	})
+	if err != nil {
+		mgr.closePlugin(datasource.UID, cli)
+		return nil, err
+	}
+	return guarded, nil
+}
+
+// GetProxy returns the proxy which forwards request to datasource with its credentials,
+// the proxy is cached by uid and version of datasource.
+func (mgr *manager) GetProxy(datasource *model.Datasource) (Proxy, error) {
+	routes, ok := proxyRoutes[datasource.Type]
+	if !ok {
+		return nil, fmt.Errorf("datasouce not support proxy, type: %s", datasource.Type)
+	}
+	mgr.lock.RLock()
+	instance, ok := mgr.proxies[datasource.UID]
+	mgr.lock.RUnlock()
+	// datasource may be loaded before modified(stale version), uses the newer cached version
+	if ok && !instance.version.Before(datasource.UpdatedAt) {
+		return instance.proxy, nil
+	}
+
+	mgr.lock.Lock()
+	defer mgr.lock.Unlock()
+	// double check, maybe other goroutine created it
+	instance, ok = mgr.proxies[datasource.UID]
+	if ok && !instance.version.Before(datasource.UpdatedAt) {
+		return instance.proxy, nil
+	}
+	cfg, err := mgr.pluginConfig(datasource)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newProxy(datasource, cfg, routes)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		// datasource modified, close the old version
+		instance.proxy.closeIdleConnections()
+	}
+	mgr.proxies[datasource.UID] = &proxyInstance{
+		version: datasource.UpdatedAt,
+		proxy:   p,
+	}
+	return p, nil
+}
+
+// pluginConfig returns the config of datasource merged with the decrypted secrets and the secrets not
+// saved(testing datasource before saving).
+func (mgr *manager) pluginConfig(datasource *model.Datasource) (json.RawMessage, error) {
+	cfg := []byte(datasource.Config)
+	if len(datasource.EncryptedSecureConfig) > 0 {
+		secrets, err := util.Decrypt(datasource.EncryptedSecureConfig, mgr.secretKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt secure config of datasource failure: %w", err)
+		}
+		if cfg, err = util.MergeJSON(cfg, secrets); err != nil {
+			return nil, err
+		}
+	}
+	if len(datasource.SecureConfig) > 0 {
+		var err error
+		if cfg, err = util.MergeJSON(cfg, datasource.SecureConfig); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// RemovePlugin evicts the cached datasource plugin/proxy by uid, then closes it after the queries in flight completed.
+func (mgr *manager) RemovePlugin(uid string) {
+	mgr.lock.Lock()
+	instance, ok := mgr.plugins[uid]
+	delete(mgr.plugins, uid)
+	cachedProxy, proxyOk := mgr.proxies[uid]
+	delete(mgr.proxies, uid)
+	mgr.lock.Unlock()
+	mgr.slotsLock.Lock()
+	delete(mgr.slots, uid)
+	mgr.slotsLock.Unlock()
+
+	if ok {
+		instance.plugin.evict()
+	}
+	if proxyOk {
+		cachedProxy.proxy.closeIdleConnections()
+	}
+}
+
+// getSlots returns the query slots of datasource by uid, creates new slots if datasource not saved or
+// the limit modified(the queries in flight of old version are not counted).
+func (mgr *manager) getSlots(uid string, limit int) chan struct{} {
+	if uid == "" {
+		return make(chan struct{}, limit)
+	}
+	mgr.slotsLock.Lock()
+	defer mgr.slotsLock.Unlock()
+	slots, ok := mgr.slots[uid]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		mgr.slots[uid] = slots
+	}
+	return slots
+}
+
+// closePlugin closes datasource plugin, logs the error if failure.
+func (mgr *manager) closePlugin(uid string, cli plugin.DatasourcePlugin) {
+	if err := cli.Close(); err != nil {
+		mgr.logger.Warn("close datasource plugin failure",
+			logger.String("uid", uid), logger.Error(err))
+	}
 }
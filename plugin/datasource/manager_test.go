@@ -18,15 +18,24 @@
 package datasource
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/util"
+	"github.com/lindb/linsight/plugin"
 )
 
 func TestManager_GetPlugin(t *testing.T) {
-	mgr := NewDatasourceManager()
+	mgr := NewDatasourceManager("")
 	plugin, err := mgr.GetPlugin(&model.Datasource{})
 	assert.Error(t, err)
 	assert.Nil(t, plugin)
@@ -36,4 +45,345 @@ func TestManager_GetPlugin(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.NotNil(t, plugin)
+
+	plugin, err = mgr.GetPlugin(&model.Datasource{
+		Type: model.PrometheusDatasource,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+
+	plugin, err = mgr.GetPlugin(&model.Datasource{
+		Type: model.TestDataDatasource,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+
+	plugin, err = mgr.GetPlugin(&model.Datasource{
+		Type: model.OpenSearchDatasource,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+
+	plugin, err = mgr.GetPlugin(&model.Datasource{
+		Type: model.JaegerDatasource,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+
+	plugin, err = mgr.GetPlugin(&model.Datasource{
+		Type: model.ZipkinDatasource,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+}
+
+func TestManager_PluginCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		delete(datasourceClients, "mock")
+		ctrl.Finish()
+	}()
+
+	var newCliErr error
+	var clients []*plugin.MockDatasourcePlugin
+	datasourceClients["mock"] = func(_ *model.Datasource, _ json.RawMessage) (plugin.DatasourcePlugin, error) {
+		if newCliErr != nil {
+			return nil, newCliErr
+		}
+		cli := plugin.NewMockDatasourcePlugin(ctrl)
+		clients = append(clients, cli)
+		return cli, nil
+	}
+	mgr := NewDatasourceManager("")
+	now := time.Now()
+	ds := &model.Datasource{UID: "1234", Type: "mock", BaseModel: model.BaseModel{UpdatedAt: now}}
+
+	// not saved datasource, no cache
+	p1, err := mgr.GetPlugin(&model.Datasource{Type: "mock"})
+	assert.NoError(t, err)
+	p2, err := mgr.GetPlugin(&model.Datasource{Type: "mock"})
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
+
+	// same version, hit cache
+	p1, err = mgr.GetPlugin(ds)
+	assert.NoError(t, err)
+	p2, err = mgr.GetPlugin(ds)
+	assert.NoError(t, err)
+	assert.Same(t, p1, p2)
+
+	// create new version failure, keep old version
+	newCliErr = fmt.Errorf("err")
+	p2, err = mgr.GetPlugin(&model.Datasource{UID: "1234", Type: "mock", BaseModel: model.BaseModel{UpdatedAt: now.Add(time.Second)}})
+	assert.Error(t, err)
+	assert.Nil(t, p2)
+	newCliErr = nil
+
+	// new version, close old version
+	clients[len(clients)-1].EXPECT().Close().Return(fmt.Errorf("err"))
+	p2, err = mgr.GetPlugin(&model.Datasource{UID: "1234", Type: "mock", BaseModel: model.BaseModel{UpdatedAt: now.Add(time.Second)}})
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
+
+	// stale version, keep new version
+	p3, err := mgr.GetPlugin(ds)
+	assert.NoError(t, err)
+	assert.Same(t, p2, p3)
+
+	// remove plugin
+	clients[len(clients)-1].EXPECT().Close().Return(nil)
+	mgr.RemovePlugin("1234")
+	mgr.RemovePlugin("1234")
+	p1, err = mgr.GetPlugin(ds)
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
+}
+
+func TestManager_PluginUpdatedInQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		delete(datasourceClients, "mock")
+		ctrl.Finish()
+	}()
+
+	var (
+		closed    atomic.Bool
+		clients   []*plugin.MockDatasourcePlugin
+		clientsMu sync.Mutex
+	)
+	datasourceClients["mock"] = func(_ *model.Datasource, _ json.RawMessage) (plugin.DatasourcePlugin, error) {
+		cli := plugin.NewMockDatasourcePlugin(ctrl)
+		clientsMu.Lock()
+		clients = append(clients, cli)
+		clientsMu.Unlock()
+		return cli, nil
+	}
+	mgr := NewDatasourceManager("")
+	now := time.Now()
+	p1, err := mgr.GetPlugin(&model.Datasource{UID: "1234", Type: "mock", BaseModel: model.BaseModel{UpdatedAt: now}})
+	assert.NoError(t, err)
+
+	inQuery := make(chan struct{})
+	finishQuery := make(chan struct{})
+	clients[0].EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *model.Query, _ model.TimeRange) (model.Frames, error) {
+			close(inQuery)
+			<-finishQuery
+			if closed.Load() {
+				return nil, fmt.Errorf("database is closed")
+			}
+			return model.Frames{}, nil
+		})
+	clients[0].EXPECT().Close().DoAndReturn(func() error {
+		closed.Store(true)
+		return nil
+	})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := p1.DataQuery(context.TODO(), &model.Query{}, model.TimeRange{})
+		assert.NoError(t, err)
+	}()
+	<-inQuery
+	// datasource updated when query in flight
+	p2, err := mgr.GetPlugin(&model.Datasource{UID: "1234", Type: "mock", BaseModel: model.BaseModel{UpdatedAt: now.Add(time.Second)}})
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
+	assert.False(t, closed.Load())
+	close(finishQuery)
+	wg.Wait()
+	// closed after query completed
+	assert.True(t, closed.Load())
+	_, err = p1.DataQuery(context.TODO(), &model.Query{}, model.TimeRange{})
+	assert.Error(t, err)
+	_, err = p1.MetadataQuery(context.TODO(), &model.Query{})
+	assert.Error(t, err)
+	assert.Error(t, p1.CheckHealth(context.TODO()))
+	assert.NoError(t, p1.Close())
+
+	// new version works
+	clients[1].EXPECT().MetadataQuery(gomock.Any(), gomock.Any()).Return("ok", nil)
+	clients[1].EXPECT().CheckHealth(gomock.Any()).Return(nil)
+	rs, err := p2.MetadataQuery(context.TODO(), &model.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", rs)
+	assert.NoError(t, p2.CheckHealth(context.TODO()))
+	req, err := plugin.InterpolateVariables(p2, json.RawMessage(`{"sql":"select 1"}`), nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sql":"select 1"}`, string(req))
+	clients[1].EXPECT().Close().Return(nil)
+	mgr.RemovePlugin("1234")
+}
+
+func TestManager_SharedSlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		delete(datasourceClients, "mock")
+		ctrl.Finish()
+	}()
+
+	var clients []*plugin.MockDatasourcePlugin
+	datasourceClients["mock"] = func(_ *model.Datasource, _ json.RawMessage) (plugin.DatasourcePlugin, error) {
+		cli := plugin.NewMockDatasourcePlugin(ctrl)
+		clients = append(clients, cli)
+		return cli, nil
+	}
+	mgr := NewDatasourceManager("")
+	now := time.Now()
+	newDatasource := func(updatedAt time.Time, config string) *model.Datasource {
+		return &model.Datasource{UID: "1234", Type: "mock", Config: []byte(config), BaseModel: model.BaseModel{UpdatedAt: updatedAt}}
+	}
+	p1, err := mgr.GetPlugin(newDatasource(now, `{"maxConcurrentQueries":1}`))
+	assert.NoError(t, err)
+
+	inQuery := make(chan struct{})
+	finishQuery := make(chan struct{})
+	clients[0].EXPECT().MetadataQuery(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *model.Query) (any, error) {
+			close(inQuery)
+			<-finishQuery
+			return "ok", nil
+		})
+	clients[0].EXPECT().Close().Return(nil)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := p1.MetadataQuery(context.TODO(), &model.Query{})
+		assert.NoError(t, err)
+	}()
+	<-inQuery
+
+	// new version shares the slots with old version
+	p2, err := mgr.GetPlugin(newDatasource(now.Add(time.Second), `{"maxConcurrentQueries":1}`))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	_, err = p2.MetadataQuery(ctx, &model.Query{})
+	cancel()
+	assert.ErrorContains(t, err, "too many concurrent queries")
+	close(finishQuery)
+	wg.Wait()
+	clients[1].EXPECT().MetadataQuery(gomock.Any(), gomock.Any()).Return("ok", nil)
+	_, err = p2.MetadataQuery(context.TODO(), &model.Query{})
+	assert.NoError(t, err)
+
+	// limit modified, new slots
+	clients[1].EXPECT().Close().Return(nil)
+	p3, err := mgr.GetPlugin(newDatasource(now.Add(2*time.Second), `{"maxConcurrentQueries":2}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cap(p3.(*sharedPlugin).DatasourcePlugin.(*guardPlugin).slots))
+	clients[2].EXPECT().Close().Return(nil)
+	mgr.RemovePlugin("1234")
+}
+
+func TestManager_NewPlugin_InvalidLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		delete(datasourceClients, "mock")
+		ctrl.Finish()
+	}()
+
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	datasourceClients["mock"] = func(_ *model.Datasource, _ json.RawMessage) (plugin.DatasourcePlugin, error) {
+		return cli, nil
+	}
+	mgr := NewDatasourceManager("")
+	cli.EXPECT().Close().Return(nil)
+	p, err := mgr.NewPlugin(&model.Datasource{Type: "mock", Config: []byte(`{"queryTimeout":"a"}`)})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+
+	p, err = mgr.NewPlugin(&model.Datasource{Type: "mock", Config: []byte(`{"queryTimeout":"10s"}`)})
+	assert.NoError(t, err)
+	assert.IsType(t, &guardPlugin{}, p)
+}
+
+func TestManager_NewPlugin_SecureConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		delete(datasourceClients, "mock")
+		ctrl.Finish()
+	}()
+
+	var config json.RawMessage
+	datasourceClients["mock"] = func(_ *model.Datasource, cfg json.RawMessage) (plugin.DatasourcePlugin, error) {
+		config = cfg
+		return plugin.NewMockDatasourcePlugin(ctrl), nil
+	}
+	secrets, err := util.Encrypt([]byte(`{"http":{"bearerToken":"token","basicAuth":{"password":"pwd"}}}`), "key")
+	assert.NoError(t, err)
+	ds := &model.Datasource{
+		Type:                  "mock",
+		Config:                []byte(`{"database":"_internal","http":{"basicAuth":{"user":"admin"}}}`),
+		EncryptedSecureConfig: secrets,
+	}
+
+	// secret key changed
+	p, err := NewDatasourceManager("new-key").NewPlugin(ds)
+	assert.Error(t, err)
+	assert.Nil(t, p)
+
+	mgr := NewDatasourceManager("key")
+	_, err = mgr.NewPlugin(ds)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"database":"_internal","http":{"bearerToken":"token","basicAuth":{"user":"admin","password":"pwd"}}}`,
+		string(config))
+
+	// secrets not saved override stored secrets
+	ds.SecureConfig = []byte(`{"http":{"bearerToken":"new-token"}}`)
+	_, err = mgr.NewPlugin(ds)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"database":"_internal","http":{"bearerToken":"new-token","basicAuth":{"user":"admin","password":"pwd"}}}`,
+		string(config))
+
+	// invalid secrets
+	ds.SecureConfig = []byte(`[]`)
+	_, err = mgr.NewPlugin(ds)
+	assert.Error(t, err)
+	ds.SecureConfig = nil
+	ds.Config = []byte(`[]`)
+	_, err = mgr.NewPlugin(ds)
+	assert.Error(t, err)
+}
+
+func TestManager_GetProxy(t *testing.T) {
+	mgr := NewDatasourceManager("key")
+	p, err := mgr.GetProxy(&model.Datasource{Type: model.SQLiteDatasource})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+	p, err = mgr.GetProxy(&model.Datasource{Type: model.LinDBDatasource, URL: "localhost"})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+	p, err = mgr.GetProxy(&model.Datasource{Type: model.LinDBDatasource, URL: "http://localhost", Config: []byte(`[]`)})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+
+	now := time.Now()
+	ds := &model.Datasource{UID: "1234", Type: model.LinDBDatasource, URL: "http://localhost",
+		BaseModel: model.BaseModel{UpdatedAt: now}}
+	// same version, hit cache
+	p1, err := mgr.GetProxy(ds)
+	assert.NoError(t, err)
+	p2, err := mgr.GetProxy(ds)
+	assert.NoError(t, err)
+	assert.Same(t, p1, p2)
+
+	// create new version failure, keep old version
+	p2, err = mgr.GetProxy(&model.Datasource{UID: "1234", Type: model.LinDBDatasource, URL: "localhost",
+		BaseModel: model.BaseModel{UpdatedAt: now.Add(time.Second)}})
+	assert.Error(t, err)
+	assert.Nil(t, p2)
+
+	// new version
+	p2, err = mgr.GetProxy(&model.Datasource{UID: "1234", Type: model.LinDBDatasource, URL: "http://localhost",
+		BaseModel: model.BaseModel{UpdatedAt: now.Add(time.Second)}})
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
+
+	// remove proxy
+	mgr.RemovePlugin("1234")
+	p1, err = mgr.GetProxy(ds)
+	assert.NoError(t, err)
+	assert.NotSame(t, p1, p2)
 }
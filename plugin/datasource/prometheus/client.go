@@ -0,0 +1,260 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+const (
+	// defaultScrapeInterval represents the default min step of range query.
+	defaultScrapeInterval = 15 * time.Second
+	// maxPoints represents the max points of range query for each series(Prometheus limit is 11000).
+	maxPoints = 11000
+	// metricNameLabel represents the label of metric name.
+	metricNameLabel = "__name__"
+	// valueField represents the value field name of time series frame.
+	valueField = "value"
+)
+
+// ProxyRoutes represents the Prometheus apis which datasource proxy is allowed to forward.
+var ProxyRoutes = plugin.ReadOnlyProxyRoutes("/api/v1/**")
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+	newRequestFn    = http.NewRequestWithContext
+	readAllFn       = io.ReadAll
+	nowFn           = time.Now
+)
+
+// client implements plugin.DatasourcePlugin for Prometheus.
+type client struct {
+	datasouce      *model.Datasource
+	cfg            *DatasourceConfig
+	scrapeInterval time.Duration
+	httpCli        *http.Client
+
+	logger logger.Logger
+}
+
+// NewClient creates a Prometheus client.
+func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	config := &DatasourceConfig{}
+	cfgData, _ := cfg.MarshalJSON()
+	if err0 := jsonUnmarshalFn(cfgData, config); err0 != nil {
+		return nil, err0
+	}
+	scrapeInterval := defaultScrapeInterval
+	if config.ScrapeInterval != "" {
+		scrapeInterval, err = parseDuration(config.ScrapeInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	httpCli, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		cfg:            config,
+		datasouce:      datasource,
+		scrapeInterval: scrapeInterval,
+		httpCli:        httpCli,
+		logger:         logger.GetLogger("DatasourcePlugin", "Prometheus"),
+	}, nil
+}
+
+// DataQuery runs range query(PromQL) with step.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	dataQueryReq := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &dataQueryReq); err != nil {
+		return nil, err
+	}
+	if dataQueryReq.Expr == "" {
+		return nil, fmt.Errorf("expr is required")
+	}
+	if timeRange.To <= 0 {
+		// time range without end means until now
+		timeRange.To = nowFn().UnixMilli()
+	}
+	step, err := cli.step(dataQueryReq.Step, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("query", dataQueryReq.Expr)
+	params.Set("start", formatTime(timeRange.From))
+	params.Set("end", formatTime(timeRange.To))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	plugin.RecordExecutedQuery(ctx, dataQueryReq.Expr)
+	rs := &QueryData{}
+	if err := cli.get(ctx, "/api/v1/query_range", params, rs); err != nil {
+		return nil, err
+	}
+	cli.logger.Info("data query", logger.String("expr", dataQueryReq.Expr), logger.Any("step", step))
+	frames, err := rs.toFrames()
+	if err != nil {
+		return nil, err
+	}
+	for _, frame := range frames {
+		frame.Meta.Interval = step.Milliseconds()
+		frame.Meta.ExecutedQuery = dataQueryReq.Expr
+	}
+	return frames, nil
+}
+
+// MetadataQuery queries label names/label values/series.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	for _, match := range metadataQueryReq.Match {
+		params.Add("match[]", match)
+	}
+	switch metadataQueryReq.Type {
+	case LabelNames:
+		var rs []string
+		if err := cli.get(ctx, "/api/v1/labels", params, &rs); err != nil {
+			return nil, err
+		}
+		return rs, nil
+	case LabelValues:
+		if metadataQueryReq.Label == "" {
+			return nil, fmt.Errorf("label is required")
+		}
+		var rs []string
+		if err := cli.get(ctx, fmt.Sprintf("/api/v1/label/%s/values", url.PathEscape(metadataQueryReq.Label)), params, &rs); err != nil {
+			return nil, err
+		}
+		return rs, nil
+	case Series:
+		if len(metadataQueryReq.Match) == 0 {
+			return nil, fmt.Errorf("match is required")
+		}
+		var rs []map[string]string
+		if err := cli.get(ctx, "/api/v1/series", params, &rs); err != nil {
+			return nil, err
+		}
+		return rs, nil
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+}
+
+// CheckHealth checks if Prometheus HTTP API answers.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	var rs map[string]any
+	return cli.get(ctx, "/api/v1/status/buildinfo", nil, &rs)
+}
+
+// Close closes the idle connections of http client.
+func (cli *client) Close() error {
+	cli.httpCli.CloseIdleConnections()
+	return nil
+}
+
+// get sends get request to Prometheus HTTP API, then unmarshals the data of response.
+func (cli *client) get(ctx context.Context, path string, params url.Values, data any) error {
+	endpoint := strings.TrimSuffix(cli.datasouce.URL, "/") + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	httpReq, err := newRequestFn(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.httpCli.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := readAllFn(resp.Body)
+	if err != nil {
+		return err
+	}
+	rs := &response{}
+	if err := jsonUnmarshalFn(body, rs); err != nil {
+		return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	if rs.Status != "success" {
+		return fmt.Errorf("%s: %s", rs.ErrorType, rs.Error)
+	}
+	return jsonUnmarshalFn(rs.Data, data)
+}
+
+// step returns the step of range query, if step not set, calculates it based on time range.
+func (cli *client) step(step string, timeRange model.TimeRange) (time.Duration, error) {
+	if step != "" {
+		rs, err := parseDuration(step)
+		if err != nil {
+			return 0, err
+		}
+		if rs <= 0 {
+			return 0, fmt.Errorf("step must be positive: %s", step)
+		}
+		return rs, nil
+	}
+	rs := cli.scrapeInterval
+	// make sure the points of each series not exceed the limit
+	if minStep := time.Duration(timeRange.To-timeRange.From) * time.Millisecond / maxPoints; minStep > rs {
+		rs = minStep.Truncate(time.Second) + time.Second
+	}
+	return rs, nil
+}
+
+// parseDuration parses duration string, supports Go duration(1m/30s) or seconds(15/0.5).
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+// formatTime formats timestamp(millisecond) as unix timestamp(seconds) for Prometheus.
+func formatTime(timestamp int64) string {
+	return strconv.FormatFloat(float64(timestamp)/1000, 'f', 3, 64)
+}
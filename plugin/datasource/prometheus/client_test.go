@@ -0,0 +1,379 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+)
+
+// newPrometheusServer creates a stand-in server that speaks the Prometheus HTTP API.
+func newPrometheusServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query_range", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("query") == "bad{" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		assert.Equal(t, "up", query.Get("query"))
+		assert.Equal(t, "1680000000.000", query.Get("start"))
+		assert.Equal(t, "1680003600.000", query.Get("end"))
+		assert.Equal(t, "60", query.Get("step"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[` +
+			`{"metric":{"__name__":"up","job":"node"},"values":[[1680000000,"1"],[1680000060,"0"]]}]}}`))
+	})
+	mux.HandleFunc("/api/v1/labels", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":["__name__","job"]}`))
+	})
+	mux.HandleFunc("/api/v1/label/job/values", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":["node","prometheus"]}`))
+	})
+	mux.HandleFunc("/api/v1/series", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"up"}, r.URL.Query()["match[]"])
+		_, _ = w.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"node"}]}`))
+	})
+	mux.HandleFunc("/api/v1/status/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"version":"2.43.0"}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_NewClient(t *testing.T) {
+	defer func() {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+	}()
+	t.Run("create client failure", func(t *testing.T) {
+		jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+			return fmt.Errorf("err")
+		}
+		cli, err := NewClient(&model.Datasource{}, []byte{})
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("invalid scrape interval", func(t *testing.T) {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		cli, err := NewClient(&model.Datasource{}, []byte(`{"scrapeInterval":"abc"}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("create client successfully", func(t *testing.T) {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		cli, err := NewClient(&model.Datasource{}, []byte(`{"scrapeInterval":"30s"}`))
+		assert.NoError(t, err)
+		assert.NotNil(t, cli)
+		assert.Equal(t, 30*time.Second, cli.(*client).scrapeInterval)
+	})
+}
+
+func TestClient_DataQuery(t *testing.T) {
+	server := newPrometheusServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{}`))
+	assert.NoError(t, err)
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680003600000}
+
+	cases := []struct {
+		name      string
+		req       string
+		timeRange *model.TimeRange
+		prepare   func()
+		assert    func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "expr is empty",
+			req:  `{}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid step",
+			req:  `{"expr":"up","step":"abc"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "negative step",
+			req:  `{"expr":"up","step":"-1s"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "new http request failure",
+			req:  `{"expr":"up","step":"1m"}`,
+			prepare: func() {
+				newRequestFn = func(_ context.Context, _, _ string, _ io.Reader) (*http.Request, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "read resp body failure",
+			req:  `{"expr":"up","step":"1m"}`,
+			prepare: func() {
+				readAllFn = func(_ io.Reader) ([]byte, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "query failure",
+			req:  `{"expr":"bad{","step":"1m"}`,
+			assert: func(_ any, err error) {
+				assert.EqualError(t, err, "bad_data: parse error")
+			},
+		},
+		{
+			name: "range query successfully",
+			req:  `{"expr":"up","step":"60"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frames := rs.(model.Frames)
+				assert.Len(t, frames, 1)
+				assert.Equal(t, "up", frames[0].Name)
+				assert.Equal(t, &model.FrameMeta{
+					Type:          model.FrameTypeTimeSeries,
+					Interval:      60000,
+					ExecutedQuery: "up",
+				}, frames[0].Meta)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frames[0].Fields[0].Values)
+				assert.Equal(t, map[string]string{"job": "node"}, frames[0].Fields[1].Labels)
+				assert.Equal(t, []any{1.0, 0.0}, frames[0].Fields[1].Values)
+			},
+		},
+		{
+			name:      "range query without end",
+			req:       `{"expr":"up","step":"60"}`,
+			timeRange: &model.TimeRange{From: 1680000000000},
+			prepare: func() {
+				nowFn = func() time.Time {
+					return time.UnixMilli(1680003600000)
+				}
+			},
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, rs.(model.Frames), 1)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+				newRequestFn = http.NewRequestWithContext
+				readAllFn = io.ReadAll
+				nowFn = time.Now
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			queryTimeRange := timeRange
+			if tt.timeRange != nil {
+				queryTimeRange = *tt.timeRange
+			}
+			rs, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, queryTimeRange)
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	server := newPrometheusServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{}`))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metadata type not support",
+			req:  `{"type":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "label names",
+			req:  `{"type":"labelNames"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"__name__", "job"}, rs)
+			},
+		},
+		{
+			name: "label values without label",
+			req:  `{"type":"labelValues"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "label values",
+			req:  `{"type":"labelValues","label":"job"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"node", "prometheus"}, rs)
+			},
+		},
+		{
+			name: "series without match",
+			req:  `{"type":"series"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "series",
+			req:  `{"type":"series","match":["up"]}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []map[string]string{{"__name__": "up", "job": "node"}}, rs)
+			},
+		},
+		{
+			name: "label values not found",
+			req:  `{"type":"labelValues","label":"not_found"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_step(t *testing.T) {
+	cli := &client{scrapeInterval: defaultScrapeInterval}
+	step, err := cli.step("", model.TimeRange{From: 0, To: time.Hour.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultScrapeInterval, step)
+	// 30 days
+	step, err = cli.step("", model.TimeRange{From: 0, To: 30 * 24 * time.Hour.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, 236*time.Second, step)
+	step, err = cli.step("0.5", model.TimeRange{})
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, step)
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := newPrometheusServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, cli.CheckHealth(context.TODO()))
+
+	cli, err = NewClient(&model.Datasource{URL: server.URL + "/not_found"}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Error(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
+
+func TestQueryData_toFrames(t *testing.T) {
+	cases := []struct {
+		name   string
+		values [][]any
+	}{
+		{name: "invalid point", values: [][]any{{1680000000.0}}},
+		{name: "invalid timestamp", values: [][]any{{"1680000000", "1"}}},
+		{name: "invalid value type", values: [][]any{{1680000000.0, 1.0}}},
+		{name: "invalid value", values: [][]any{{1680000000.0, "a"}}},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			data := &QueryData{Result: []*MatrixSeries{{Values: tt.values}}}
+			frames, err := data.toFrames()
+			assert.Error(t, err)
+			assert.Nil(t, frames)
+		})
+	}
+}
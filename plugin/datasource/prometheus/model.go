@@ -0,0 +1,113 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/lindb/linsight/model"
+)
+
+// MetadataType represents metadata type for Prometheus.
+type MetadataType = string
+
+// Defines all Prometheus's metadata types.
+var (
+	LabelNames  MetadataType = "labelNames"
+	LabelValues              = "labelValues"
+	Series                   = "series"
+)
+
+// DatasourceConfig represents datasource config for Prometheus.
+type DatasourceConfig struct {
+	// ScrapeInterval is the scrape interval of Prometheus, used as min step of range query(default 15s).
+	ScrapeInterval string `json:"scrapeInterval"`
+}
+
+// DataQueryRequest represents data query request for Prometheus.
+type DataQueryRequest struct {
+	Expr string `json:"expr"`
+	Step string `json:"step"`
+}
+
+// MetadataQueryRequest represents metadata query request for Prometheus.
+type MetadataQueryRequest struct {
+	Type  MetadataType `json:"type"`
+	Label string       `json:"label"`
+	Match []string     `json:"match"`
+}
+
+// QueryData represents the data of range query result.
+type QueryData struct {
+	ResultType string          `json:"resultType"`
+	Result     []*MatrixSeries `json:"result"`
+}
+
+// MatrixSeries represents a series of range query result.
+type MatrixSeries struct {
+	Metric map[string]string `json:"metric"`
+	// Values represents the points of series, point format: [unix timestamp(seconds), "value"].
+	Values [][]any `json:"values"`
+}
+
+// response represents the response envelope of Prometheus HTTP API.
+type response struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+// toFrames converts matrix result to time series frames, one frame per series,
+// metric name is frame name, other labels are field labels.
+func (rs *QueryData) toFrames() (model.Frames, error) {
+	frames := make(model.Frames, 0, len(rs.Result))
+	for _, series := range rs.Result {
+		labels := make(map[string]string, len(series.Metric))
+		for k, v := range series.Metric {
+			if k != metricNameLabel {
+				labels[k] = v
+			}
+		}
+		points := make(map[int64]float64, len(series.Values))
+		for _, point := range series.Values {
+			if len(point) != 2 {
+				return nil, fmt.Errorf("invalid point: %v", point)
+			}
+			timestamp, ok := point[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid point timestamp: %v", point[0])
+			}
+			value, ok := point[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid point value: %v", point[1])
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid point value: %s", value)
+			}
+			points[int64(math.Round(timestamp*1000))] = v
+		}
+		frames = append(frames, model.NewTimeSeriesFrame(series.Metric[metricNameLabel], labels,
+			map[string]map[int64]float64{valueField: points}))
+	}
+	return frames, nil
+}
@@ -0,0 +1,177 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+//go:generate mockgen -source=./proxy.go -destination=./proxy_mock.go -package=datasource
+
+// ErrProxyRouteNotAllowed represents the method/path of request is not allowed by datasource plugin.
+var ErrProxyRouteNotAllowed = errors.New("proxy route not allowed by datasource")
+
+// hopHeaders represents the headers of linsight which cannot be forwarded to datasource.
+var hopHeaders = []string{"Cookie", "Authorization"}
+
+// Proxy represents the proxy which forwards request to the url of datasource.
+type Proxy interface {
+	// Route returns the route which the method/path of request matches, returns ErrProxyRouteNotAllowed if not found,
+	// caller checks the permission of privileged route before forwarding.
+	Route(method, path string) (plugin.ProxyRoute, error)
+	// Forward forwards request to the path of datasource, returns ErrProxyRouteNotAllowed if the method/path
+	// of request not allowed or the request rejected by the check of route, the error of datasource is written
+	// to response.
+	Forward(w http.ResponseWriter, req *http.Request, path string) error
+}
+
+// proxy implements Proxy interface, injects the credentials of datasource by http client.
+type proxy struct {
+	routes  []plugin.ProxyRoute
+	config  json.RawMessage
+	target  *url.URL
+	client  *http.Client
+	reverse *httputil.ReverseProxy
+
+	logger logger.Logger
+}
+
+// newProxy creates a datasource proxy with the config(merged with secrets) of datasource.
+func newProxy(datasource *model.Datasource, cfg json.RawMessage, routes []plugin.ProxyRoute) (*proxy, error) {
+	target, err := url.Parse(datasource.URL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == "" || target.Host == "" {
+		return nil, errors.New("datasource url is invalid, need scheme and host")
+	}
+	client, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &proxy{
+		routes: routes,
+		config: json.RawMessage(datasource.Config),
+		target: target,
+		client: client,
+		logger: logger.GetLogger("Plugin", "DatasourceProxy"),
+	}
+	p.reverse = &httputil.ReverseProxy{
+		Director:       p.direct,
+		Transport:      client.Transport,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleError,
+	}
+	return p, nil
+}
+
+// Route returns the route which the method/path of request matches, returns ErrProxyRouteNotAllowed if not found.
+func (p *proxy) Route(method, path string) (plugin.ProxyRoute, error) {
+	route, ok := plugin.MatchProxyRoute(p.routes, method, path)
+	if !ok {
+		return plugin.ProxyRoute{}, ErrProxyRouteNotAllowed
+	}
+	return route, nil
+}
+
+// Forward forwards request to the path of datasource, returns ErrProxyRouteNotAllowed if the method/path
+// of request not allowed or the request rejected by the check of route, the error of datasource is written
+// to response.
+func (p *proxy) Forward(w http.ResponseWriter, req *http.Request, path string) error {
+	path = plugin.CleanProxyPath(path)
+	route, err := p.Route(req.Method, path)
+	if err != nil {
+		return err
+	}
+	ctx := req.Context()
+	if p.client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.client.Timeout)
+		defer cancel()
+	}
+	outReq := req.Clone(ctx)
+	if route.Check != nil {
+		if err := route.Check(outReq, path, p.config); err != nil {
+			return fmt.Errorf("%w: %s", ErrProxyRouteNotAllowed, err)
+		}
+	}
+	outReq.URL.Path = p.targetPath(path)
+	outReq.URL.RawPath = ""
+	p.reverse.ServeHTTP(w, outReq)
+	return nil
+}
+
+// targetPath returns the path of datasource url joined with the path of request,
+// "/" represents datasource url itself.
+func (p *proxy) targetPath(path string) string {
+	if path == "/" {
+		return p.target.Path
+	}
+	return strings.TrimSuffix(p.target.Path, "/") + path
+}
+
+// direct rewrites the request to datasource url, removes the credentials of linsight.
+func (p *proxy) direct(req *http.Request) {
+	req.URL.Scheme = p.target.Scheme
+	req.URL.Host = p.target.Host
+	req.Host = p.target.Host
+	if p.target.RawQuery != "" {
+		if req.URL.RawQuery == "" {
+			req.URL.RawQuery = p.target.RawQuery
+		} else {
+			req.URL.RawQuery = p.target.RawQuery + "&" + req.URL.RawQuery
+		}
+	}
+	for _, header := range hopHeaders {
+		req.Header.Del(header)
+	}
+	if _, ok := req.Header["User-Agent"]; !ok {
+		// explicitly disable User-Agent so it's not set to default value
+		req.Header.Set("User-Agent", "")
+	}
+}
+
+// modifyResponse removes the cookies of datasource, which would be set on linsight's domain.
+func (p *proxy) modifyResponse(resp *http.Response) error {
+	resp.Header.Del("Set-Cookie")
+	return nil
+}
+
+// handleError responses bad gateway if forward request failure.
+func (p *proxy) handleError(w http.ResponseWriter, req *http.Request, err error) {
+	p.logger.Warn("forward request to datasource failure",
+		logger.String("url", req.URL.String()), logger.Error(err))
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// closeIdleConnections closes the idle connections of http client.
+func (p *proxy) closeIdleConnections() {
+	p.client.CloseIdleConnections()
+}
@@ -0,0 +1,137 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+func TestProxy_New(t *testing.T) {
+	p, err := newProxy(&model.Datasource{URL: "://a"}, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, p)
+	p, err = newProxy(&model.Datasource{URL: "localhost:9000"}, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, p)
+	p, err = newProxy(&model.Datasource{URL: "http://localhost:9000"}, json.RawMessage(`{"http":{"timeout":"a"}}`), nil)
+	assert.Error(t, err)
+	assert.Nil(t, p)
+}
+
+func TestProxy_Forward(t *testing.T) {
+	var received *http.Request
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "datasource"})
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer svr.Close()
+
+	routes := []plugin.ProxyRoute{
+		{Method: http.MethodGet, Path: "/"},
+		{Method: http.MethodPut, Path: "/api/v1/exec"},
+		{Method: http.MethodPost, Path: "/*/_search", Check: func(_ *http.Request, path string, cfg json.RawMessage) error {
+			if !strings.HasPrefix(path, "/"+gjson.GetBytes(cfg, "index").String()+"/") {
+				return fmt.Errorf("index not allowed")
+			}
+			return nil
+		}},
+	}
+	p, err := newProxy(&model.Datasource{URL: svr.URL + "/pipeline?tenant=a", Config: []byte(`{"index":"logs"}`)},
+		json.RawMessage(`{"http":{"timeout":"10s","bearerToken":"token"}}`), routes)
+	assert.NoError(t, err)
+	defer p.closeIdleConnections()
+
+	forward := func(method, path, query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/api/v1/datasources/uid/proxy"+path+query, strings.NewReader("body"))
+		req.Header.Set("Cookie", "linsight=session")
+		req.Header.Set("Authorization", "Basic linsight")
+		resp := httptest.NewRecorder()
+		return resp, p.Forward(resp, req, path)
+	}
+
+	// route not allowed
+	received = nil
+	resp, err := forward(http.MethodDelete, "/api/v1/exec", "")
+	assert.ErrorIs(t, err, ErrProxyRouteNotAllowed)
+	assert.Nil(t, received)
+	_, err = forward(http.MethodPut, "/api/v1/state/../exec/..", "")
+	assert.ErrorIs(t, err, ErrProxyRouteNotAllowed)
+	// rejected by the check of route
+	_, err = forward(http.MethodPost, "/secrets/_search", "")
+	assert.ErrorIs(t, err, ErrProxyRouteNotAllowed)
+	assert.Nil(t, received)
+	route, err := p.Route(http.MethodPost, "/logs/_search")
+	assert.NoError(t, err)
+	assert.NotNil(t, route.Check)
+	resp, err = forward(http.MethodPost, "/logs/_search", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "/pipeline/logs/_search", received.URL.Path)
+
+	// forward to path of datasource url
+	resp, err = forward(http.MethodPut, "/api/v1/exec", "?db=_internal")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "ok", resp.Body.String())
+	assert.Empty(t, resp.Header().Get("Set-Cookie"))
+	assert.Equal(t, "/pipeline/api/v1/exec", received.URL.Path)
+	assert.Equal(t, "tenant=a&db=_internal", received.URL.RawQuery)
+	assert.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+	assert.Empty(t, received.Header.Get("Cookie"))
+
+	// forward to datasource url itself
+	resp, err = forward(http.MethodGet, "/", "?pipeline=trace")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "/pipeline", received.URL.Path)
+	assert.Equal(t, "tenant=a&pipeline=trace", received.URL.RawQuery)
+
+	// datasource not available
+	svr.Close()
+	resp, err = forward(http.MethodGet, "/", "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.Code)
+}
+
+func TestProxy_Forward_Canceled(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer svr.Close()
+
+	p, err := newProxy(&model.Datasource{URL: svr.URL}, nil, plugin.ReadOnlyProxyRoutes("/"))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(ctx)
+	resp := httptest.NewRecorder()
+	assert.NoError(t, p.Forward(resp, req, "/"))
+	assert.Equal(t, http.StatusBadGateway, resp.Code)
+}
@@ -0,0 +1,125 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/plugin"
+)
+
+// sharedPlugin wraps the cached datasource plugin which is shared by concurrent queries, counts the calls in flight,
+// the wrapped plugin is closed after it is evicted from cache and all calls in flight completed.
+type sharedPlugin struct {
+	plugin.DatasourcePlugin
+
+	uid     string
+	closeFn func()
+	refs    int
+	evicted bool
+	lock    sync.Mutex
+}
+
+// newSharedPlugin creates the shared datasource plugin, closeFn is invoked when the plugin can be closed.
+func newSharedPlugin(uid string, cli plugin.DatasourcePlugin, closeFn func()) *sharedPlugin {
+	return &sharedPlugin{
+		DatasourcePlugin: cli,
+		uid:              uid,
+		closeFn:          closeFn,
+	}
+}
+
+// DataQuery queries data by the wrapped plugin.
+func (p *sharedPlugin) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	if err := p.acquire(); err != nil {
+		return nil, err
+	}
+	defer p.release()
+	return p.DatasourcePlugin.DataQuery(ctx, req, timeRange)
+}
+
+// MetadataQuery queries metadata by the wrapped plugin.
+func (p *sharedPlugin) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	if err := p.acquire(); err != nil {
+		return nil, err
+	}
+	defer p.release()
+	return p.DatasourcePlugin.MetadataQuery(ctx, req)
+}
+
+// CheckHealth checks if datasource is working by the wrapped plugin.
+func (p *sharedPlugin) CheckHealth(ctx context.Context) error {
+	if err := p.acquire(); err != nil {
+		return err
+	}
+	defer p.release()
+	return p.DatasourcePlugin.CheckHealth(ctx)
+}
+
+// InterpolateVariables expands the variables of query request by the wrapped plugin.
+func (p *sharedPlugin) InterpolateVariables(req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error) {
+	return plugin.InterpolateVariables(p.DatasourcePlugin, req, vars)
+}
+
+// Close evicts the plugin, the wrapped plugin is closed after all calls in flight completed.
+func (p *sharedPlugin) Close() error {
+	p.evict()
+	return nil
+}
+
+// acquire marks a call in flight, returns error if the wrapped plugin is closed.
+func (p *sharedPlugin) acquire() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.evicted && p.refs == 0 {
+		return fmt.Errorf("datasource plugin '%s' is closed, please retry", p.uid)
+	}
+	p.refs++
+	return nil
+}
+
+// release marks a call completed, closes the wrapped plugin if it is the last call after evicted.
+func (p *sharedPlugin) release() {
+	p.lock.Lock()
+	p.refs--
+	closable := p.evicted && p.refs == 0
+	p.lock.Unlock()
+	if closable {
+		p.closeFn()
+	}
+}
+
+// evict marks the plugin evicted from cache, closes the wrapped plugin if no call in flight.
+func (p *sharedPlugin) evict() {
+	p.lock.Lock()
+	if p.evicted {
+		p.lock.Unlock()
+		return
+	}
+	p.evicted = true
+	closable := p.refs == 0
+	p.lock.Unlock()
+	if closable {
+		p.closeFn()
+	}
+}
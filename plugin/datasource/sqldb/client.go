@@ -0,0 +1,434 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/lindb/common/pkg/logger"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/plugin"
+)
+
+const (
+	timeColumn   = "time"
+	metricColumn = "metric"
+)
+
+var (
+	// sqlStringReplacer escapes single quote in string literal by doubling(SQL standard).
+	sqlStringReplacer = strings.NewReplacer(`'`, `''`)
+	// mysqlStringReplacer escapes backslash and single quote in string literal of MySQL.
+	mysqlStringReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+)
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+	openFn          = gorm.Open
+	loadLocationFn  = time.LoadLocation
+)
+
+// client implements plugin.DatasourcePlugin for relational database(MySQL/PostgreSQL/SQLite).
+type client struct {
+	datasouce *model.Datasource
+	cfg       *DatasourceConfig
+	db        *gorm.DB
+	location  *time.Location
+
+	logger logger.Logger
+}
+
+// NewClient creates a relational database client, data source name is the url of datasource.
+func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	config := &DatasourceConfig{}
+	cfgData, _ := cfg.MarshalJSON()
+	if err0 := jsonUnmarshalFn(cfgData, config); err0 != nil {
+		return nil, err0
+	}
+	location := time.UTC
+	if datasource.TimeZone != "" {
+		location, err = loadLocationFn(datasource.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var dialector gorm.Dialector
+	switch datasource.Type {
+	case model.MySQLDatasource:
+		dsnCfg, err0 := mysqldriver.ParseDSN(datasource.URL)
+		if err0 != nil {
+			return nil, err0
+		}
+		// scans DATE/DATETIME as time.Time for time column
+		dsnCfg.ParseTime = true
+		// only single statement can be executed
+		dsnCfg.MultiStatements = false
+		dialector = mysql.New(mysql.Config{
+			DSN: dsnCfg.FormatDSN(),
+			// not connect database when create client
+			SkipInitializeWithVersion: true,
+		})
+	case model.PostgreSQLDatasource:
+		dialector = postgres.Open(datasource.URL)
+	case model.SQLiteDatasource:
+		dsn, err0 := sqliteDSN(datasource.URL)
+		if err0 != nil {
+			return nil, err0
+		}
+		dialector = &sqlite.Dialector{DriverName: sqliteDriverName, DSN: dsn}
+	default:
+		return nil, fmt.Errorf("relational database not support, type: %s", datasource.Type)
+	}
+	db, err := openFn(dialector, &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	return &client{
+		datasouce: datasource,
+		cfg:       config,
+		db:        db,
+		location:  location,
+		logger:    logger.GetLogger("DatasourcePlugin", "SQL"),
+	}, nil
+}
+
+// DataQuery runs sql with time macros, returns time series or table result.
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	dataQueryReq := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &dataQueryReq); err != nil {
+		return nil, err
+	}
+	if dataQueryReq.SQL == "" {
+		return nil, fmt.Errorf("sql is required")
+	}
+	query, args, err := expandMacros(dataQueryReq.SQL, cli.datasouce.Type,
+		time.UnixMilli(timeRange.From).In(cli.location),
+		time.UnixMilli(timeRange.To).In(cli.location))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSelectStatement(query, cli.datasouce.Type); err != nil {
+		return nil, err
+	}
+	plugin.RecordExecutedQuery(ctx, query)
+	maxRows := 0
+	if dataQueryReq.Format == TimeSeriesFormat {
+		// each row has one point at least for time series
+		maxRows = plugin.GetQueryLimits(ctx).MaxPoints
+	}
+	table, err := cli.queryWithLimit(ctx, maxRows, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	cli.logger.Info("data query", logger.String("sql", query))
+	if dataQueryReq.Format == TimeSeriesFormat {
+		series, err := toTimeSeries(table, cli.location)
+		if err != nil {
+			return nil, err
+		}
+		frames := make(model.Frames, 0, len(series))
+		for _, s := range series {
+			frames = append(frames, s.toFrame())
+		}
+		return frames, nil
+	}
+	return model.Frames{table.toFrame()}, nil
+}
+
+// InterpolateVariables expands the variables of sql, the values are quoted as string literal by default
+// and escaped by the dialect of database for other formats except raw.
+func (cli *client) InterpolateVariables(req json.RawMessage, vars *interpolate.Variables) (json.RawMessage, error) {
+	dataQueryReq := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(req, &dataQueryReq); err != nil {
+		return nil, err
+	}
+	// MySQL treats backslash as escape character in string literal by default
+	format, escaper := interpolate.SQLString, sqlStringReplacer.Replace
+	if cli.datasouce.Type == model.MySQLDatasource {
+		format, escaper = interpolate.SingleQuote, mysqlStringReplacer.Replace
+	}
+	sql, err := vars.InterpolateWithEscaper(dataQueryReq.SQL, format, escaper)
+	if err != nil {
+		return nil, err
+	}
+	dataQueryReq.SQL = sql
+	return json.Marshal(dataQueryReq)
+}
+
+// MetadataQuery queries schema/table/column list.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
+		return nil, err
+	}
+	db := cli.db.WithContext(ctx)
+	switch metadataQueryReq.Type {
+	case Schema:
+		return cli.queryNames(ctx, cli.schemaSQL())
+	case Table:
+		if metadataQueryReq.Schema == "" || cli.datasouce.Type == model.SQLiteDatasource {
+			return db.Migrator().GetTables()
+		}
+		return cli.queryNames(ctx,
+			"SELECT table_name FROM information_schema.tables WHERE table_schema = ? ORDER BY table_name",
+			metadataQueryReq.Schema)
+	case Column:
+		if metadataQueryReq.Table == "" {
+			return nil, fmt.Errorf("table is required")
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(metadataQueryReq.Table)
+		if err != nil {
+			return nil, err
+		}
+		rs := make([]ColumnInfo, len(columnTypes))
+		for idx, columnType := range columnTypes {
+			rs[idx] = ColumnInfo{Name: columnType.Name(), Type: columnType.DatabaseTypeName()}
+		}
+		return rs, nil
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+}
+
+// CheckHealth pings database.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	sqlDB, err := cli.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the connection pool of database.
+func (cli *client) Close() error {
+	sqlDB, err := cli.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// schemaSQL returns the sql which queries schema list.
+func (cli *client) schemaSQL() string {
+	switch cli.datasouce.Type {
+	case model.MySQLDatasource:
+		return "SHOW DATABASES"
+	case model.PostgreSQLDatasource:
+		return "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name"
+	default:
+		return "SELECT name FROM pragma_database_list"
+	}
+}
+
+// queryNames queries the values of first column.
+func (cli *client) queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	table, err := cli.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rs = append(rs, fmt.Sprintf("%v", row[0]))
+	}
+	return rs, nil
+}
+
+// query runs sql with parameters, returns the result as table.
+func (cli *client) query(ctx context.Context, query string, args ...any) (*TableData, error) {
+	return cli.queryWithLimit(ctx, 0, query, args...)
+}
+
+// queryWithLimit runs sql with parameters in read only transaction, returns the result as table, stops reading
+// rows and returns error if the number of rows exceeds max rows(no limit if 0).
+func (cli *client) queryWithLimit(ctx context.Context, maxRows int, query string, args ...any) (*TableData, error) {
+	tx := cli.db.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer tx.Rollback()
+	rows, err := tx.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	rs := &TableData{Columns: make([]ColumnInfo, len(columnTypes))}
+	for idx, columnType := range columnTypes {
+		rs.Columns[idx] = ColumnInfo{Name: columnType.Name(), Type: columnType.DatabaseTypeName()}
+	}
+	for rows.Next() {
+		if maxRows > 0 && len(rs.Rows) >= maxRows {
+			return nil, fmt.Errorf("too many rows returned, limit: %d, "+
+				"please narrow the time range or increase the interval", maxRows)
+		}
+		values := make([]any, len(columnTypes))
+		dest := make([]any, len(columnTypes))
+		for idx := range values {
+			dest[idx] = &values[idx]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for idx, value := range values {
+			if v, ok := value.([]byte); ok {
+				values[idx] = string(v)
+			}
+		}
+		rs.Rows = append(rs.Rows, values)
+	}
+	return rs, rows.Err()
+}
+
+// toTimeSeries converts table result to time series, table must have "time" column,
+// if it has "metric" column, uses it as the name of series, other columns as value fields.
+func toTimeSeries(table *TableData, location *time.Location) ([]*TimeSeries, error) {
+	timeIdx, metricIdx := -1, -1
+	for idx, column := range table.Columns {
+		switch column.Name {
+		case timeColumn:
+			timeIdx = idx
+		case metricColumn:
+			metricIdx = idx
+		}
+	}
+	if timeIdx < 0 {
+		return nil, fmt.Errorf("time series format must have '%s' column", timeColumn)
+	}
+	var rs []*TimeSeries
+	seriesMap := make(map[string]*TimeSeries)
+	for _, row := range table.Rows {
+		timestamp, err := toTimestamp(row[timeIdx], location)
+		if err != nil {
+			return nil, err
+		}
+		metric := ""
+		if metricIdx >= 0 && row[metricIdx] != nil {
+			metric = fmt.Sprintf("%v", row[metricIdx])
+		}
+		for idx, column := range table.Columns {
+			if idx == timeIdx || idx == metricIdx {
+				continue
+			}
+			key := metric + "\x00" + column.Name
+			series, ok := seriesMap[key]
+			if !ok {
+				series = &TimeSeries{Metric: metric, Field: column.Name, Points: make(map[int64]*float64)}
+				seriesMap[key] = series
+				rs = append(rs, series)
+			}
+			value, err := toFloat(row[idx])
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': %w", column.Name, err)
+			}
+			series.Points[timestamp] = value
+		}
+	}
+	return rs, nil
+}
+
+// toTimestamp converts the value of time column to timestamp(millisecond), datetime string without
+// time zone is in the location of datasource, numeric value less than 1e11 is treated as epoch seconds.
+func toTimestamp(value any, location *time.Location) (int64, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixMilli(), nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UnixMilli(), nil
+		}
+		if t, err := time.ParseInLocation(time.DateTime, v, location); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	f, err := toFloat(value)
+	if err != nil || f == nil {
+		return 0, fmt.Errorf("invalid time value: %v", value)
+	}
+	if *f < 1e11 {
+		return int64(*f * 1000), nil
+	}
+	return int64(*f), nil
+}
+
+// toFloat converts numeric value to float64, returns nil if value is null.
+func toFloat(value any) (*float64, error) {
+	var rs float64
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		rs = float64(v)
+	case int32:
+		rs = float64(v)
+	case int:
+		rs = float64(v)
+	case uint64:
+		rs = float64(v)
+	case float64:
+		rs = v
+	case float32:
+		rs = float64(v)
+	case bool:
+		if v {
+			rs = 1
+		}
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not numeric value: %s", v)
+		}
+		rs = f
+	default:
+		return nil, fmt.Errorf("not numeric value: %v", v)
+	}
+	return &rs, nil
+}
@@ -0,0 +1,418 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/plugin"
+)
+
+// newSQLiteClient creates a client based on sqlite with orders table.
+func newSQLiteClient(t *testing.T) plugin.DatasourcePlugin {
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "orders.db")))
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec("CREATE TABLE orders (created_at DATETIME, region TEXT, amount REAL)").Error)
+	base := time.UnixMilli(1680000000000).UTC()
+	for i, region := range []string{"bj", "sh", "bj", "sh"} {
+		assert.NoError(t, db.Exec("INSERT INTO orders VALUES (?, ?, ?)",
+			base.Add(time.Duration(i/2)*time.Minute), region, float64(i+1)).Error)
+	}
+	SetSQLiteOptions(&SQLiteOptions{Dir: dir})
+	t.Cleanup(func() {
+		SetSQLiteOptions(nil)
+	})
+	cli, err := NewClient(&model.Datasource{
+		Type:     model.SQLiteDatasource,
+		URL:      "orders.db",
+		TimeZone: "UTC",
+	}, []byte(`{"maxOpenConns":2,"maxIdleConns":1}`))
+	assert.NoError(t, err)
+	return cli
+}
+
+func TestClient_NewClient(t *testing.T) {
+	defer func() {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		loadLocationFn = time.LoadLocation
+		openFn = gorm.Open
+	}()
+	t.Run("unmarshal config failure", func(t *testing.T) {
+		jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+			return fmt.Errorf("err")
+		}
+		cli, err := NewClient(&model.Datasource{}, []byte{})
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("load time location failure", func(t *testing.T) {
+		jsonUnmarshalFn = encoding.JSONUnmarshal
+		loadLocationFn = func(_ string) (*time.Location, error) {
+			return nil, fmt.Errorf("err")
+		}
+		cli, err := NewClient(&model.Datasource{TimeZone: "UTC"}, []byte(`{}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("database not support", func(t *testing.T) {
+		loadLocationFn = time.LoadLocation
+		cli, err := NewClient(&model.Datasource{Type: "oracle"}, []byte(`{}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("open database failure", func(t *testing.T) {
+		openFn = func(_ gorm.Dialector, _ ...gorm.Option) (*gorm.DB, error) {
+			return nil, fmt.Errorf("err")
+		}
+		cli, err := NewClient(&model.Datasource{Type: model.PostgreSQLDatasource}, []byte(`{}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("invalid mysql dsn", func(t *testing.T) {
+		openFn = gorm.Open
+		cli, err := NewClient(&model.Datasource{Type: model.MySQLDatasource, URL: "invalid"}, []byte(`{}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("sqlite database file not allowed", func(t *testing.T) {
+		openFn = gorm.Open
+		cli, err := NewClient(&model.Datasource{Type: model.SQLiteDatasource, URL: "linsight.db"}, []byte(`{}`))
+		assert.Error(t, err)
+		assert.Nil(t, cli)
+	})
+	t.Run("create client without connecting database", func(t *testing.T) {
+		openFn = gorm.Open
+		for _, ds := range []*model.Datasource{
+			{Type: model.MySQLDatasource, URL: "user:pwd@tcp(127.0.0.1:1)/db"},
+			{Type: model.PostgreSQLDatasource, URL: "host=127.0.0.1 port=1 user=u dbname=db"},
+		} {
+			cli, err := NewClient(ds, []byte(`{}`))
+			assert.NoError(t, err)
+			assert.NotNil(t, cli)
+		}
+	})
+}
+
+func TestClient_DataQuery(t *testing.T) {
+	cli := newSQLiteClient(t)
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680000060000}
+
+	cases := []struct {
+		name    string
+		req     string
+		limits  plugin.QueryLimits
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "sql is empty",
+			req:  `{}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "expand macro failure",
+			req:  `{"sql":"SELECT * FROM orders WHERE $__timeFilter()"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "not select statement",
+			req:  `{"sql":"SELECT * FROM orders; DELETE FROM orders"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "execute sql failure",
+			req:  `{"sql":"SELECT * FROM not_exist"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "table format",
+			req:  `{"sql":"SELECT region, sum(amount) AS total FROM orders WHERE $__timeFilter(created_at) GROUP BY region ORDER BY region","format":"table"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, model.FrameTypeTable, frame.Meta.Type)
+				assert.Equal(t, &model.Field{Name: "region", Type: model.FieldTypeString, Values: []any{"bj", "sh"}}, frame.Fields[0])
+				assert.Equal(t, &model.Field{Name: "total", Type: model.FieldTypeNumber, Values: []any{4.0, 6.0}}, frame.Fields[1])
+			},
+		},
+		{
+			name: "time series format without time column",
+			req:  `{"sql":"SELECT region FROM orders","format":"timeSeries"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "time series format with not numeric value",
+			req:  `{"sql":"SELECT $__timeGroup(created_at, 1m) AS time, region FROM orders","format":"timeSeries"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "time series format",
+			req: `{"sql":"SELECT $__timeGroup(created_at, 1m) AS time, region AS metric, sum(amount) AS total FROM orders ` +
+				`WHERE $__timeFilter(created_at) GROUP BY 1, 2 ORDER BY 1, 2","format":"timeSeries"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frames := rs.(model.Frames)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, "bj", frames[0].Name)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frames[0].Fields[0].Values)
+				assert.Equal(t, "total", frames[0].Fields[1].Name)
+				assert.Equal(t, []any{1.0, 3.0}, frames[0].Fields[1].Values)
+				assert.Equal(t, "sh", frames[1].Name)
+				assert.Equal(t, []any{2.0, 4.0}, frames[1].Fields[1].Values)
+			},
+		},
+		{
+			name:   "time series format with too many rows",
+			req:    `{"sql":"SELECT created_at AS time, amount FROM orders","format":"timeSeries"}`,
+			limits: plugin.QueryLimits{MaxPoints: 3},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:   "table format not limited by points",
+			req:    `{"sql":"SELECT created_at AS time, amount FROM orders","format":"table"}`,
+			limits: plugin.QueryLimits{MaxPoints: 3},
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 4, rs.(model.Frames)[0].Rows())
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.DataQuery(plugin.WithQueryLimits(context.TODO(), tt.limits), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, timeRange)
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	cli := newSQLiteClient(t)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metadata type not support",
+			req:  `{"type":"index"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "schema list",
+			req:  `{"type":"schema"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"main"}, rs)
+			},
+		},
+		{
+			name: "table list",
+			req:  `{"type":"table","schema":"main"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"orders"}, rs)
+			},
+		},
+		{
+			name: "table is empty",
+			req:  `{"type":"column"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "column list",
+			req:  `{"type":"column","table":"orders"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []ColumnInfo{
+					{Name: "created_at", Type: "DATETIME"},
+					{Name: "region", Type: "TEXT"},
+					{Name: "amount", Type: "REAL"},
+				}, rs)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_toTimestamp(t *testing.T) {
+	ts, err := toTimestamp(int64(1680000000), time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1680000000000), ts)
+	ts, err = toTimestamp(float64(1680000000000), time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1680000000000), ts)
+	ts, err = toTimestamp("2023-03-28T10:40:00Z", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1680000000000), ts)
+	ts, err = toTimestamp("2023-03-28 10:40:00", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1680000000000), ts)
+	ts, err = toTimestamp("2023-03-28 18:40:00", time.FixedZone("CST", 8*3600))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1680000000000), ts)
+	_, err = toTimestamp(nil, time.UTC)
+	assert.Error(t, err)
+	_, err = toTimestamp("abc", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	cli := newSQLiteClient(t)
+	assert.NoError(t, cli.CheckHealth(context.TODO()))
+
+	cli, err := NewClient(&model.Datasource{
+		Type: model.MySQLDatasource,
+		URL:  "user:pwd@tcp(127.0.0.1:1)/db?timeout=100ms",
+	}, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Error(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestClient_InterpolateVariables(t *testing.T) {
+	cli := newSQLiteClient(t)
+	vars, err := interpolate.NewVariables(map[string]any{"region": []any{"bj", "x' or '1'='1"}})
+	assert.NoError(t, err)
+	req, err := cli.(plugin.VariableInterpolator).InterpolateVariables(
+		json.RawMessage(`{"sql":"SELECT region, amount FROM orders WHERE region IN ($region) ORDER BY amount"}`), vars)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sql":"SELECT region, amount FROM orders WHERE region IN ('bj','x'' or ''1''=''1') ORDER BY amount","format":""}`,
+		string(req))
+	frames, err := cli.DataQuery(context.TODO(), &model.Query{Request: req}, model.TimeRange{})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"bj", "bj"}, frames[0].Fields[0].Values)
+
+	// MySQL escapes backslash
+	mysqlCli := &client{datasouce: &model.Datasource{Type: model.MySQLDatasource}}
+	vars, err = interpolate.NewVariables(map[string]any{"region": `x\' or 1=1`})
+	assert.NoError(t, err)
+	req, err = mysqlCli.InterpolateVariables(json.RawMessage(`{"sql":"WHERE region = $region or region like '${region:csv}%'"}`), vars)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sql":"WHERE region = 'x\\\\\\' or 1=1' or region like 'x\\\\\\' or 1=1%'","format":""}`, string(req))
+
+	for _, req := range []string{`{`, `{"sql":"${region:unknown}"}`} {
+		rs, err := mysqlCli.InterpolateVariables(json.RawMessage(req), vars)
+		assert.Error(t, err)
+		assert.Nil(t, rs)
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	cli := newSQLiteClient(t)
+	assert.NoError(t, cli.Close())
+	assert.Error(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestTableData_toFrame(t *testing.T) {
+	now := time.UnixMilli(1680000000000)
+	table := &TableData{
+		Columns: []ColumnInfo{{Name: "time"}, {Name: "count"}, {Name: "ok"}, {Name: "mixed"}, {Name: "empty"}},
+		Rows: [][]any{
+			{now, int64(1), true, "a", nil},
+			{nil, int64(2), false, int64(1), nil},
+		},
+	}
+	frame := table.toFrame()
+	assert.NoError(t, frame.Validate())
+	assert.Equal(t, model.FieldTypeTime, frame.Fields[0].Type)
+	assert.Equal(t, []any{int64(1680000000000), nil}, frame.Fields[0].Values)
+	assert.Equal(t, model.FieldTypeInteger, frame.Fields[1].Type)
+	assert.Equal(t, model.FieldTypeBoolean, frame.Fields[2].Type)
+	assert.Equal(t, model.FieldTypeOther, frame.Fields[3].Type)
+	assert.Equal(t, []any{"a", int64(1)}, frame.Fields[3].Values)
+	assert.Equal(t, model.FieldTypeOther, frame.Fields[4].Type)
+}
@@ -0,0 +1,97 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// macroRegexp matches the macros: $__timeFilter(column)/$__timeGroup(column, interval)/$__timeFrom()/$__timeTo().
+var macroRegexp = regexp.MustCompile(`\$__(timeFilter|timeGroup|timeFrom|timeTo)\(([^)]*)\)`)
+
+// expandMacros expands the time macros of sql, time range values are bound as parameters.
+//
+//	$__timeFilter(column)          => column BETWEEN ? AND ?
+//	$__timeGroup(column, interval) => epoch(seconds) of column aligned by interval
+//	$__timeFrom()/$__timeTo()      => ?
+func expandMacros(sql, dialect string, from, to time.Time) (string, []any, error) {
+	var (
+		args []any
+		err  error
+	)
+	rs := macroRegexp.ReplaceAllStringFunc(sql, func(macro string) string {
+		if err != nil {
+			return macro
+		}
+		matches := macroRegexp.FindStringSubmatch(macro)
+		var params []string
+		for _, param := range strings.Split(matches[2], ",") {
+			if param = strings.TrimSpace(param); param != "" {
+				params = append(params, param)
+			}
+		}
+		switch matches[1] {
+		case "timeFilter":
+			if len(params) != 1 {
+				err = fmt.Errorf("macro $__timeFilter needs column, e.g. $__timeFilter(created_at)")
+				return macro
+			}
+			args = append(args, from, to)
+			return fmt.Sprintf("%s BETWEEN ? AND ?", params[0])
+		case "timeGroup":
+			if len(params) != 2 {
+				err = fmt.Errorf("macro $__timeGroup needs column and interval, e.g. $__timeGroup(created_at, 1m)")
+				return macro
+			}
+			interval, err0 := time.ParseDuration(params[1])
+			if err0 != nil || interval < time.Second {
+				err = fmt.Errorf("invalid interval of macro $__timeGroup: %s", params[1])
+				return macro
+			}
+			var group string
+			group, err = timeGroup(dialect, params[0], int64(interval.Seconds()))
+			return group
+		case "timeFrom":
+			args = append(args, from)
+		default:
+			args = append(args, to)
+		}
+		return "?"
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return rs, args, nil
+}
+
+// timeGroup returns the expression which converts column to epoch(seconds) aligned by interval.
+func timeGroup(dialect, column string, seconds int64) (string, error) {
+	switch dialect {
+	case "mysql":
+		return fmt.Sprintf("UNIX_TIMESTAMP(%s) DIV %d * %d", column, seconds, seconds), nil
+	case "postgres":
+		return fmt.Sprintf("floor(extract(epoch from %s)/%d)*%d", column, seconds, seconds), nil
+	case "sqlite":
+		return fmt.Sprintf("cast(strftime('%%s', %s) as integer)/%d*%d", column, seconds, seconds), nil
+	default:
+		return "", fmt.Errorf("macro $__timeGroup not support, dialect: %s", dialect)
+	}
+}
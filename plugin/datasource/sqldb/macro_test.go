@@ -0,0 +1,70 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMacro_expandMacros(t *testing.T) {
+	from := time.UnixMilli(1680000000000)
+	to := time.UnixMilli(1680003600000)
+
+	sql, args, err := expandMacros("SELECT $__timeGroup(created_at, 1m) AS time, count(*) FROM orders "+
+		"WHERE $__timeFilter(created_at) AND updated_at < $__timeTo() GROUP BY 1", "mysql", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT UNIX_TIMESTAMP(created_at) DIV 60 * 60 AS time, count(*) FROM orders "+
+		"WHERE created_at BETWEEN ? AND ? AND updated_at < ? GROUP BY 1", sql)
+	assert.Equal(t, []any{from, to, to}, args)
+
+	sql, args, err = expandMacros("SELECT $__timeGroup(ts,5m) FROM t WHERE ts > $__timeFrom()", "postgres", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT floor(extract(epoch from ts)/300)*300 FROM t WHERE ts > ?", sql)
+	assert.Equal(t, []any{from}, args)
+
+	sql, _, err = expandMacros("SELECT $__timeGroup(ts, 1h) FROM t", "sqlite", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT cast(strftime('%s', ts) as integer)/3600*3600 FROM t", sql)
+
+	sql, args, err = expandMacros("SELECT 1", "sqlite", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT 1", sql)
+	assert.Empty(t, args)
+}
+
+func TestMacro_expandMacros_Failure(t *testing.T) {
+	cases := []struct {
+		sql     string
+		dialect string
+	}{
+		{sql: "SELECT * FROM t WHERE $__timeFilter()", dialect: "mysql"},
+		{sql: "SELECT $__timeGroup(ts) FROM t", dialect: "mysql"},
+		{sql: "SELECT $__timeGroup(ts, abc) FROM t", dialect: "mysql"},
+		{sql: "SELECT $__timeGroup(ts, 1ms) FROM t", dialect: "mysql"},
+		{sql: "SELECT $__timeGroup(ts, 1m) FROM t", dialect: "oracle"},
+	}
+	for _, tt := range cases {
+		sql, args, err := expandMacros(tt.sql, tt.dialect, time.Now(), time.Now())
+		assert.Error(t, err, tt.sql)
+		assert.Empty(t, sql)
+		assert.Empty(t, args)
+	}
+}
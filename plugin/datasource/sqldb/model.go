@@ -0,0 +1,139 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"time"
+
+	"github.com/lindb/linsight/model"
+)
+
+// MetadataType represents metadata type for relational database.
+type MetadataType = string
+
+// Defines all relational database's metadata types.
+var (
+	Schema MetadataType = "schema"
+	Table               = "table"
+	Column              = "column"
+)
+
+// Format represents the result format of data query.
+type Format = string
+
+// Defines all result formats.
+var (
+	TimeSeriesFormat Format = "timeSeries"
+	TableFormat             = "table"
+)
+
+// DatasourceConfig represents datasource config for relational database.
+type DatasourceConfig struct {
+	MaxOpenConns int `json:"maxOpenConns"`
+	MaxIdleConns int `json:"maxIdleConns"`
+}
+
+// DataQueryRequest represents data query request for relational database.
+type DataQueryRequest struct {
+	SQL    string `json:"sql"`
+	Format Format `json:"format"`
+}
+
+// MetadataQueryRequest represents metadata query request for relational database.
+type MetadataQueryRequest struct {
+	Type   MetadataType `json:"type"`
+	Schema string       `json:"schema"`
+	Table  string       `json:"table"`
+}
+
+// ColumnInfo represents the column information of table.
+type ColumnInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TableData represents the table result of data query.
+type TableData struct {
+	Columns []ColumnInfo `json:"columns"`
+	Rows    [][]any      `json:"rows"`
+}
+
+// TimeSeries represents the time series result of data query.
+type TimeSeries struct {
+	// Metric represents the value of "metric" column, empty if not exist.
+	Metric string `json:"metric,omitempty"`
+	// Field represents the value column name.
+	Field string `json:"field"`
+	// Points represents the points of series, key: timestamp(millisecond).
+	Points map[int64]*float64 `json:"points"`
+}
+
+// toFrame converts table to table frame, field type is decided by the first not null value of column,
+// uses other type if values of column have different types.
+func (table *TableData) toFrame() *model.Frame {
+	frame := model.NewFrame("")
+	for idx, column := range table.Columns {
+		field := model.NewField(column.Name, columnFieldType(table.Rows, idx), nil)
+		for _, row := range table.Rows {
+			if err := field.Append(row[idx]); err != nil {
+				field.Type = model.FieldTypeOther
+				field.Values = nil
+				for _, r := range table.Rows {
+					field.Values = append(field.Values, r[idx])
+				}
+				break
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame.SetMeta(&model.FrameMeta{Type: model.FrameTypeTable})
+}
+
+// columnFieldType returns field type by the first not null value of column.
+func columnFieldType(rows [][]any, idx int) model.FieldType {
+	for _, row := range rows {
+		switch row[idx].(type) {
+		case nil:
+			continue
+		case time.Time:
+			return model.FieldTypeTime
+		case int64, int32, int, uint64:
+			return model.FieldTypeInteger
+		case float64, float32:
+			return model.FieldTypeNumber
+		case string:
+			return model.FieldTypeString
+		case bool:
+			return model.FieldTypeBoolean
+		default:
+			return model.FieldTypeOther
+		}
+	}
+	return model.FieldTypeOther
+}
+
+// toFrame converts time series to time series frame, null point is ignored.
+func (series *TimeSeries) toFrame() *model.Frame {
+	points := make(map[int64]float64, len(series.Points))
+	for timestamp, value := range series.Points {
+		if value != nil {
+			points[timestamp] = *value
+		}
+	}
+	return model.NewTimeSeriesFrame(series.Metric, nil, map[string]map[int64]float64{series.Field: points})
+}
@@ -0,0 +1,105 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName represents the driver name of SQLite datasource which denies attaching database.
+const sqliteDriverName = "sqlite3_datasource"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// ATTACH DATABASE can open(or create) any file, bypasses the allowed directory and read only mode
+			conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+			conn.RegisterAuthorizer(func(action int, _, _, _ string) int {
+				if action == sqlite3.SQLITE_ATTACH || action == sqlite3.SQLITE_DETACH {
+					return sqlite3.SQLITE_DENY
+				}
+				return sqlite3.SQLITE_OK
+			})
+			return nil
+		},
+	})
+}
+
+// SQLiteOptions represents the server side restrictions of SQLite datasource.
+type SQLiteOptions struct {
+	// Dir represents the directory which the database files must be in, SQLite datasource is disabled if empty.
+	Dir string
+	// ExcludeFiles represents the database files which cannot be opened(e.g. the database of linsight).
+	ExcludeFiles []string
+}
+
+var sqliteOptions atomic.Pointer[SQLiteOptions]
+
+// SetSQLiteOptions sets the server side restrictions of SQLite datasource.
+func SetSQLiteOptions(opts *SQLiteOptions) {
+	sqliteOptions.Store(opts)
+}
+
+// sqliteDSN returns the read only data source name of SQLite database file, the file must be in the
+// allowed directory and not be excluded.
+func sqliteDSN(url string) (string, error) {
+	opts := sqliteOptions.Load()
+	if opts == nil || opts.Dir == "" {
+		return "", errors.New("SQLite datasource is disabled, sqlite-dir is not configured")
+	}
+	dir, err := resolvePath(opts.Dir)
+	if err != nil {
+		return "", err
+	}
+	file := strings.TrimPrefix(url, "file:")
+	if file == "" || strings.ContainsAny(file, "?#") {
+		return "", fmt.Errorf("invalid SQLite database file: %s", url)
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	if file, err = resolvePath(file); err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, file); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("SQLite database file must be in directory: %s", opts.Dir)
+	}
+	for _, exclude := range opts.ExcludeFiles {
+		exclude, _, _ = strings.Cut(strings.TrimPrefix(exclude, "file:"), "?")
+		if excludeFile, err := resolvePath(exclude); err == nil && excludeFile == file {
+			return "", fmt.Errorf("SQLite database file not allowed: %s", url)
+		}
+	}
+	return "file:" + file + "?mode=ro", nil
+}
+
+// resolvePath returns the absolute path which symbolic links are evaluated.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
@@ -0,0 +1,143 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLite_sqliteDSN(t *testing.T) {
+	defer SetSQLiteOptions(nil)
+	dir := t.TempDir()
+	outside := t.TempDir()
+	for _, name := range []string{"data.db", "linsight.db"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "other.db"), nil, 0o600))
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "other.db"), filepath.Join(dir, "link.db")))
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		opts    *SQLiteOptions
+		url     string
+		wantDSN string
+		wantErr bool
+	}{
+		{
+			name:    "sqlite disabled",
+			url:     "data.db",
+			wantErr: true,
+		},
+		{
+			name:    "sqlite dir not set",
+			opts:    &SQLiteOptions{},
+			url:     "data.db",
+			wantErr: true,
+		},
+		{
+			name:    "relative file",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "data.db",
+			wantDSN: "file:" + filepath.Join(resolvedDir, "data.db") + "?mode=ro",
+		},
+		{
+			name:    "absolute file with file prefix",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "file:" + filepath.Join(dir, "data.db"),
+			wantDSN: "file:" + filepath.Join(resolvedDir, "data.db") + "?mode=ro",
+		},
+		{
+			name:    "empty file",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "file:",
+			wantErr: true,
+		},
+		{
+			name:    "file with query params",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "data.db?mode=rw",
+			wantErr: true,
+		},
+		{
+			name:    "file outside dir",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     filepath.Join(outside, "other.db"),
+			wantErr: true,
+		},
+		{
+			name:    "file escape dir",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "../" + filepath.Base(outside) + "/other.db",
+			wantErr: true,
+		},
+		{
+			name:    "symbolic link to file outside dir",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "link.db",
+			wantErr: true,
+		},
+		{
+			name:    "file not exist",
+			opts:    &SQLiteOptions{Dir: dir},
+			url:     "not_exist.db",
+			wantErr: true,
+		},
+		{
+			name:    "excluded file",
+			opts:    &SQLiteOptions{Dir: dir, ExcludeFiles: []string{"file:" + filepath.Join(dir, "linsight.db") + "?cache=shared"}},
+			url:     "linsight.db",
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			SetSQLiteOptions(tt.opts)
+			dsn, err := sqliteDSN(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDSN, dsn)
+		})
+	}
+}
+
+func TestSQLite_denyAttach(t *testing.T) {
+	cli := newSQLiteClient(t).(*client)
+	file := filepath.Join(t.TempDir(), "attached.db")
+
+	// bypasses the statement check of data query
+	err := cli.db.Exec("ATTACH DATABASE ? AS other", file).Error
+	assert.Error(t, err)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+	assert.Error(t, cli.db.Exec("CREATE TABLE other_orders (id INTEGER)").Error)
+
+	table, err := cli.query(context.TODO(), "SELECT count(*) FROM orders")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), table.Rows[0][0])
+}
@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeKeywords represents the keywords which modify data in SELECT statement, e.g. data-modifying statement
+// in WITH clause of PostgreSQL, SELECT ... INTO creates table or writes file.
+var writeKeywords = map[string]struct{}{
+	"insert": {}, "update": {}, "delete": {}, "merge": {}, "into": {},
+}
+
+// checkSelectStatement checks if sql is a single SELECT statement(optional WITH clause), the string literals,
+// quoted identifiers and comments are skipped by the lexical rules of dialect.
+func checkSelectStatement(sql, dialect string) error {
+	var (
+		first string
+		ended bool
+	)
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case isSpace(c):
+			i++
+			continue
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#' && dialect == "mysql":
+			i = skipTo(sql, i, "\n")
+			continue
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if dialect == "mysql" && strings.HasPrefix(sql[i:], "/*!") {
+				// executable comment of MySQL, checks the content as sql
+				i += 3
+				continue
+			}
+			i = skipTo(sql, i+2, "*/")
+			continue
+		case c == ';':
+			ended = true
+			i++
+			continue
+		}
+		if ended {
+			return fmt.Errorf("only single SELECT statement is allowed")
+		}
+		switch {
+		case c == '\'':
+			// string literal, MySQL and escape string constant(E'...') of PostgreSQL support backslash escape
+			escape := dialect == "mysql" ||
+				(dialect == "postgres" && i > 0 && (sql[i-1] == 'e' || sql[i-1] == 'E') && (i < 2 || !isWordChar(sql[i-2])))
+			i = skipQuoted(sql, i, '\'', escape)
+		case c == '"':
+			i = skipQuoted(sql, i, '"', dialect == "mysql")
+		case c == '`' && dialect != "postgres":
+			i = skipQuoted(sql, i, '`', false)
+		case c == '[' && dialect == "sqlite":
+			i = skipTo(sql, i+1, "]")
+		case c == '$' && dialect == "postgres" && (i == 0 || !isWordChar(sql[i-1])):
+			i = skipDollarQuoted(sql, i)
+		case isWordChar(c) && !isDigit(c):
+			start := i
+			for i < len(sql) && (isWordChar(sql[i]) || sql[i] == '$') {
+				i++
+			}
+			word := strings.ToLower(sql[start:i])
+			if first == "" {
+				first = word
+				if first != "select" && first != "with" {
+					return fmt.Errorf("only SELECT statement is allowed")
+				}
+			}
+			if _, ok := writeKeywords[word]; ok {
+				return fmt.Errorf("keyword '%s' is not allowed, only SELECT statement is allowed", word)
+			}
+		default:
+			i++
+		}
+	}
+	if first == "" {
+		return fmt.Errorf("only SELECT statement is allowed")
+	}
+	return nil
+}
+
+// skipQuoted returns the position after the quoted string which starts at pos, doubled quote is escaped.
+func skipQuoted(sql string, pos int, quote byte, backslashEscape bool) int {
+	for i := pos + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if backslashEscape {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted returns the position after the dollar-quoted string($tag$...$tag$) of PostgreSQL,
+// returns the next position if it isn't dollar-quoted string(e.g. positional parameter $1).
+func skipDollarQuoted(sql string, pos int) int {
+	end := pos + 1
+	for end < len(sql) && isWordChar(sql[end]) && (end > pos+1 || !isDigit(sql[end])) {
+		end++
+	}
+	if end >= len(sql) || sql[end] != '$' {
+		return pos + 1
+	}
+	return skipTo(sql, end+1, sql[pos:end+1])
+}
+
+// skipTo returns the position after the first terminator from pos, returns the length of sql if not found.
+func skipTo(sql string, pos int, terminator string) int {
+	idx := strings.Index(sql[pos:], terminator)
+	if idx < 0 {
+		return len(sql)
+	}
+	return pos + idx + len(terminator)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,68 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatement_checkSelectStatement(t *testing.T) {
+	cases := []struct {
+		name    string
+		sql     string
+		dialect string
+		wantErr bool
+	}{
+		{name: "select", sql: "SELECT * FROM orders", dialect: "sqlite"},
+		{name: "select with comment and trailing semicolon", sql: "-- orders\n/* all */ select * from orders;  ", dialect: "mysql"},
+		{name: "with clause", sql: "WITH t AS (SELECT 1 AS v) SELECT v FROM t", dialect: "postgres"},
+		{name: "keyword in literal", sql: "SELECT 'delete; drop table orders' AS \"insert\", `update` FROM orders", dialect: "mysql"},
+		{name: "keyword in dollar quoted string", sql: "SELECT $tag$ ' ; delete $tag$, $1 FROM orders", dialect: "postgres"},
+		{name: "empty", sql: " -- comment", dialect: "sqlite", wantErr: true},
+		{name: "not select", sql: "DELETE FROM orders", dialect: "sqlite", wantErr: true},
+		{name: "attach", sql: "ATTACH DATABASE '/tmp/x.db' AS x", dialect: "sqlite", wantErr: true},
+		{name: "multiple statements", sql: "SELECT 1; DROP TABLE orders", dialect: "postgres", wantErr: true},
+		{name: "select into", sql: "SELECT * INTO OUTFILE '/tmp/orders' FROM orders", dialect: "mysql", wantErr: true},
+		{
+			name:    "data modifying with clause",
+			sql:     "WITH d AS (DELETE FROM orders RETURNING *) SELECT * FROM d",
+			dialect: "postgres",
+			wantErr: true,
+		},
+		{name: "backslash escape of mysql", sql: `SELECT 'a\'; DELETE FROM orders; -- '`, dialect: "mysql"},
+		{name: "escaped backslash of mysql", sql: `SELECT 'a\\'; DELETE FROM orders`, dialect: "mysql", wantErr: true},
+		{name: "backslash not escape of postgres", sql: `SELECT 'a\'; DELETE FROM orders; -- '`, dialect: "postgres", wantErr: true},
+		{name: "escape string of postgres", sql: `SELECT E'a\'; DELETE FROM orders; -- '`, dialect: "postgres"},
+		{name: "dollar quote not for mysql", sql: "SELECT 1 AS $a$; DELETE FROM orders; SELECT $a$", dialect: "mysql", wantErr: true},
+		{name: "hash is not comment of postgres", sql: "SELECT 1 # 2; DELETE FROM orders", dialect: "postgres", wantErr: true},
+		{name: "executable comment of mysql", sql: "SELECT 1 /*! ; DELETE FROM orders */", dialect: "mysql", wantErr: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSelectStatement(tt.sql, tt.dialect)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
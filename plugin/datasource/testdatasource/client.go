@@ -0,0 +1,306 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testdatasource
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/models"
+	"github.com/lindb/common/pkg/encoding"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+const (
+	defaultMaxDataPoints = 300
+	defaultGapRatio      = 0.3
+	defaultErrorMessage  = "synthetic error from testdata datasource"
+	metricName           = "testdata"
+	seriesTagKey         = "series"
+	valueField           = "value"
+	// maxSeriesCount represents the max number of series generated by one query.
+	maxSeriesCount = 100
+	// maxPoints represents the max number of points(all series) generated by one query.
+	maxPoints = 100000
+)
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+)
+
+// client implements plugin.DatasourcePlugin for synthetic data, no backend needed.
+type client struct {
+	datasouce *model.Datasource
+}
+
+// NewClient creates a synthetic data client.
+func NewClient(datasource *model.Datasource, _ json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	return &client{
+		datasouce: datasource,
+	}, nil
+}
+
+// DataQuery generates synthetic data over the time range, returns the data as time series frames.
+func (cli *client) DataQuery(_ context.Context, req *model.Query, timeRange model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	dataQueryReq := &DataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &dataQueryReq); err != nil {
+		return nil, err
+	}
+	if dataQueryReq.Scenario == Error {
+		if dataQueryReq.ErrorMessage == "" {
+			return nil, errors.New(defaultErrorMessage)
+		}
+		return nil, errors.New(dataQueryReq.ErrorMessage)
+	}
+	if dataQueryReq.Scenario == CSVContent {
+		rs, err := parseCSV(dataQueryReq.CSVContent)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewFramesFromResultSet(rs), nil
+	}
+	if timeRange.To <= timeRange.From {
+		return nil, fmt.Errorf("invalid time range, from: %d, to: %d", timeRange.From, timeRange.To)
+	}
+	interval, err := getInterval(dataQueryReq, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	var generate func(r *rand.Rand, idx int, timestamps []int64) map[int64]float64
+	switch dataQueryReq.Scenario {
+	case RandomWalk:
+		generate = func(r *rand.Rand, _ int, timestamps []int64) map[int64]float64 {
+			return randomWalk(r, dataQueryReq, timestamps, 0)
+		}
+	case NullGaps:
+		gapRatio := dataQueryReq.GapRatio
+		if gapRatio <= 0 || gapRatio >= 1 {
+			gapRatio = defaultGapRatio
+		}
+		generate = func(r *rand.Rand, _ int, timestamps []int64) map[int64]float64 {
+			return randomWalk(r, dataQueryReq, timestamps, gapRatio)
+		}
+	case Sine:
+		period := time.Duration(timeRange.To-timeRange.From) * time.Millisecond / 2
+		if dataQueryReq.Period != "" {
+			period, err = time.ParseDuration(dataQueryReq.Period)
+			if err != nil || period < time.Millisecond {
+				return nil, fmt.Errorf("invalid period: %s", dataQueryReq.Period)
+			}
+		}
+		if period < time.Millisecond {
+			period = time.Millisecond
+		}
+		generate = func(_ *rand.Rand, idx int, timestamps []int64) map[int64]float64 {
+			return sine(dataQueryReq, period, idx, timestamps)
+		}
+	default:
+		return nil, fmt.Errorf("scenario not support, scenario: %s", dataQueryReq.Scenario)
+	}
+	seriesCount := dataQueryReq.SeriesCount
+	if seriesCount <= 0 {
+		seriesCount = 1
+	}
+	if seriesCount > maxSeriesCount {
+		seriesCount = maxSeriesCount
+	}
+	if points := ((timeRange.To-timeRange.From)/interval.Milliseconds() + 1) * int64(seriesCount); points > maxPoints {
+		return nil, fmt.Errorf("too many points generated: %d, max: %d, increase interval or decrease series count", points, maxPoints)
+	}
+	timestamps := alignTimestamps(timeRange, interval)
+	rs := &models.ResultSet{
+		MetricName: metricName,
+		GroupBy:    []string{seriesTagKey},
+		Fields:     []string{valueField},
+		StartTime:  timeRange.From,
+		EndTime:    timeRange.To,
+		Interval:   interval.Milliseconds(),
+	}
+	for i := 0; i < seriesCount; i++ {
+		// same seed/series generates same data
+		r := rand.New(rand.NewSource(dataQueryReq.Seed + int64(i))) //nolint:gosec
+		series := models.NewSeries(map[string]string{seriesTagKey: fmt.Sprintf("series-%d", i+1)}, "")
+		series.Fields[valueField] = generate(r, i, timestamps)
+		rs.AddSeries(series)
+	}
+	return model.NewFramesFromResultSet(rs), nil
+}
+
+// MetadataQuery returns all supported scenarios.
+func (cli *client) MetadataQuery(_ context.Context, _ *model.Query) (any, error) {
+	return scenarios, nil
+}
+
+// CheckHealth always returns nil, because no backend is needed.
+func (cli *client) CheckHealth(_ context.Context) error {
+	return nil
+}
+
+// Close does nothing, because no backend is needed.
+func (cli *client) Close() error {
+	return nil
+}
+
+// getInterval returns the interval of points, if not set calculates it by max data points(at most maxPoints).
+func getInterval(req *DataQueryRequest, timeRange model.TimeRange) (time.Duration, error) {
+	if req.Interval != "" {
+		interval, err := time.ParseDuration(req.Interval)
+		if err != nil || interval < time.Millisecond {
+			return 0, fmt.Errorf("invalid interval: %s", req.Interval)
+		}
+		return interval, nil
+	}
+	maxDataPoints := req.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
+	if maxDataPoints >= maxPoints {
+		// points include both ends of time range
+		maxDataPoints = maxPoints - 1
+	}
+	interval := time.Duration((timeRange.To-timeRange.From+maxDataPoints-1)/maxDataPoints) * time.Millisecond
+	if interval < time.Second {
+		return time.Second, nil
+	}
+	if interval%time.Second != 0 {
+		interval = interval.Truncate(time.Second) + time.Second
+	}
+	return interval, nil
+}
+
+// alignTimestamps returns the timestamps aligned by interval in time range.
+func alignTimestamps(timeRange model.TimeRange, interval time.Duration) []int64 {
+	step := interval.Milliseconds()
+	start := timeRange.From / step * step
+	if start < timeRange.From {
+		start += step
+	}
+	var rs []int64
+	for timestamp := start; timestamp <= timeRange.To; timestamp += step {
+		rs = append(rs, timestamp)
+	}
+	return rs
+}
+
+// randomWalk generates random walk points, drops points by gap ratio.
+func randomWalk(r *rand.Rand, req *DataQueryRequest, timestamps []int64, gapRatio float64) map[int64]float64 {
+	value := r.Float64() * 100
+	if req.Min != nil && req.Max != nil {
+		value = *req.Min + r.Float64()*(*req.Max-*req.Min)
+	}
+	points := make(map[int64]float64)
+	for _, timestamp := range timestamps {
+		value += r.Float64()*2 - 1
+		if req.Min != nil && value < *req.Min {
+			value = *req.Min
+		}
+		if req.Max != nil && value > *req.Max {
+			value = *req.Max
+		}
+		if gapRatio > 0 && r.Float64() < gapRatio {
+			continue
+		}
+		points[timestamp] = value
+	}
+	return points
+}
+
+// sine generates sine wave points, each series has different phase.
+func sine(req *DataQueryRequest, period time.Duration, idx int, timestamps []int64) map[int64]float64 {
+	amplitude := req.Amplitude
+	if amplitude == 0 {
+		amplitude = 1
+	}
+	phase := float64(idx) * math.Pi / 4
+	points := make(map[int64]float64)
+	for _, timestamp := range timestamps {
+		points[timestamp] = amplitude * math.Sin(2*math.Pi*float64(timestamp)/float64(period.Milliseconds())+phase)
+	}
+	return points
+}
+
+// parseCSV parses inline csv as result set, first column is timestamp(ms or RFC3339), others are fields,
+// empty cell means null value.
+func parseCSV(content string) (*models.ResultSet, error) {
+	records, err := csv.NewReader(strings.NewReader(strings.TrimSpace(content))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) < 2 {
+		return nil, fmt.Errorf("csv content must have header with time column and at least one field")
+	}
+	header := records[0]
+	fields := make([]string, len(header)-1)
+	for idx, name := range header[1:] {
+		fields[idx] = strings.TrimSpace(name)
+	}
+	series := models.NewSeries(nil, "")
+	for _, field := range fields {
+		series.Fields[field] = make(map[int64]float64)
+	}
+	rs := &models.ResultSet{MetricName: metricName, Fields: fields}
+	for line, record := range records[1:] {
+		timestamp, err := parseTimestamp(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		if rs.StartTime == 0 || timestamp < rs.StartTime {
+			rs.StartTime = timestamp
+		}
+		if timestamp > rs.EndTime {
+			rs.EndTime = timestamp
+		}
+		for idx, cell := range record[1:] {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value: %s", line+2, cell)
+			}
+			series.Fields[fields[idx]][timestamp] = value
+		}
+	}
+	rs.AddSeries(series)
+	return rs, nil
+}
+
+// parseTimestamp parses timestamp(ms) or RFC3339 time.
+func parseTimestamp(s string) (int64, error) {
+	if timestamp, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return timestamp, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time: %s", s)
+	}
+	return t.UnixMilli(), nil
+}
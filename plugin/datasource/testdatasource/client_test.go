@@ -0,0 +1,299 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+)
+
+func TestClient_DataQuery(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680003600000}
+
+	cases := []struct {
+		name      string
+		req       string
+		timeRange *model.TimeRange
+		prepare   func()
+		assert    func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "scenario not support",
+			req:  `{"scenario":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "error scenario with default message",
+			req:  `{"scenario":"error"}`,
+			assert: func(_ any, err error) {
+				assert.EqualError(t, err, defaultErrorMessage)
+			},
+		},
+		{
+			name: "error scenario",
+			req:  `{"scenario":"error","errorMessage":"timeout"}`,
+			assert: func(_ any, err error) {
+				assert.EqualError(t, err, "timeout")
+			},
+		},
+		{
+			name:      "invalid time range",
+			req:       `{"scenario":"randomWalk"}`,
+			timeRange: &model.TimeRange{From: 10, To: 10},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid interval",
+			req:  `{"scenario":"randomWalk","interval":"abc"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid period",
+			req:  `{"scenario":"sine","period":"-1m"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "period less than 1ms",
+			req:  `{"scenario":"sine","period":"100us"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "interval less than 1ms",
+			req:  `{"scenario":"randomWalk","interval":"100us"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "too many points by interval",
+			req:  `{"scenario":"randomWalk","interval":"1ms"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "too many points by series count",
+			req:  `{"scenario":"randomWalk","interval":"1s","seriesCount":50}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:      "huge max data points",
+			req:       `{"scenario":"randomWalk","maxDataPoints":100000000}`,
+			timeRange: &model.TimeRange{From: 0, To: 1680000000000},
+			assert: func(_ any, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "series count clamped",
+			req:  `{"scenario":"randomWalk","interval":"5m","seriesCount":1000}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, rs.(model.Frames), maxSeriesCount)
+			},
+		},
+		{
+			name:      "sine with default period in short time range",
+			req:       `{"scenario":"sine","interval":"1ms"}`,
+			timeRange: &model.TimeRange{From: 1680000000000, To: 1680000000001},
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				for _, v := range rs.(model.Frames)[0].Fields[1].Values {
+					assert.False(t, math.IsNaN(v.(float64)))
+				}
+			},
+		},
+		{
+			name: "random walk",
+			req:  `{"scenario":"randomWalk","interval":"1m","seriesCount":2,"min":0,"max":10}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frames := rs.(model.Frames)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, metricName, frames[0].Name)
+				assert.Equal(t, time.Minute.Milliseconds(), frames[0].Meta.Interval)
+				assert.Equal(t, map[string]string{seriesTagKey: "series-2"}, frames[1].Fields[1].Labels)
+				values := frames[0].Fields[1].Values
+				assert.Len(t, values, 61)
+				for _, v := range values {
+					assert.True(t, v.(float64) >= 0 && v.(float64) <= 10)
+				}
+			},
+		},
+		{
+			name: "null gaps",
+			req:  `{"scenario":"nullGaps","interval":"1m","gapRatio":0.5}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				rows := rs.(model.Frames)[0].Rows()
+				assert.True(t, rows > 0 && rows < 61)
+			},
+		},
+		{
+			name: "sine",
+			req:  `{"scenario":"sine","maxDataPoints":60,"period":"1h","amplitude":2}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, time.Minute.Milliseconds(), frame.Meta.Interval)
+				// 1680002100000 is the 36th point
+				assert.Equal(t, int64(1680002100000), frame.Fields[0].Values[35])
+				assert.InDelta(t, 2.0, frame.Fields[1].Values[35], 1e-9)
+			},
+		},
+		{
+			name: "invalid csv content",
+			req:  `{"scenario":"csvContent","csvContent":"time"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid time in csv content",
+			req:  `{"scenario":"csvContent","csvContent":"time,a\nabc,1"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "invalid value in csv content",
+			req:  `{"scenario":"csvContent","csvContent":"time,a\n1680000000000,abc"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "csv content",
+			req:  `{"scenario":"csvContent","csvContent":"time,a,b\n1680000000000,1,\n2023-03-28T10:41:00Z,2,3"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frame := rs.(model.Frames)[0]
+				assert.Equal(t, &model.TimeRange{From: 1680000000000, To: 1680000060000}, frame.Meta.TimeRange)
+				assert.Len(t, frame.Fields, 3)
+				assert.Equal(t, []any{int64(1680000000000), int64(1680000060000)}, frame.Fields[0].Values)
+				assert.Equal(t, "a", frame.Fields[1].Name)
+				assert.Equal(t, []any{1.0, 2.0}, frame.Fields[1].Values)
+				assert.Equal(t, "b", frame.Fields[2].Name)
+				assert.Equal(t, []any{nil, 3.0}, frame.Fields[2].Values)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			queryTimeRange := timeRange
+			if tt.timeRange != nil {
+				queryTimeRange = *tt.timeRange
+			}
+			rs, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, queryTimeRange)
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_DataQuery_Deterministic(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680003600000}
+	query := func(req string) any {
+		rs, err := cli.DataQuery(context.TODO(), &model.Query{Request: json.RawMessage(req)}, timeRange)
+		assert.NoError(t, err)
+		return rs
+	}
+	assert.Equal(t, query(`{"scenario":"randomWalk","seed":1}`), query(`{"scenario":"randomWalk","seed":1}`))
+	assert.NotEqual(t, query(`{"scenario":"randomWalk","seed":1}`), query(`{"scenario":"randomWalk","seed":2}`))
+	assert.Equal(t, query(`{"scenario":"nullGaps","seed":1}`), query(`{"scenario":"nullGaps","seed":1}`))
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	rs, err := cli.MetadataQuery(context.TODO(), &model.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"randomWalk", "sine", "csvContent", "nullGaps", "error"}, rs)
+}
+
+func TestClient_getInterval(t *testing.T) {
+	interval, err := getInterval(&DataQueryRequest{}, model.TimeRange{From: 0, To: time.Hour.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, 12*time.Second, interval)
+	interval, err = getInterval(&DataQueryRequest{}, model.TimeRange{From: 0, To: time.Minute.Milliseconds()})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, interval)
+	interval, err = getInterval(&DataQueryRequest{MaxDataPoints: 1e9}, model.TimeRange{From: 0, To: 1e9})
+	assert.NoError(t, err)
+	assert.Equal(t, 11*time.Second, interval)
+	interval, err = getInterval(&DataQueryRequest{Interval: "30s"}, model.TimeRange{})
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
@@ -0,0 +1,57 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testdatasource
+
+// Scenario represents the scenario of synthetic data.
+type Scenario = string
+
+// Defines all supported scenarios.
+var (
+	RandomWalk Scenario = "randomWalk"
+	Sine                = "sine"
+	CSVContent          = "csvContent"
+	NullGaps            = "nullGaps"
+	Error               = "error"
+)
+
+// scenarios represents all supported scenarios.
+var scenarios = []Scenario{RandomWalk, Sine, CSVContent, NullGaps, Error}
+
+// DataQueryRequest represents data query request for synthetic data.
+type DataQueryRequest struct {
+	Scenario Scenario `json:"scenario"`
+	// Interval represents the interval of points(e.g. 10s/1m), if not set calculates it by max data points.
+	Interval      string `json:"interval"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+	// SeriesCount represents how many series generated, default 1.
+	SeriesCount int `json:"seriesCount"`
+	// Seed represents the seed of random generator, same seed generates same data.
+	Seed int64 `json:"seed"`
+	// Min/Max represents the value range of random walk/null gaps.
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+	// Period/Amplitude represents the shape of sine wave.
+	Period    string  `json:"period"`
+	Amplitude float64 `json:"amplitude"`
+	// GapRatio represents the ratio of null points for null gaps scenario(0~1), default 0.3.
+	GapRatio float64 `json:"gapRatio"`
+	// CSVContent represents inline csv, first column is timestamp(ms or RFC3339), others are fields.
+	CSVContent string `json:"csvContent"`
+	// ErrorMessage represents the error message returned by error scenario.
+	ErrorMessage string `json:"errorMessage"`
+}
@@ -0,0 +1,213 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package zipkin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+)
+
+// ProxyRoutes represents the Zipkin apis which datasource proxy is allowed to forward.
+var ProxyRoutes = plugin.ReadOnlyProxyRoutes("/api/v2/**")
+
+// for testing
+var (
+	jsonUnmarshalFn = encoding.JSONUnmarshal
+	newRequestFn    = http.NewRequestWithContext
+	readAllFn       = io.ReadAll
+)
+
+// client implements plugin.DatasourcePlugin for Zipkin v2 API.
+type client struct {
+	datasouce *model.Datasource
+	httpCli   *http.Client
+
+	logger logger.Logger
+}
+
+// NewClient creates a Zipkin client.
+func NewClient(datasource *model.Datasource, cfg json.RawMessage) (p plugin.DatasourcePlugin, err error) {
+	httpCli, err := plugin.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		datasouce: datasource,
+		httpCli:   httpCli,
+		logger:    logger.GetLogger("DatasourcePlugin", "Zipkin"),
+	}, nil
+}
+
+// DataQuery queries trace by trace id, returns the spans grouped by process(local endpoint).
+func (cli *client) DataQuery(ctx context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+	data, _ := req.Request.MarshalJSON()
+	traceQueryReq := &GetTraceRequest{}
+	if err := jsonUnmarshalFn(data, &traceQueryReq); err != nil {
+		return nil, err
+	}
+	if traceQueryReq.TraceID == "" {
+		return nil, fmt.Errorf("trace id is required")
+	}
+	var spans []*zipkinSpan
+	if err := cli.get(ctx, "/api/v2/trace/"+url.PathEscape(traceQueryReq.TraceID), nil, &spans); err != nil {
+		return nil, err
+	}
+	return model.NewFramesFromTraces(toTraces(spans)), nil
+}
+
+// MetadataQuery queries service/operation(span name) list.
+func (cli *client) MetadataQuery(ctx context.Context, req *model.Query) (any, error) {
+	data, _ := req.Request.MarshalJSON()
+	metadataQueryReq := &MetadataQueryRequest{}
+	if err := jsonUnmarshalFn(data, &metadataQueryReq); err != nil {
+		return nil, err
+	}
+	rs := []string{}
+	switch metadataQueryReq.Type {
+	case Services:
+		if err := cli.get(ctx, "/api/v2/services", nil, &rs); err != nil {
+			return nil, err
+		}
+	case Operations:
+		if metadataQueryReq.Service == "" {
+			return nil, fmt.Errorf("service is required")
+		}
+		params := url.Values{}
+		params.Set("serviceName", metadataQueryReq.Service)
+		if err := cli.get(ctx, "/api/v2/spans", params, &rs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("metadata type not support, type: %s", metadataQueryReq.Type)
+	}
+	return rs, nil
+}
+
+// CheckHealth checks if Zipkin v2 API answers.
+func (cli *client) CheckHealth(ctx context.Context) error {
+	var rs []string
+	return cli.get(ctx, "/api/v2/services", nil, &rs)
+}
+
+// Close closes the idle connections of http client.
+func (cli *client) Close() error {
+	cli.httpCli.CloseIdleConnections()
+	return nil
+}
+
+// get sends get request to Zipkin v2 API, then unmarshals the response.
+func (cli *client) get(ctx context.Context, path string, params url.Values, data any) error {
+	endpoint := strings.TrimSuffix(cli.datasouce.URL, "/") + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	httpReq, err := newRequestFn(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.httpCli.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := readAllFn(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return jsonUnmarshalFn(body, data)
+}
+
+// toTraces converts Zipkin spans to traces grouped by local endpoint.
+func toTraces(spans []*zipkinSpan) []*model.Trace {
+	var rs []*model.Trace
+	traces := make(map[string]*model.Trace)
+	for _, span := range spans {
+		local := span.LocalEndpoint
+		if local == nil {
+			local = &endpoint{}
+		}
+		key := local.ServiceName + "/" + local.IPv4 + "/" + local.IPv6
+		t, ok := traces[key]
+		if !ok {
+			tags := make(map[string]any)
+			if ip := local.IPv4 + local.IPv6; ip != "" {
+				tags["ip"] = ip
+			}
+			if local.Port > 0 {
+				tags["port"] = local.Port
+			}
+			t = &model.Trace{Process: model.NewProcess(local.ServiceName, tags)}
+			traces[key] = t
+			rs = append(rs, t)
+		}
+		t.Spans = append(t.Spans, toSpan(span))
+	}
+	return rs
+}
+
+// toSpan converts Zipkin span(microseconds) to span(nanoseconds).
+func toSpan(span *zipkinSpan) *model.Span {
+	tags := make(map[string]any, len(span.Tags))
+	for k, v := range span.Tags {
+		tags[k] = v
+	}
+	if remote := span.RemoteEndpoint; remote != nil {
+		if remote.ServiceName != "" {
+			tags["peer.service"] = remote.ServiceName
+		}
+		if ip := remote.IPv4 + remote.IPv6; ip != "" {
+			tags["net.peer.ip"] = ip
+		}
+	}
+	rs := &model.Span{
+		TraceID:      span.TraceID,
+		ParentSpanID: span.ParentID,
+		SpanID:       span.ID,
+		Name:         span.Name,
+		Kind:         model.ParseSpanKind(span.Kind),
+		StartTime:    span.Timestamp * int64(time.Microsecond),
+		EndTime:      (span.Timestamp + span.Duration) * int64(time.Microsecond),
+		Duration:     span.Duration * int64(time.Microsecond),
+		Tags:         tags,
+	}
+	for _, a := range span.Annotations {
+		rs.Events = append(rs.Events, &model.SpanEvent{
+			Name:      a.Value,
+			Timestamp: a.Timestamp * int64(time.Microsecond),
+		})
+	}
+	return rs
+}
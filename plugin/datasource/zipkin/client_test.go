@@ -0,0 +1,277 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package zipkin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lindb/common/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/model"
+)
+
+const traceResp = `[` +
+	`{"traceId":"t1","id":"s1","kind":"SERVER","name":"get /order","timestamp":1680000000000000,"duration":2000,` +
+	`"localEndpoint":{"serviceName":"order","ipv4":"10.0.0.1","port":8080},"tags":{"http.method":"GET"}},` +
+	`{"traceId":"t1","parentId":"s1","id":"s2","kind":"CLIENT","name":"select","timestamp":1680000000000500,"duration":1000,` +
+	`"localEndpoint":{"serviceName":"order","ipv4":"10.0.0.1","port":8080},"remoteEndpoint":{"serviceName":"mysql","ipv4":"10.0.0.2"},` +
+	`"annotations":[{"timestamp":1680000000000600,"value":"retry"}]},` +
+	`{"traceId":"t1","parentId":"s1","id":"s3","name":"consume","timestamp":1680000000001000,"duration":100}]`
+
+// newZipkinServer creates a stand-in server that speaks the Zipkin v2 API.
+func newZipkinServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/trace/t1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(traceResp))
+	})
+	mux.HandleFunc("/api/v2/trace/not_found", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`Trace not_found not found`))
+	})
+	mux.HandleFunc("/api/v2/services", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["mysql","order"]`))
+	})
+	mux.HandleFunc("/api/v2/spans", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "order", r.URL.Query().Get("serviceName"))
+		_, _ = w.Write([]byte(`["get /order","select"]`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_DataQuery(t *testing.T) {
+	server := newZipkinServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "trace id is empty",
+			req:  `{}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "new http request failure",
+			req:  `{"traceId":"t1"}`,
+			prepare: func() {
+				newRequestFn = func(_ context.Context, _, _ string, _ io.Reader) (*http.Request, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "read resp body failure",
+			req:  `{"traceId":"t1"}`,
+			prepare: func() {
+				readAllFn = func(_ io.Reader) ([]byte, error) {
+					return nil, fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "trace not found",
+			req:  `{"traceId":"not_found"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "get trace successfully",
+			req:  `{"traceId":"t1"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				frames := rs.(model.Frames)
+				assert.Len(t, frames, 2)
+				assert.Equal(t, "order", frames[0].Name)
+				assert.Equal(t, model.FrameTypeTrace, frames[0].Meta.Type)
+				assert.Equal(t, 2, frames[0].Rows())
+				assert.Equal(t, []any{"s2", "s3"}, []any{frames[0].Fields[2].Values[1], frames[1].Fields[2].Values[0]})
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+				newRequestFn = http.NewRequestWithContext
+				readAllFn = io.ReadAll
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.DataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			}, model.TimeRange{})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_MetadataQuery(t *testing.T) {
+	server := newZipkinServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		req     string
+		prepare func()
+		assert  func(rs any, err error)
+	}{
+		{
+			name: "unmarshal query request failure",
+			req:  `{}`,
+			prepare: func() {
+				jsonUnmarshalFn = func(_ []byte, _ interface{}) error {
+					return fmt.Errorf("err")
+				}
+			},
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "metadata type not support",
+			req:  `{"type":"unknown"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "service list",
+			req:  `{"type":"services"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"mysql", "order"}, rs)
+			},
+		},
+		{
+			name: "operation list without service",
+			req:  `{"type":"operations"}`,
+			assert: func(_ any, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "operation list",
+			req:  `{"type":"operations","service":"order"}`,
+			assert: func(rs any, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"get /order", "select"}, rs)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				jsonUnmarshalFn = encoding.JSONUnmarshal
+			}()
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := cli.MetadataQuery(context.TODO(), &model.Query{
+				Request: json.RawMessage(tt.req),
+			})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := newZipkinServer(t)
+	defer server.Close()
+
+	cli, err := NewClient(&model.Datasource{URL: server.URL}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.CheckHealth(context.TODO()))
+
+	cli, err = NewClient(&model.Datasource{URL: server.URL + "/not_found"}, nil)
+	assert.NoError(t, err)
+	assert.Error(t, cli.CheckHealth(context.TODO()))
+}
+
+func TestClient_Close(t *testing.T) {
+	cli, err := NewClient(&model.Datasource{}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Close())
+}
+
+func TestToTraces(t *testing.T) {
+	var spans []*zipkinSpan
+	assert.NoError(t, json.Unmarshal([]byte(traceResp), &spans))
+	traces := toTraces(spans)
+	assert.Len(t, traces, 2)
+	assert.Equal(t, "order", traces[0].Process.ServiceName)
+	assert.Equal(t, "10.0.0.1", traces[0].Process.InstanceID)
+	assert.Len(t, traces[0].Spans, 2)
+	assert.Equal(t, &model.Span{
+		TraceID:   "t1",
+		SpanID:    "s1",
+		Name:      "get /order",
+		Kind:      model.SpanKindServer,
+		StartTime: 1680000000000000000,
+		EndTime:   1680000000002000000,
+		Duration:  2000000,
+		Tags:      map[string]any{"http.method": "GET"},
+	}, traces[0].Spans[0])
+	span := traces[0].Spans[1]
+	assert.Equal(t, "s1", span.ParentSpanID)
+	assert.Equal(t, model.SpanKindClient, span.Kind)
+	assert.Equal(t, map[string]any{"peer.service": "mysql", "net.peer.ip": "10.0.0.2"}, span.Tags)
+	assert.Equal(t, []*model.SpanEvent{{Name: "retry", Timestamp: 1680000000000600000}}, span.Events)
+	// span without local endpoint
+	assert.Equal(t, "", traces[1].Process.ServiceName)
+	assert.Equal(t, model.SpanKindUnspecified, traces[1].Spans[0].Kind)
+}
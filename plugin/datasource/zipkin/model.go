@@ -0,0 +1,65 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package zipkin
+
+// MetadataType represents metadata type for Zipkin.
+type MetadataType = string
+
+// Defines all Zipkin's metadata types.
+var (
+	Services   MetadataType = "services"
+	Operations              = "operations"
+)
+
+// GetTraceRequest represents get trace data request params.
+type GetTraceRequest struct {
+	TraceID string `json:"traceId"`
+}
+
+// MetadataQueryRequest represents metadata query request for Zipkin.
+type MetadataQueryRequest struct {
+	Type    MetadataType `json:"type"`
+	Service string       `json:"service"`
+}
+
+// zipkinSpan represents the span of Zipkin v2 API(microseconds).
+type zipkinSpan struct {
+	TraceID        string            `json:"traceId"`
+	ParentID       string            `json:"parentId"`
+	ID             string            `json:"id"`
+	Kind           string            `json:"kind"`
+	Name           string            `json:"name"`
+	Timestamp      int64             `json:"timestamp"`
+	Duration       int64             `json:"duration"`
+	LocalEndpoint  *endpoint         `json:"localEndpoint"`
+	RemoteEndpoint *endpoint         `json:"remoteEndpoint"`
+	Annotations    []*annotation     `json:"annotations"`
+	Tags           map[string]string `json:"tags"`
+}
+
+type endpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+type annotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
@@ -0,0 +1,57 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+)
+
+// executedQueriesKey is the context key of executed queries recorder.
+type executedQueriesKey struct{}
+
+// ExecutedQueries records the statements executed by backend during data query.
+type ExecutedQueries struct {
+	queries []string
+	mutex   sync.Mutex
+}
+
+// WithExecutedQueries returns a context which carries the recorder of executed queries.
+func WithExecutedQueries(ctx context.Context) (context.Context, *ExecutedQueries) {
+	recorder := &ExecutedQueries{}
+	return context.WithValue(ctx, executedQueriesKey{}, recorder), recorder
+}
+
+// RecordExecutedQuery records the statement executed by backend, does nothing if context has no recorder.
+// Plugin records statement before executing it, so it is still returned if query failure.
+func RecordExecutedQuery(ctx context.Context, query string) {
+	recorder, ok := ctx.Value(executedQueriesKey{}).(*ExecutedQueries)
+	if !ok {
+		return
+	}
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.queries = append(recorder.queries, query)
+}
+
+// Queries returns the recorded statements.
+func (q *ExecutedQueries) Queries() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return append([]string(nil), q.queries...)
+}
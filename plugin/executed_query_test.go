@@ -0,0 +1,36 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutedQueries(t *testing.T) {
+	// no recorder
+	RecordExecutedQuery(context.TODO(), "select 1")
+
+	ctx, recorder := WithExecutedQueries(context.TODO())
+	assert.Empty(t, recorder.Queries())
+	RecordExecutedQuery(ctx, "select 1")
+	RecordExecutedQuery(ctx, "select 2")
+	assert.Equal(t, []string{"select 1", "select 2"}, recorder.Queries())
+}
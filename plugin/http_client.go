@@ -0,0 +1,208 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lindb/linsight/pkg/interval"
+)
+
+// default options of http client.
+const (
+	defaultMaxIdleConns        = 10
+	defaultMaxIdleConnsPerHost = 5
+	defaultIdleConnTimeout     = 30 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+)
+
+// HTTPClientOptions represents the common http client options of datasource config(config.http),
+// durations are like 500ms/30s/1m.
+type HTTPClientOptions struct {
+	// Timeout represents the timeout of each request, no timeout if not set.
+	Timeout             string            `json:"timeout"`
+	DialTimeout         string            `json:"dialTimeout"`
+	IdleConnTimeout     string            `json:"idleConnTimeout"`
+	MaxIdleConns        int               `json:"maxIdleConns"`
+	MaxIdleConnsPerHost int               `json:"maxIdleConnsPerHost"`
+	MaxConnsPerHost     int               `json:"maxConnsPerHost"`
+	TLS                 *TLSOptions       `json:"tls"`
+	BasicAuth           *BasicAuth        `json:"basicAuth"`
+	BearerToken         string            `json:"bearerToken"`
+	Headers             map[string]string `json:"headers"`
+}
+
+// TLSOptions represents the tls options of http client, certificates/key are PEM encoded.
+type TLSOptions struct {
+	// CACert represents the CA certificates which verify server, uses system CA if not set.
+	CACert string `json:"caCert"`
+	// ClientCert/ClientKey represent the client certificate for mutual tls.
+	ClientCert         string `json:"clientCert"`
+	ClientKey          string `json:"clientKey"`
+	ServerName         string `json:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+}
+
+// BasicAuth represents the basic authentication of http request.
+type BasicAuth struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// GetHTTPClientOptions returns the http client options of datasource config.
+func GetHTTPClientOptions(cfg json.RawMessage) (*HTTPClientOptions, error) {
+	config := struct {
+		HTTP *HTTPClientOptions `json:"http"`
+	}{}
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	if config.HTTP == nil {
+		return &HTTPClientOptions{}, nil
+	}
+	return config.HTTP, nil
+}
+
+// NewHTTPClient creates a http client with the http client options of datasource config.
+func NewHTTPClient(cfg json.RawMessage) (*http.Client, error) {
+	opts, err := GetHTTPClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := opts.RequestTimeout()
+	if err != nil {
+		return nil, err
+	}
+	dialTimeout, err := parseDuration("dial timeout", opts.DialTimeout, defaultDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	idleConnTimeout, err := parseDuration("idle connection timeout", opts.IdleConnTimeout, defaultIdleConnTimeout)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := opts.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: dialTimeout}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: dialTimeout,
+		MaxIdleConns:        defaultMaxIdleConns,
+		MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
+		IdleConnTimeout:     idleConnTimeout,
+	}
+	if opts.MaxIdleConns > 0 {
+		transport.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	cli := &http.Client{Timeout: timeout, Transport: transport}
+	if opts.HasAuthOrHeaders() {
+		cli.Transport = &headerRoundTripper{opts: opts, next: transport}
+	}
+	return cli, nil
+}
+
+// RequestTimeout returns the timeout of each request, returns 0 if not set.
+func (opts *HTTPClientOptions) RequestTimeout() (time.Duration, error) {
+	return parseDuration("timeout", opts.Timeout, 0)
+}
+
+// HasAuthOrHeaders checks if authentication or custom headers set.
+func (opts *HTTPClientOptions) HasAuthOrHeaders() bool {
+	return opts.BasicAuth != nil || opts.BearerToken != "" || len(opts.Headers) > 0
+}
+
+// TLSConfig returns the tls config of http client, returns nil if tls options not set.
+func (opts *HTTPClientOptions) TLSConfig() (*tls.Config, error) {
+	if opts.TLS == nil {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName:         opts.TLS.ServerName,
+		InsecureSkipVerify: opts.TLS.InsecureSkipVerify, //nolint:gosec
+	}
+	if opts.TLS.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(opts.TLS.CACert)) {
+			return nil, fmt.Errorf("invalid tls CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.TLS.ClientCert != "" || opts.TLS.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(opts.TLS.ClientCert), []byte(opts.TLS.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// headerRoundTripper sets the authentication and custom headers of request.
+type headerRoundTripper struct {
+	opts *HTTPClientOptions
+	next http.RoundTripper
+}
+
+// RoundTrip sets headers of request, then executes it.
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// round tripper should not modify request
+	req = req.Clone(req.Context())
+	for key, value := range rt.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	if rt.opts.BasicAuth != nil {
+		req.SetBasicAuth(rt.opts.BasicAuth.User, rt.opts.BasicAuth.Password)
+	} else if rt.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+rt.opts.BearerToken)
+	}
+	return rt.next.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of transport.
+func (rt *headerRoundTripper) CloseIdleConnections() {
+	if closer, ok := rt.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// parseDuration parses duration, returns default value if empty.
+func parseDuration(name, duration string, defaultValue time.Duration) (time.Duration, error) {
+	if duration == "" {
+		return defaultValue, nil
+	}
+	ms, err := interval.Parse(duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid http %s: %w", name, err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
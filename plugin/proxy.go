@@ -0,0 +1,78 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ProxyRoute represents the method and path of datasource api which datasource proxy is allowed to forward.
+type ProxyRoute struct {
+	Method string
+	// Path represents the path pattern relative to datasource url, matched by path.Match,
+	// the suffix "/**" matches any sub path, "/" matches datasource url itself.
+	Path string
+	// Privileged represents the route exposes the internals of datasource(e.g. cluster state) or executes
+	// statement, only the user who can edit datasource is allowed.
+	Privileged bool
+	// Check checks if the request(cleaned path) is allowed by the config of datasource(e.g. only read only statement,
+	// only the configured index), it can rewrite the request, no check if nil.
+	Check func(req *http.Request, path string, cfg json.RawMessage) error
+}
+
+// Match checks if the method and the cleaned path of request match the route.
+func (r ProxyRoute) Match(method, reqPath string) bool {
+	if !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "/**"); ok {
+		return strings.HasPrefix(reqPath, prefix+"/")
+	}
+	matched, err := path.Match(r.Path, reqPath)
+	return err == nil && matched
+}
+
+// CleanProxyPath returns the cleaned path of proxy request, removes "."/".." elements
+// so that the request cannot escape from the allowed routes.
+func CleanProxyPath(reqPath string) string {
+	return path.Clean("/" + reqPath)
+}
+
+// MatchProxyRoute returns the first route which the method and path of request match.
+func MatchProxyRoute(routes []ProxyRoute, method, reqPath string) (ProxyRoute, bool) {
+	reqPath = CleanProxyPath(reqPath)
+	for _, route := range routes {
+		if route.Match(method, reqPath) {
+			return route, true
+		}
+	}
+	return ProxyRoute{}, false
+}
+
+// ReadOnlyProxyRoutes returns the routes which only allow GET/HEAD methods for the path patterns.
+func ReadOnlyProxyRoutes(paths ...string) (routes []ProxyRoute) {
+	for _, p := range paths {
+		routes = append(routes,
+			ProxyRoute{Method: http.MethodGet, Path: p},
+			ProxyRoute{Method: http.MethodHead, Path: p})
+	}
+	return routes
+}
@@ -0,0 +1,70 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyRoute_Match(t *testing.T) {
+	cases := []struct {
+		route  ProxyRoute
+		method string
+		path   string
+		match  bool
+	}{
+		{route: ProxyRoute{Method: http.MethodPut, Path: "/api/v1/exec"}, method: http.MethodPut, path: "/api/v1/exec", match: true},
+		{route: ProxyRoute{Method: http.MethodPut, Path: "/api/v1/exec"}, method: "put", path: "/api/v1/exec", match: true},
+		{route: ProxyRoute{Method: http.MethodPut, Path: "/api/v1/exec"}, method: http.MethodDelete, path: "/api/v1/exec"},
+		{route: ProxyRoute{Method: http.MethodPut, Path: "/api/v1/exec"}, method: http.MethodPut, path: "/api/v1/exec/a"},
+		{route: ProxyRoute{Method: http.MethodGet, Path: "/api/v1/state/**"}, method: http.MethodGet, path: "/api/v1/state/a/b", match: true},
+		{route: ProxyRoute{Method: http.MethodGet, Path: "/api/v1/state/**"}, method: http.MethodGet, path: "/api/v1/state"},
+		{route: ProxyRoute{Method: http.MethodGet, Path: "/api/v1/state/**"}, method: http.MethodGet, path: "/api/v1/stateful"},
+		{route: ProxyRoute{Method: http.MethodPost, Path: "/*/_search"}, method: http.MethodPost, path: "/logs/_search", match: true},
+		{route: ProxyRoute{Method: http.MethodPost, Path: "/*/_search"}, method: http.MethodPost, path: "/a/b/_search"},
+		{route: ProxyRoute{Method: http.MethodGet, Path: "["}, method: http.MethodGet, path: "/"},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.route.Match(tt.method, tt.path))
+		})
+	}
+}
+
+func TestProxyRoute_MatchProxyRoute(t *testing.T) {
+	routes := append(ReadOnlyProxyRoutes("/", "/api/v1/**"), ProxyRoute{Method: http.MethodPut, Path: "/api/v1/exec", Privileged: true})
+	assert.Len(t, routes, 5)
+	match := func(method, path string) bool {
+		_, ok := MatchProxyRoute(routes, method, path)
+		return ok
+	}
+	assert.True(t, match(http.MethodGet, ""))
+	assert.True(t, match(http.MethodHead, "/api/v1/query"))
+	assert.True(t, match(http.MethodGet, "api/v1/query"))
+	assert.False(t, match(http.MethodPost, "/api/v1/query"))
+	route, ok := MatchProxyRoute(routes, http.MethodPut, "/api/v1/exec")
+	assert.True(t, ok)
+	assert.True(t, route.Privileged)
+	// cannot escape from allowed routes
+	assert.False(t, match(http.MethodGet, "/api/v1/../../admin"))
+	assert.Equal(t, "/admin", CleanProxyPath("/api/v1/../../../admin"))
+}
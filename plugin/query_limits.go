@@ -0,0 +1,41 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import "context"
+
+// queryLimitsKey is the context key of query limits.
+type queryLimitsKey struct{}
+
+// QueryLimits represents the max series/points of data query result, no limit if 0.
+// Plugin stops reading result once exceeds the limits if backend supports, avoid loading huge result into memory.
+type QueryLimits struct {
+	MaxSeries int
+	MaxPoints int
+}
+
+// WithQueryLimits returns a context which carries the limits of data query result.
+func WithQueryLimits(ctx context.Context, limits QueryLimits) context.Context {
+	return context.WithValue(ctx, queryLimitsKey{}, limits)
+}
+
+// GetQueryLimits returns the limits of data query result, returns no limit if context has no limits.
+func GetQueryLimits(ctx context.Context) QueryLimits {
+	limits, _ := ctx.Value(queryLimitsKey{}).(QueryLimits)
+	return limits
+}
@@ -0,0 +1,33 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryLimits(t *testing.T) {
+	// no limits
+	assert.Equal(t, QueryLimits{}, GetQueryLimits(context.TODO()))
+
+	ctx := WithQueryLimits(context.TODO(), QueryLimits{MaxSeries: 10, MaxPoints: 100})
+	assert.Equal(t, QueryLimits{MaxSeries: 10, MaxPoints: 100}, GetQueryLimits(ctx))
+}
@@ -57,6 +57,17 @@ type AuthorizeService interface {
 	AddResourcePolicy(aclParam *modelpkg.ResourceACLParam) error
 	// RemoveResourcePoliciesByCategory removes resource level acl policies by category.
 	RemoveResourcePoliciesByCategory(orgID int64, category accesscontrol.ResourceCategory) error
+	// GetResourcePolicies returns the resource level acl policies of resource.
+	GetResourcePolicies(orgID int64, category accesscontrol.ResourceCategory, resource string) []modelpkg.ResourceACLParam
+	// RemoveResourcePolicies removes the resource level acl policies of resource.
+	RemoveResourcePolicies(orgID int64, category accesscontrol.ResourceCategory, resource string) error
+	// ReplaceResourcePolicies replaces the resource level acl policies of resource atomically.
+	ReplaceResourcePolicies(
+		orgID int64,
+		category accesscontrol.ResourceCategory,
+		resource string,
+		aclParams []modelpkg.ResourceACLParam,
+	) error
 	// UpdateResourceRole updates the acl role for resource.
 	UpdateResourceRole(alcParam *modelpkg.ResourceACLParam) error
 	// CheckResourceACL checks resource if can be accesed by given param.
@@ -141,6 +152,54 @@ func (srv *authorizeService) RemoveResourcePoliciesByCategory(orgID int64, categ
 	return err
 }
 
+// GetResourcePolicies returns the resource level acl policies of resource.
+func (srv *authorizeService) GetResourcePolicies(
+	orgID int64,
+	category accesscontrol.ResourceCategory,
+	resource string,
+) (rs []modelpkg.ResourceACLParam) {
+	policies := srv.resource.GetFilteredPolicy(1, fmt.Sprintf("%d", orgID), category.String(), resource)
+	for _, policy := range policies {
+		if len(policy) < 5 {
+			continue
+		}
+		rs = append(rs, modelpkg.ResourceACLParam{
+			Role:     accesscontrol.RoleType(policy[0]),
+			OrgID:    orgID,
+			Category: category,
+			Resource: resource,
+			Action:   accesscontrol.ActionType(policy[4]),
+		})
+	}
+	return rs
+}
+
+// RemoveResourcePolicies removes the resource level acl policies of resource.
+func (srv *authorizeService) RemoveResourcePolicies(
+	orgID int64,
+	category accesscontrol.ResourceCategory,
+	resource string,
+) error {
+	_, err := srv.resource.RemoveFilteredNamedPolicy("p", 1, fmt.Sprintf("%d", orgID), category.String(), resource)
+	return err
+}
+
+// ReplaceResourcePolicies replaces the resource level acl policies of resource atomically,
+// old policies are removed and new policies are added in one transaction.
+func (srv *authorizeService) ReplaceResourcePolicies(
+	orgID int64,
+	category accesscontrol.ResourceCategory,
+	resource string,
+	aclParams []modelpkg.ResourceACLParam,
+) error {
+	policies := make([][]string, 0, len(aclParams))
+	for idx := range aclParams {
+		policies = append(policies, aclParams[idx].ToStringParams())
+	}
+	_, err := srv.resource.UpdateFilteredPolicies(policies, 1, fmt.Sprintf("%d", orgID), category.String(), resource)
+	return err
+}
+
 // UpdateResourceRole updates the acl role for resource.
 func (srv *authorizeService) UpdateResourceRole(alcParam *modelpkg.ResourceACLParam) error {
 	_, err := srv.resource.UpdateFilteredPolicies(
@@ -298,3 +298,65 @@ func TestAuthorizeService_UpdateResourcesRole(t *testing.T) {
 	})
 	assert.Error(t, err)
 }
+
+func TestAuthorizeService_GetResourcePolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	enforcer := casbinmock.NewMockIEnforcer(ctrl)
+	srv := &authorizeService{
+		resource: enforcer,
+		logger:   logger.GetLogger("Service", "AuthTest"),
+	}
+	enforcer.EXPECT().GetFilteredPolicy(1, "123", "Datasource", "abc").Return([][]string{
+		{"team:finance", "123", "Datasource", "abc", "query"},
+		{"invalid"},
+	})
+	policies := srv.GetResourcePolicies(123, accesscontrol.Datasource, "abc")
+	assert.Equal(t, []modelpkg.ResourceACLParam{{
+		Role:     accesscontrol.TeamRole("finance"),
+		OrgID:    123,
+		Category: accesscontrol.Datasource,
+		Resource: "abc",
+		Action:   accesscontrol.Query,
+	}}, policies)
+}
+
+func TestAuthorizeService_RemoveResourcePolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	enforcer := casbinmock.NewMockIEnforcer(ctrl)
+	srv := &authorizeService{
+		resource: enforcer,
+		logger:   logger.GetLogger("Service", "AuthTest"),
+	}
+	enforcer.EXPECT().RemoveFilteredNamedPolicy("p", 1, "123", "Datasource", "abc").Return(false, fmt.Errorf("err"))
+	err := srv.RemoveResourcePolicies(123, accesscontrol.Datasource, "abc")
+	assert.Error(t, err)
+}
+
+func TestAuthorizeService_ReplaceResourcePolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	enforcer := casbinmock.NewMockIEnforcer(ctrl)
+	srv := &authorizeService{
+		resource: enforcer,
+		logger:   logger.GetLogger("Service", "AuthTest"),
+	}
+	enforcer.EXPECT().UpdateFilteredPolicies(
+		[][]string{{"team:finance", "123", "Datasource", "abc", "query"}},
+		1, "123", "Datasource", "abc").Return(false, fmt.Errorf("err"))
+	err := srv.ReplaceResourcePolicies(123, accesscontrol.Datasource, "abc", []modelpkg.ResourceACLParam{{
+		Role:     accesscontrol.TeamRole("finance"),
+		OrgID:    123,
+		Category: accesscontrol.Datasource,
+		Resource: "abc",
+		Action:   accesscontrol.Query,
+	}})
+	assert.Error(t, err)
+	enforcer.EXPECT().UpdateFilteredPolicies([][]string{}, 1, "123", "Datasource", "abc").Return(true, nil)
+	err = srv.ReplaceResourcePolicies(123, accesscontrol.Datasource, "abc", nil)
+	assert.NoError(t, err)
+}
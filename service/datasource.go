@@ -19,11 +19,16 @@ package service
 
 import (
 	"context"
+	"fmt"
 
+	"gorm.io/datatypes"
+
+	"github.com/lindb/linsight/accesscontrol"
 	"github.com/lindb/linsight/model"
 	dbpkg "github.com/lindb/linsight/pkg/db"
 	"github.com/lindb/linsight/pkg/util"
 	"github.com/lindb/linsight/pkg/uuid"
+	"github.com/lindb/linsight/plugin/datasource"
 )
 
 //go:generate mockgen -source=./datasource.go -destination=./datasource_mock.go -package=service
@@ -36,27 +41,42 @@ type DatasourceService interface {
 	UpdateDatasource(ctx context.Context, datasource *model.Datasource) error
 	// DeleteDatasourceByUID deletes data source by uid from current org.
 	DeleteDatasourceByUID(ctx context.Context, uid string) error
-	// GetDatasources returns all data sources for current org.
+	// GetDatasources returns all data sources which signed user can query for current org.
 	GetDatasources(ctx context.Context) ([]model.Datasource, error)
 	// GetDatasourceByUID returns data source by uid from current org.
 	GetDatasourceByUID(ctx context.Context, uid string) (*model.Datasource, error)
+	// CanAccessDatasource checks if signed user has the permission(query/edit) of data source.
+	CanAccessDatasource(ctx context.Context, uid string, action accesscontrol.ActionType) (bool, error)
+	// GetDatasourcePermissions returns the permissions of data source, empty means the permissions of role are used.
+	GetDatasourcePermissions(ctx context.Context, uid string) ([]model.DatasourcePermission, error)
+	// SaveDatasourcePermissions replaces the permissions of data source.
+	SaveDatasourcePermissions(ctx context.Context, uid string, permissions []model.DatasourcePermission) error
 }
 
 // datasourceService implements DatasourceService interface.
 type datasourceService struct {
-	db dbpkg.DB
+	db           dbpkg.DB
+	mgr          datasource.Manager
+	authorizeSrv AuthorizeService
+	secretKey    string
 }
 
-// NewDatasourceService creates a DatasourceService instance.
-func NewDatasourceService(db dbpkg.DB) DatasourceService {
+// NewDatasourceService creates a DatasourceService instance, secrets of datasource are encrypted by secret key.
+func NewDatasourceService(db dbpkg.DB, mgr datasource.Manager, authorizeSrv AuthorizeService, secretKey string) DatasourceService {
 	return &datasourceService{
-		db: db,
+		db:           db,
+		mgr:          mgr,
+		authorizeSrv: authorizeSrv,
+		secretKey:    secretKey,
 	}
 }
 
 // CreateDatasource creates a data source, if success returns uid of data source.
 func (srv *datasourceService) CreateDatasource(ctx context.Context, datasource *model.Datasource) (uid string, err error) {
 	datasource.UID = uuid.GenerateShortUUID()
+	if err := srv.encryptSecureConfig(nil, datasource); err != nil {
+		return "", err
+	}
 	err = srv.db.Transaction(func(tx dbpkg.DB) error {
 		user := util.GetUser(ctx)
 		if err0 := srv.cleanDefaultDatasource(tx, user.Org.ID, datasource); err0 != nil {
@@ -82,7 +102,28 @@ func (srv *datasourceService) UpdateDatasource(ctx context.Context, datasource *
 	if err != nil {
 		return err
 	}
-	return srv.db.Transaction(func(tx dbpkg.DB) error {
+	columns := map[string]any{
+		"name":       datasource.Name,
+		"is_default": datasource.IsDefault,
+		"url":        datasource.URL,
+		"config":     datasource.Config,
+		"time_zone":  datasource.TimeZone,
+	}
+	urlChanged := ds.URL != datasource.URL
+	if len(datasource.SecureConfig) > 0 || (urlChanged && len(ds.EncryptedSecureConfig) > 0) {
+		// secrets not in request are kept, but dropped if url changed, because the stored secrets
+		// would be sent to the new url which may be not trusted, they must be entered again
+		stored := ds.EncryptedSecureConfig
+		if urlChanged {
+			stored = nil
+		}
+		if err := srv.encryptSecureConfig(stored, datasource); err != nil {
+			return err
+		}
+		columns["secure_config"] = datasource.EncryptedSecureConfig
+		columns["secure_config_fields"] = datasource.SecureConfigFields
+	}
+	err = srv.db.Transaction(func(tx dbpkg.DB) error {
 		user := util.GetUser(ctx)
 		userID := user.User.ID
 		ds.UpdatedBy = userID
@@ -94,28 +135,31 @@ func (srv *datasourceService) UpdateDatasource(ctx context.Context, datasource *
 		// update datasource
 		ds.URL = datasource.URL
 		ds.Config = datasource.Config
-		if err := tx.Updates(&model.Datasource{},
-			map[string]any{
-				"name":       datasource.Name,
-				"is_default": datasource.IsDefault,
-				"url":        datasource.URL,
-				"config":     datasource.Config,
-				"time_zone":  datasource.TimeZone,
-				"updated_by": userID,
-			}, "uid=? and org_id=?", datasource.UID, user.Org.ID); err != nil {
+		columns["updated_by"] = userID
+		if err := tx.Updates(&model.Datasource{}, columns, "uid=? and org_id=?", datasource.UID, user.Org.ID); err != nil {
 			return err
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	// evict old plugin instance, next query will create new one with new config
+	srv.mgr.RemovePlugin(datasource.UID)
+	return nil
 }
 
 // DeleteDatasourceByUID deletes data source by uid from current org.
 func (srv *datasourceService) DeleteDatasourceByUID(ctx context.Context, uid string) error {
 	user := util.GetUser(ctx)
-	return srv.db.Delete(&model.Datasource{}, "uid=? and org_id=?", uid, user.Org.ID)
+	if err := srv.db.Delete(&model.Datasource{}, "uid=? and org_id=?", uid, user.Org.ID); err != nil {
+		return err
+	}
+	srv.mgr.RemovePlugin(uid)
+	return srv.authorizeSrv.RemoveResourcePolicies(user.Org.ID, accesscontrol.Datasource, uid)
 }
 
-// GetDatasources returns all data sources for current org.
+// GetDatasources returns all data sources which signed user can query for current org.
 func (srv *datasourceService) GetDatasources(ctx context.Context) ([]model.Datasource, error) {
 	var rs []model.Datasource
 
@@ -123,7 +167,21 @@ func (srv *datasourceService) GetDatasources(ctx context.Context) ([]model.Datas
 	if err := srv.db.Find(&rs, "org_id=?", user.Org.ID); err != nil {
 		return nil, err
 	}
-	return rs, nil
+	roles, err := srv.getRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var accessDatasources []model.Datasource
+	for idx := range rs {
+		ok, err := srv.canAccess(user, roles, rs[idx].UID, accesscontrol.Query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			accessDatasources = append(accessDatasources, rs[idx])
+		}
+	}
+	return accessDatasources, nil
 }
 
 // GetDatasourceByUID returns data source by uid from current org.
@@ -136,6 +194,192 @@ func (srv *datasourceService) GetDatasourceByUID(ctx context.Context, uid string
 	return &rs, nil
 }
 
+// CanAccessDatasource checks if signed user has the permission(query/edit) of data source.
+func (srv *datasourceService) CanAccessDatasource(
+	ctx context.Context,
+	uid string,
+	action accesscontrol.ActionType,
+) (bool, error) {
+	roles, err := srv.getRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+	return srv.canAccess(util.GetUser(ctx), roles, uid, action)
+}
+
+// GetDatasourcePermissions returns the permissions of data source, empty means the permissions of role are used.
+func (srv *datasourceService) GetDatasourcePermissions(ctx context.Context, uid string) ([]model.DatasourcePermission, error) {
+	user := util.GetUser(ctx)
+	policies := srv.authorizeSrv.GetResourcePolicies(user.Org.ID, accesscontrol.Datasource, uid)
+	rs := make([]model.DatasourcePermission, 0, len(policies))
+	for _, policy := range policies {
+		permission := model.DatasourcePermission{Action: policy.Action}
+		if teamUID, ok := policy.Role.TeamUID(); ok {
+			permission.TeamUID = teamUID
+		} else {
+			permission.Role = policy.Role
+		}
+		rs = append(rs, permission)
+	}
+	return rs, nil
+}
+
+// SaveDatasourcePermissions replaces the permissions of data source.
+func (srv *datasourceService) SaveDatasourcePermissions(
+	ctx context.Context,
+	uid string,
+	permissions []model.DatasourcePermission,
+) error {
+	if _, err := srv.GetDatasourceByUID(ctx, uid); err != nil {
+		return err
+	}
+	user := util.GetUser(ctx)
+	acl := make([]model.ResourceACLParam, 0, len(permissions))
+	for _, permission := range permissions {
+		role, err := srv.getPermissionRole(user.Org.ID, &permission)
+		if err != nil {
+			return err
+		}
+		acl = append(acl, model.ResourceACLParam{
+			Role:     role,
+			OrgID:    user.Org.ID,
+			Category: accesscontrol.Datasource,
+			Resource: uid,
+			Action:   permission.Action,
+		})
+	}
+	// replace old acl after all permissions validated
+	return srv.authorizeSrv.ReplaceResourcePolicies(user.Org.ID, accesscontrol.Datasource, uid, acl)
+}
+
+// getPermissionRole validates the permission of data source, then returns the role(role/team) of acl policy.
+func (srv *datasourceService) getPermissionRole(orgID int64, permission *model.DatasourcePermission) (accesscontrol.RoleType, error) {
+	if permission.Action != accesscontrol.Query && permission.Action != accesscontrol.Edit {
+		return "", fmt.Errorf("data source permission not support, action: %s", permission.Action)
+	}
+	if (permission.Role == "") == (permission.TeamUID == "") {
+		return "", fmt.Errorf("data source permission need either role or team")
+	}
+	if permission.TeamUID != "" {
+		exist, err := srv.db.Exist(&model.Team{}, "uid=? and org_id=?", permission.TeamUID, orgID)
+		if err != nil {
+			return "", err
+		}
+		if !exist {
+			return "", fmt.Errorf("team not found, uid: %s", permission.TeamUID)
+		}
+		return accesscontrol.TeamRole(permission.TeamUID), nil
+	}
+	switch permission.Role {
+	case accesscontrol.RoleViewer, accesscontrol.RoleEditor, accesscontrol.RoleAdmin:
+		return permission.Role, nil
+	default:
+		return "", fmt.Errorf("data source permission not support, role: %s", permission.Role)
+	}
+}
+
+// getRoles returns the role of signed user and the roles of teams which signed user joined.
+func (srv *datasourceService) getRoles(ctx context.Context) ([]accesscontrol.RoleType, error) {
+	user := util.GetUser(ctx)
+	var teamUIDs []string
+	sql := `select t.uid from teams t, team_members tm where t.id=tm.team_id and tm.org_id=? and tm.user_id=?`
+	if err := srv.db.ExecRaw(&teamUIDs, sql, user.Org.ID, user.User.ID); err != nil {
+		return nil, err
+	}
+	roles := []accesscontrol.RoleType{user.Role}
+	for _, teamUID := range teamUIDs {
+		roles = append(roles, accesscontrol.TeamRole(teamUID))
+	}
+	return roles, nil
+}
+
+// canAccess checks if any role has the permission(query/edit) of data source, edit permission includes
+// query permission. Admin always can edit data source, so admin cannot be locked out by permissions.
+// If data source has no permissions, viewer can query.
+func (srv *datasourceService) canAccess(
+	user *model.SignedUser,
+	roles []accesscontrol.RoleType,
+	uid string,
+	action accesscontrol.ActionType,
+) (bool, error) {
+	if srv.authorizeSrv.CanAccess(user.Role, accesscontrol.AdminAccessResource, accesscontrol.Write) {
+		return true, nil
+	}
+	if len(srv.authorizeSrv.GetResourcePolicies(user.Org.ID, accesscontrol.Datasource, uid)) == 0 {
+		if action == accesscontrol.Edit {
+			return false, nil
+		}
+		return srv.authorizeSrv.CanAccess(user.Role, accesscontrol.ViewerAccessResource, accesscontrol.Read), nil
+	}
+	actions := []accesscontrol.ActionType{action}
+	if action == accesscontrol.Query {
+		actions = append(actions, accesscontrol.Edit)
+	}
+	var aclParamList []model.ResourceACLParam
+	for _, role := range roles {
+		for _, act := range actions {
+			aclParamList = append(aclParamList, model.ResourceACLParam{
+				Role:     role,
+				OrgID:    user.Org.ID,
+				Category: accesscontrol.Datasource,
+				Resource: uid,
+				Action:   act,
+			})
+		}
+	}
+	// check acl
+	result, err := srv.authorizeSrv.CheckResourcesACL(aclParamList)
+	if err != nil {
+		return false, err
+	}
+	for _, ok := range result {
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// encryptSecureConfig merges the secrets of request into the stored secrets, then encrypts them,
+// the secrets of request are cleared after encrypted.
+func (srv *datasourceService) encryptSecureConfig(stored []byte, datasource *model.Datasource) error {
+	if len(datasource.SecureConfig) == 0 {
+		datasource.EncryptedSecureConfig = nil
+		datasource.SecureConfigFields = nil
+		return nil
+	}
+	var (
+		secrets []byte
+		err     error
+	)
+	if len(stored) > 0 {
+		if secrets, err = util.Decrypt(stored, srv.secretKey); err != nil {
+			return err
+		}
+	}
+	if secrets, err = util.MergeJSON(secrets, datasource.SecureConfig); err != nil {
+		return err
+	}
+	paths, err := util.JSONLeafPaths(secrets)
+	if err != nil {
+		return err
+	}
+	fields := datatypes.JSONMap{}
+	for _, path := range paths {
+		fields[path] = true
+	}
+	var encrypted []byte
+	if len(paths) > 0 {
+		if encrypted, err = util.Encrypt(secrets, srv.secretKey); err != nil {
+			return err
+		}
+	}
+	datasource.SecureConfig = nil
+	datasource.EncryptedSecureConfig = encrypted
+	datasource.SecureConfigFields = fields
+	return nil
+}
+
 // cleanDefaultDatasource cleans default datasource if exist when set new default datasource.
 func (srv *datasourceService) cleanDefaultDatasource(tx dbpkg.DB, orgID int64, datasource *model.Datasource) error {
 	if datasource.IsDefault {
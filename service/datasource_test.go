@@ -24,10 +24,14 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
 
+	"github.com/lindb/linsight/accesscontrol"
 	"github.com/lindb/linsight/constant"
 	"github.com/lindb/linsight/model"
 	"github.com/lindb/linsight/pkg/db"
+	"github.com/lindb/linsight/pkg/util"
+	"github.com/lindb/linsight/plugin/datasource"
 )
 
 var ctx = context.WithValue(context.TODO(), constant.LinSightSignedKey, &model.SignedUser{
@@ -51,7 +55,7 @@ func TestDatasourceService_CreateDatasource(t *testing.T) {
 	mockDB.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx db.DB) error) error {
 		return fn(mockDB)
 	}).AnyTimes()
-	srv := NewDatasourceService(mockDB)
+	srv := NewDatasourceService(mockDB, nil, nil, "")
 	cases := []struct {
 		name    string
 		prepare func()
@@ -104,10 +108,11 @@ func TestDatasourceService_UpdateDatasource(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := db.NewMockDB(ctrl)
+	mgr := datasource.NewMockManager(ctrl)
 	mockDB.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx db.DB) error) error {
 		return fn(mockDB)
 	}).AnyTimes()
-	srv := NewDatasourceService(mockDB)
+	srv := NewDatasourceService(mockDB, mgr, nil, "")
 	cases := []struct {
 		name    string
 		ds      *model.Datasource
@@ -151,6 +156,7 @@ func TestDatasourceService_UpdateDatasource(t *testing.T) {
 				mockDB.EXPECT().UpdateSingle(gomock.Any(), gomock.Any(), gomock.Any(),
 					gomock.Any(), gomock.Any()).Return(nil)
 				mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+				mgr.EXPECT().RemovePlugin("1234")
 			},
 			wantErr: false,
 		},
@@ -160,6 +166,7 @@ func TestDatasourceService_UpdateDatasource(t *testing.T) {
 			prepare: func() {
 				mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
 				mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+				mgr.EXPECT().RemovePlugin("1234")
 			},
 			wantErr: false,
 		},
@@ -177,14 +184,148 @@ func TestDatasourceService_UpdateDatasource(t *testing.T) {
 	}
 }
 
+func TestDatasourceService_SecureConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := db.NewMockDB(ctrl)
+	mgr := datasource.NewMockManager(ctrl)
+	mockDB.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx db.DB) error) error {
+		return fn(mockDB)
+	}).AnyTimes()
+
+	// secret key not configured
+	_, err := NewDatasourceService(mockDB, mgr, nil, "").CreateDatasource(ctx, &model.Datasource{
+		SecureConfig: []byte(`{"http":{"bearerToken":"token"}}`),
+	})
+	assert.Error(t, err)
+
+	srv := NewDatasourceService(mockDB, mgr, nil, "key")
+	// invalid secure config
+	_, err = srv.CreateDatasource(ctx, &model.Datasource{SecureConfig: []byte(`[]`)})
+	assert.Error(t, err)
+
+	// create with secrets
+	var stored []byte
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(ds any) error {
+		created := *ds.(**model.Datasource)
+		assert.Empty(t, created.SecureConfig)
+		assert.NotContains(t, string(created.EncryptedSecureConfig), "token")
+		assert.Equal(t, datatypes.JSONMap{"http.bearerToken": true}, created.SecureConfigFields)
+		stored = created.EncryptedSecureConfig
+		return nil
+	})
+	_, err = srv.CreateDatasource(ctx, &model.Datasource{
+		SecureConfig:       []byte(`{"http":{"bearerToken":"token"}}`),
+		SecureConfigFields: datatypes.JSONMap{"password": true},
+	})
+	assert.NoError(t, err)
+
+	getStored := func(ds any, _ ...any) error {
+		ds.(*model.Datasource).EncryptedSecureConfig = stored
+		return nil
+	}
+	// update without secrets, keep stored secrets
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStored)
+	mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).
+		DoAndReturn(func(_ any, columns map[string]any, _ ...any) error {
+			assert.NotContains(t, columns, "secure_config")
+			assert.NotContains(t, columns, "secure_config_fields")
+			return nil
+		})
+	mgr.EXPECT().RemovePlugin("1234")
+	assert.NoError(t, srv.UpdateDatasource(ctx, &model.Datasource{UID: "1234"}))
+
+	// update secrets, merge with stored secrets
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStored)
+	mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).
+		DoAndReturn(func(_ any, columns map[string]any, _ ...any) error {
+			assert.Equal(t, datatypes.JSONMap{"http.bearerToken": true, "http.basicAuth.password": true},
+				columns["secure_config_fields"])
+			secrets, err := util.Decrypt(columns["secure_config"].([]byte), "key")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"http":{"bearerToken":"token","basicAuth":{"password":"pwd"}}}`, string(secrets))
+			return nil
+		})
+	mgr.EXPECT().RemovePlugin("1234")
+	assert.NoError(t, srv.UpdateDatasource(ctx, &model.Datasource{
+		UID:          "1234",
+		SecureConfig: []byte(`{"http":{"basicAuth":{"password":"pwd"}}}`),
+	}))
+
+	// delete all secrets
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStored)
+	mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).
+		DoAndReturn(func(_ any, columns map[string]any, _ ...any) error {
+			assert.Equal(t, datatypes.JSONMap{}, columns["secure_config_fields"])
+			assert.Nil(t, columns["secure_config"])
+			return nil
+		})
+	mgr.EXPECT().RemovePlugin("1234")
+	assert.NoError(t, srv.UpdateDatasource(ctx, &model.Datasource{
+		UID:          "1234",
+		SecureConfig: []byte(`{"http":null}`),
+	}))
+
+	// url changed, drop stored secrets
+	getStoredWithURL := func(ds any, _ ...any) error {
+		ds.(*model.Datasource).URL = "http://lindb:9000"
+		ds.(*model.Datasource).EncryptedSecureConfig = stored
+		return nil
+	}
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStoredWithURL)
+	mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).
+		DoAndReturn(func(_ any, columns map[string]any, _ ...any) error {
+			assert.Equal(t, "http://other:9000", columns["url"])
+			assert.Contains(t, columns, "secure_config")
+			assert.Nil(t, columns["secure_config"])
+			assert.Empty(t, columns["secure_config_fields"])
+			return nil
+		})
+	mgr.EXPECT().RemovePlugin("1234")
+	assert.NoError(t, srv.UpdateDatasource(ctx, &model.Datasource{UID: "1234", URL: "http://other:9000"}))
+
+	// url changed, only keep secrets entered again
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStoredWithURL)
+	mockDB.EXPECT().Updates(gomock.Any(), gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).
+		DoAndReturn(func(_ any, columns map[string]any, _ ...any) error {
+			assert.Equal(t, datatypes.JSONMap{"http.basicAuth.password": true}, columns["secure_config_fields"])
+			secrets, err := util.Decrypt(columns["secure_config"].([]byte), "key")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"http":{"basicAuth":{"password":"pwd"}}}`, string(secrets))
+			return nil
+		})
+	mgr.EXPECT().RemovePlugin("1234")
+	assert.NoError(t, srv.UpdateDatasource(ctx, &model.Datasource{
+		UID:          "1234",
+		URL:          "http://other:9000",
+		SecureConfig: []byte(`{"http":{"basicAuth":{"password":"pwd"}}}`),
+	}))
+
+	// stored secrets cannot be decrypted
+	mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).DoAndReturn(getStored)
+	assert.Error(t, NewDatasourceService(mockDB, mgr, nil, "new-key").UpdateDatasource(ctx, &model.Datasource{
+		UID:          "1234",
+		SecureConfig: []byte(`{"http":{"bearerToken":"token"}}`),
+	}))
+}
+
 func TestDatasourceService_DeleteDatasourceByUID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockDB := db.NewMockDB(ctrl)
-	srv := NewDatasourceService(mockDB)
-	mockDB.EXPECT().Delete(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+	mgr := datasource.NewMockManager(ctrl)
+	authorizeSrv := NewMockAuthorizeService(ctrl)
+	srv := NewDatasourceService(mockDB, mgr, authorizeSrv, "")
+	mockDB.EXPECT().Delete(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(fmt.Errorf("err"))
 	err := srv.DeleteDatasourceByUID(ctx, "1234")
+	assert.Error(t, err)
+
+	mockDB.EXPECT().Delete(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+	mgr.EXPECT().RemovePlugin("1234")
+	authorizeSrv.EXPECT().RemoveResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return(nil)
+	err = srv.DeleteDatasourceByUID(ctx, "1234")
 	assert.NoError(t, err)
 }
 
@@ -193,10 +334,19 @@ func TestDatasourceService_GetDatasources(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := db.NewMockDB(ctrl)
-	srv := NewDatasourceService(mockDB)
+	authorizeSrv := NewMockAuthorizeService(ctrl)
+	srv := NewDatasourceService(mockDB, nil, authorizeSrv, "")
+	findDatasources := func() *gomock.Call {
+		return mockDB.EXPECT().Find(gomock.Any(), "org_id=?", int64(12)).
+			DoAndReturn(func(out any, _ ...any) error {
+				*(out.(*[]model.Datasource)) = []model.Datasource{{UID: "public"}, {UID: "finance"}}
+				return nil
+			})
+	}
 	cases := []struct {
 		name    string
 		prepare func()
+		uids    []string
 		wantErr bool
 	}{
 		{
@@ -206,12 +356,42 @@ func TestDatasourceService_GetDatasources(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "get teams failure",
+			prepare: func() {
+				findDatasources()
+				mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).Return(fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "check acl failure",
+			prepare: func() {
+				findDatasources()
+				mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).Return(nil)
+				authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleType(""), accesscontrol.AdminAccessResource, accesscontrol.Write).
+					Return(false)
+				authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "public").
+					Return([]model.ResourceACLParam{{}})
+				authorizeSrv.EXPECT().CheckResourcesACL(gomock.Any()).Return(nil, fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
 		{
 			name: "get all data sources successfully",
 			prepare: func() {
-				mockDB.EXPECT().Find(gomock.Any(), "org_id=?", int64(12)).Return(nil)
+				findDatasources()
+				mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).Return(nil)
+				authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleType(""), accesscontrol.AdminAccessResource, accesscontrol.Write).
+					Return(false).Times(2)
+				authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "public").Return(nil)
+				authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleType(""), accesscontrol.ViewerAccessResource, accesscontrol.Read).
+					Return(true)
+				authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "finance").
+					Return([]model.ResourceACLParam{{}})
+				authorizeSrv.EXPECT().CheckResourcesACL(gomock.Any()).Return([]bool{false, false}, nil)
 			},
-			wantErr: false,
+			uids: []string{"public"},
 		},
 	}
 
@@ -219,10 +399,15 @@ func TestDatasourceService_GetDatasources(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepare()
-			_, err := srv.GetDatasources(ctx)
+			rs, err := srv.GetDatasources(ctx)
 			if tt.wantErr != (err != nil) {
 				t.Fatal(tt.name)
 			}
+			var uids []string
+			for _, ds := range rs {
+				uids = append(uids, ds.UID)
+			}
+			assert.Equal(t, tt.uids, uids)
 		})
 	}
 }
@@ -232,7 +417,7 @@ func TestDatasourceService_GetDatasourceByUID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := db.NewMockDB(ctrl)
-	srv := NewDatasourceService(mockDB)
+	srv := NewDatasourceService(mockDB, nil, nil, "")
 	cases := []struct {
 		name    string
 		prepare func()
@@ -265,3 +450,211 @@ func TestDatasourceService_GetDatasourceByUID(t *testing.T) {
 		})
 	}
 }
+
+func TestDatasourceService_CanAccessDatasource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := db.NewMockDB(ctrl)
+	authorizeSrv := NewMockAuthorizeService(ctrl)
+	srv := NewDatasourceService(mockDB, nil, authorizeSrv, "")
+	viewerCtx := context.WithValue(context.TODO(), constant.LinSightSignedKey, &model.SignedUser{
+		Org:  &model.Org{BaseModel: model.BaseModel{ID: 12}},
+		User: &model.User{BaseModel: model.BaseModel{ID: 10}},
+		Role: accesscontrol.RoleViewer,
+	})
+	joinTeams := func() {
+		mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).
+			DoAndReturn(func(out any, _ string, _ ...any) error {
+				*(out.(*[]string)) = []string{"finance"}
+				return nil
+			})
+	}
+	policies := []model.ResourceACLParam{{Role: accesscontrol.TeamRole("finance"), Action: accesscontrol.Edit}}
+
+	// get teams failure
+	mockDB.EXPECT().ExecRaw(gomock.Any(), gomock.Any(), int64(12), int64(10)).Return(fmt.Errorf("err"))
+	ok, err := srv.CanAccessDatasource(viewerCtx, "1234", accesscontrol.Query)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleViewer, accesscontrol.AdminAccessResource, accesscontrol.Write).
+		Return(false).AnyTimes()
+	// no permissions, uses the permissions of role
+	joinTeams()
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return(nil)
+	ok, err = srv.CanAccessDatasource(viewerCtx, "1234", accesscontrol.Edit)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	joinTeams()
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return(nil)
+	authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleViewer, accesscontrol.ViewerAccessResource, accesscontrol.Read).Return(true)
+	ok, err = srv.CanAccessDatasource(viewerCtx, "1234", accesscontrol.Query)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// edit permission includes query permission, checks role and teams
+	joinTeams()
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return(policies)
+	authorizeSrv.EXPECT().CheckResourcesACL(gomock.Any()).
+		DoAndReturn(func(aclParams []model.ResourceACLParam) ([]bool, error) {
+			assert.Equal(t, []model.ResourceACLParam{
+				{Role: accesscontrol.RoleViewer, OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Query},
+				{Role: accesscontrol.RoleViewer, OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Edit},
+				{Role: "team:finance", OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Query},
+				{Role: "team:finance", OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Edit},
+			}, aclParams)
+			return []bool{false, false, false, true}, nil
+		})
+	ok, err = srv.CanAccessDatasource(viewerCtx, "1234", accesscontrol.Query)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// no permission
+	joinTeams()
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return(policies)
+	authorizeSrv.EXPECT().CheckResourcesACL(gomock.Len(2)).Return([]bool{false, false}, nil)
+	ok, err = srv.CanAccessDatasource(viewerCtx, "1234", accesscontrol.Edit)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// admin always can edit, even if permissions not include admin
+	adminCtx := context.WithValue(context.TODO(), constant.LinSightSignedKey, &model.SignedUser{
+		Org:  &model.Org{BaseModel: model.BaseModel{ID: 12}},
+		User: &model.User{BaseModel: model.BaseModel{ID: 10}},
+		Role: accesscontrol.RoleAdmin,
+	})
+	joinTeams()
+	authorizeSrv.EXPECT().CanAccess(accesscontrol.RoleAdmin, accesscontrol.AdminAccessResource, accesscontrol.Write).Return(true)
+	ok, err = srv.CanAccessDatasource(adminCtx, "1234", accesscontrol.Edit)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestDatasourceService_GetDatasourcePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authorizeSrv := NewMockAuthorizeService(ctrl)
+	srv := NewDatasourceService(nil, nil, authorizeSrv, "")
+	authorizeSrv.EXPECT().GetResourcePolicies(int64(12), accesscontrol.Datasource, "1234").Return([]model.ResourceACLParam{
+		{Role: accesscontrol.RoleAdmin, Action: accesscontrol.Edit},
+		{Role: accesscontrol.TeamRole("finance"), Action: accesscontrol.Query},
+	})
+	permissions, err := srv.GetDatasourcePermissions(ctx, "1234")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.DatasourcePermission{
+		{Role: accesscontrol.RoleAdmin, Action: accesscontrol.Edit},
+		{TeamUID: "finance", Action: accesscontrol.Query},
+	}, permissions)
+}
+
+func TestDatasourceService_SaveDatasourcePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := db.NewMockDB(ctrl)
+	authorizeSrv := NewMockAuthorizeService(ctrl)
+	srv := NewDatasourceService(mockDB, nil, authorizeSrv, "")
+	getDatasource := func() {
+		mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(nil)
+	}
+	cases := []struct {
+		name        string
+		permissions []model.DatasourcePermission
+		prepare     func()
+		wantErr     bool
+	}{
+		{
+			name: "get data source failure",
+			prepare: func() {
+				mockDB.EXPECT().Get(gomock.Any(), "uid=? and org_id=?", "1234", int64(12)).Return(fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name:        "action not support",
+			permissions: []model.DatasourcePermission{{Role: accesscontrol.RoleViewer, Action: accesscontrol.Read}},
+			prepare:     getDatasource,
+			wantErr:     true,
+		},
+		{
+			name:        "neither role nor team",
+			permissions: []model.DatasourcePermission{{Action: accesscontrol.Query}},
+			prepare:     getDatasource,
+			wantErr:     true,
+		},
+		{
+			name:        "both role and team",
+			permissions: []model.DatasourcePermission{{Role: accesscontrol.RoleViewer, TeamUID: "finance", Action: accesscontrol.Query}},
+			prepare:     getDatasource,
+			wantErr:     true,
+		},
+		{
+			name:        "role not support",
+			permissions: []model.DatasourcePermission{{Role: accesscontrol.RoleLin, Action: accesscontrol.Query}},
+			prepare:     getDatasource,
+			wantErr:     true,
+		},
+		{
+			name:        "check team failure",
+			permissions: []model.DatasourcePermission{{TeamUID: "finance", Action: accesscontrol.Query}},
+			prepare: func() {
+				getDatasource()
+				mockDB.EXPECT().Exist(gomock.Any(), "uid=? and org_id=?", "finance", int64(12)).Return(false, fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name:        "team not found",
+			permissions: []model.DatasourcePermission{{TeamUID: "finance", Action: accesscontrol.Query}},
+			prepare: func() {
+				getDatasource()
+				mockDB.EXPECT().Exist(gomock.Any(), "uid=? and org_id=?", "finance", int64(12)).Return(false, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:        "validate all permissions before replacing",
+			permissions: []model.DatasourcePermission{{Role: accesscontrol.RoleEditor, Action: accesscontrol.Query}, {Action: accesscontrol.Query}},
+			prepare:     getDatasource,
+			wantErr:     true,
+		},
+		{
+			name:        "replace permissions failure",
+			permissions: []model.DatasourcePermission{{Role: accesscontrol.RoleEditor, Action: accesscontrol.Query}},
+			prepare: func() {
+				getDatasource()
+				authorizeSrv.EXPECT().ReplaceResourcePolicies(int64(12), accesscontrol.Datasource, "1234", gomock.Len(1)).
+					Return(fmt.Errorf("err"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "save permissions successfully",
+			permissions: []model.DatasourcePermission{
+				{Role: accesscontrol.RoleAdmin, Action: accesscontrol.Edit},
+				{TeamUID: "finance", Action: accesscontrol.Query},
+			},
+			prepare: func() {
+				getDatasource()
+				mockDB.EXPECT().Exist(gomock.Any(), "uid=? and org_id=?", "finance", int64(12)).Return(true, nil)
+				authorizeSrv.EXPECT().ReplaceResourcePolicies(int64(12), accesscontrol.Datasource, "1234", []model.ResourceACLParam{
+					{Role: accesscontrol.RoleAdmin, OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Edit},
+					{Role: "team:finance", OrgID: 12, Category: accesscontrol.Datasource, Resource: "1234", Action: accesscontrol.Query},
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			err := srv.SaveDatasourcePermissions(ctx, "1234", tt.permissions)
+			if tt.wantErr != (err != nil) {
+				t.Fatal(tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,617 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lindb/common/pkg/logger"
+
+	"github.com/lindb/linsight/config"
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/pkg/cache"
+	"github.com/lindb/linsight/pkg/interpolate"
+	"github.com/lindb/linsight/pkg/interval"
+	"github.com/lindb/linsight/plugin"
+	"github.com/lindb/linsight/plugin/datasource"
+	"github.com/lindb/linsight/plugin/datasource/expression"
+)
+
+//go:generate mockgen -source=./query.go -destination=./query_mock.go -package=service
+
+const (
+	// defaultMaxConcurrency is the default max number of queries executed concurrently.
+	defaultMaxConcurrency = 8
+	// cacheAlignPoints represents the number of points in time range, used to estimate the interval
+	// which time range of cache key aligned to if query interval not calculated.
+	cacheAlignPoints = 1000
+	// cacheLoadTimeout represents the max duration of query which loads result into cache, the loading query
+	// is shared by the same concurrent queries, so it isn't canceled with the caller who starts it.
+	cacheLoadTimeout = 5 * time.Minute
+)
+
+// for testing
+var (
+	jsonMarshalFn  = json.Marshal
+	nowFn          = time.Now
+	loadLocationFn = time.LoadLocation
+)
+
+// DataQueryService represents data query interface.
+type DataQueryService interface {
+	// DataQuery executes the queries concurrently, returns the result of each query keyed by refId.
+	DataQuery(ctx context.Context, req *model.QueryRequest) (*model.QueryResponse, error)
+}
+
+// dataQueryService implements DataQueryService interface.
+type dataQueryService struct {
+	datasourceSrv  DatasourceService
+	datasourceMgr  datasource.Manager
+	maxConcurrency int
+	cache          cache.Cache
+
+	logger logger.Logger
+}
+
+// NewDataQueryService creates a DataQueryService instance.
+func NewDataQueryService(datasourceSrv DatasourceService, datasourceMgr datasource.Manager, cfg *config.Query) DataQueryService {
+	srv := &dataQueryService{
+		datasourceSrv:  datasourceSrv,
+		datasourceMgr:  datasourceMgr,
+		maxConcurrency: defaultMaxConcurrency,
+		logger:         logger.GetLogger("Service", "DataQuery"),
+	}
+	if cfg != nil && cfg.MaxConcurrency > 0 {
+		srv.maxConcurrency = cfg.MaxConcurrency
+	}
+	if cfg != nil && cfg.Cache != nil && cfg.Cache.Enabled && cfg.Cache.TTL > 0 && cfg.Cache.MaxMemory > 0 {
+		srv.cache = cache.NewCache(cfg.Cache.TTL.Duration(), int64(cfg.Cache.MaxMemory))
+	}
+	return srv
+}
+
+// DataQuery executes the queries concurrently, returns the result of each query keyed by refId.
+// One query failure doesn't fail other queries, the error is returned in the result of that query.
+// Expression queries are evaluated after the queries which they depend on.
+func (srv *dataQueryService) DataQuery(ctx context.Context, req *model.QueryRequest) (*model.QueryResponse, error) {
+	var queries []*model.Query
+	for _, query := range req.Queries {
+		if query != nil {
+			queries = append(queries, query)
+		}
+	}
+	if err := assignRefIDs(queries); err != nil {
+		return nil, err
+	}
+	timeRange, err := newQueryTimeRange(req)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := interpolate.NewVariables(req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]*model.QueryResult, len(queries))
+	expressions := make(map[string]*expression.Expression)
+	referenced := make(map[string]struct{})
+	for _, query := range queries {
+		if !expression.IsExpression(query) {
+			continue
+		}
+		expr, err := expression.Parse(query)
+		if err != nil {
+			results[query.RefID] = srv.execute(query, newExpressionMeta(), func() (model.Frames, error) {
+				return nil, err
+			})
+			continue
+		}
+		expressions[query.RefID] = expr
+		for _, refID := range expr.Dependencies() {
+			referenced[refID] = struct{}{}
+		}
+	}
+	var dataQueries []*model.Query
+	for _, query := range queries {
+		if _, ok := referenced[query.RefID]; query.Hide && !ok {
+			// hidden query which is not used by expression, no need to execute
+			delete(expressions, query.RefID)
+			delete(results, query.RefID)
+			continue
+		}
+		if !expression.IsExpression(query) {
+			dataQueries = append(dataQueries, query)
+		}
+	}
+	for _, result := range srv.queryConcurrently(ctx, dataQueries, timeRange, vars) {
+		results[result.RefID] = result
+	}
+	srv.evalExpressions(queries, expressions, results, timeRange.timeRange)
+
+	rs := &model.QueryResponse{Results: make(map[string]*model.QueryResult, len(results))}
+	for _, query := range queries {
+		result, ok := results[query.RefID]
+		if !ok || query.Hide {
+			continue
+		}
+		if req.Format == model.ArrowDataFormat && result.Status == model.QueryStatusOK {
+			arrow, err := result.Frames.MarshalArrow()
+			if err != nil {
+				result.Status = model.QueryStatusError
+				result.Error = err.Error()
+			} else {
+				result.Arrow = arrow
+			}
+			result.Frames = nil
+		}
+		rs.Results[query.RefID] = result
+	}
+	return rs, nil
+}
+
+// queryConcurrently executes the queries of datasource concurrently.
+func (srv *dataQueryService) queryConcurrently(ctx context.Context, queries []*model.Query,
+	timeRange *queryTimeRange, vars *interpolate.Variables,
+) []*model.QueryResult {
+	results := make([]*model.QueryResult, len(queries))
+	concurrency := srv.maxConcurrency
+	if concurrency > len(queries) {
+		concurrency = len(queries)
+	}
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range tasks {
+				query := queries[idx]
+				meta := &model.QueryMeta{}
+				results[idx] = srv.execute(query, meta, func() (model.Frames, error) {
+					return srv.doQuery(ctx, query, timeRange, vars, meta)
+				})
+			}
+		}()
+	}
+	for idx := range queries {
+		tasks <- idx
+	}
+	close(tasks)
+	wg.Wait()
+	return results
+}
+
+// evalExpressions evaluates expression queries in dependency order, the expression fails
+// if any query which it depends on failed or the dependencies are circular.
+func (srv *dataQueryService) evalExpressions(queries []*model.Query, expressions map[string]*expression.Expression,
+	results map[string]*model.QueryResult, timeRange model.TimeRange,
+) {
+	// topological sort(Kahn's algorithm), keeps the order of queries if no dependency
+	inDegrees := make(map[string]int, len(expressions))
+	dependents := make(map[string][]string)
+	for refID, expr := range expressions {
+		for _, dependency := range expr.Dependencies() {
+			if _, ok := expressions[dependency]; ok {
+				inDegrees[refID]++
+				dependents[dependency] = append(dependents[dependency], refID)
+			}
+		}
+	}
+	var ordered []string
+	for _, query := range queries {
+		if _, ok := expressions[query.RefID]; ok && inDegrees[query.RefID] == 0 {
+			ordered = append(ordered, query.RefID)
+		}
+	}
+	for idx := 0; idx < len(ordered); idx++ {
+		for _, dependent := range dependents[ordered[idx]] {
+			inDegrees[dependent]--
+			if inDegrees[dependent] == 0 {
+				ordered = append(ordered, dependent)
+			}
+		}
+	}
+	queryByRefID := make(map[string]*model.Query, len(queries))
+	for _, query := range queries {
+		queryByRefID[query.RefID] = query
+	}
+	for _, refID := range ordered {
+		expr := expressions[refID]
+		results[refID] = srv.execute(queryByRefID[refID], newExpressionMeta(), func() (model.Frames, error) {
+			inputs := make(map[string]model.Frames)
+			for _, dependency := range expr.Dependencies() {
+				result, ok := results[dependency]
+				if !ok {
+					return nil, fmt.Errorf("query not found, refId: %s", dependency)
+				}
+				if result.Status != model.QueryStatusOK {
+					return nil, fmt.Errorf("query '%s' failure: %s", dependency, result.Error)
+				}
+				inputs[dependency] = result.Frames
+			}
+			return expr.Execute(inputs, timeRange)
+		})
+	}
+	// expressions not evaluated have circular dependencies
+	for _, query := range queries {
+		if _, ok := expressions[query.RefID]; !ok {
+			continue
+		}
+		if _, ok := results[query.RefID]; !ok {
+			results[query.RefID] = srv.execute(query, newExpressionMeta(), func() (model.Frames, error) {
+				return nil, fmt.Errorf("circular dependency of expression, refId: %s", query.RefID)
+			})
+		}
+	}
+}
+
+// execute executes one query, converts error/panic as the failure result, the metadata of result
+// is completed by frames.
+func (srv *dataQueryService) execute(query *model.Query, meta *model.QueryMeta,
+	fn func() (model.Frames, error),
+) (rs *model.QueryResult) {
+	rs = &model.QueryResult{RefID: query.RefID, Meta: meta}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			srv.logger.Error("panic when query data",
+				logger.String("datasource", query.Datasource.UID), logger.Any("panic", r), logger.Stack())
+			rs.Frames = nil
+			rs.Status = model.QueryStatusError
+			rs.Error = fmt.Sprintf("query data panic: %v", r)
+		}
+		rs.Duration = time.Since(start)
+	}()
+
+	frames, err := fn()
+	if err != nil {
+		rs.Status = model.QueryStatusError
+		rs.Error = err.Error()
+		return rs
+	}
+	for _, frame := range frames {
+		frame.RefID = query.RefID
+	}
+	completeMeta(meta, frames)
+	rs.Frames = frames
+	rs.Status = model.QueryStatusOK
+	return rs
+}
+
+// newExpressionMeta creates the metadata of expression query.
+func newExpressionMeta() *model.QueryMeta {
+	return &model.QueryMeta{Datasource: &model.QueryDatasource{UID: expression.DatasourceUID}}
+}
+
+// completeMeta completes the metadata of query by frames: executed queries(if plugin doesn't record them),
+// the number of series/points/rows.
+func completeMeta(meta *model.QueryMeta, frames model.Frames) {
+	executed := make(map[string]struct{}, len(meta.ExecutedQueries))
+	for _, query := range meta.ExecutedQueries {
+		executed[query] = struct{}{}
+	}
+	for _, frame := range frames {
+		meta.Rows += frame.Rows()
+		if frame.Meta == nil {
+			continue
+		}
+		if query := frame.Meta.ExecutedQuery; query != "" {
+			if _, ok := executed[query]; !ok {
+				executed[query] = struct{}{}
+				meta.ExecutedQueries = append(meta.ExecutedQueries, query)
+			}
+		}
+	}
+	series, points := frames.Count()
+	meta.Series += series
+	meta.Points += points
+}
+
+// doQuery finds the datasource plugin by uid, resolves the time range for datasource, prepares the
+// query(time shift/interval/variables), then queries data(cached if cache enabled) and downsamples the result.
+func (srv *dataQueryService) doQuery(ctx context.Context, query *model.Query,
+	queryTimeRange *queryTimeRange, vars *interpolate.Variables, meta *model.QueryMeta,
+) (model.Frames, error) {
+	ds, err := srv.datasourceSrv.GetDatasourceByUID(ctx, query.Datasource.UID)
+	if err != nil {
+		return nil, err
+	}
+	meta.Datasource = &model.QueryDatasource{UID: ds.UID, Name: ds.Name, Type: ds.Type}
+	cli, err := srv.datasourceMgr.GetPlugin(ds)
+	if err != nil {
+		return nil, err
+	}
+	timeRange, err := queryTimeRange.resolve(ds)
+	if err != nil {
+		return nil, err
+	}
+	var shift int64
+	if query.TimeShift != "" {
+		if shift, err = interval.Parse(query.TimeShift); err != nil {
+			return nil, fmt.Errorf("invalid time shift: %w", err)
+		}
+		timeRange = shiftTimeRange(timeRange, -shift)
+	}
+	query, err = prepareQuery(cli, query, timeRange, queryTimeRange.now, vars)
+	if err != nil {
+		return nil, err
+	}
+	ctx, executedQueries := plugin.WithExecutedQueries(ctx)
+	frames, err := srv.queryData(ctx, ds, cli, query, timeRange, meta)
+	meta.ExecutedQueries = executedQueries.Queries()
+	if err != nil {
+		return nil, err
+	}
+	if shift > 0 {
+		// move the result to current time range
+		for _, frame := range frames {
+			frame.ShiftTime(shift)
+		}
+	}
+	if err := downsample(ds, query, frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// downsample reduces the points of series to max data points, the algorithm of query takes precedence
+// over the setting of datasource.
+func downsample(ds *model.Datasource, query *model.Query, frames model.Frames) error {
+	algorithm := query.Downsample
+	if algorithm == "" {
+		cfg, err := ds.GetQueryConfig()
+		if err != nil {
+			return err
+		}
+		algorithm = cfg.Downsample
+	}
+	maxPoints := query.MaxDataPoints
+	if maxPoints <= 0 {
+		maxPoints = interval.DefaultMaxDataPoints
+	}
+	for _, frame := range frames {
+		if err := frame.Downsample(algorithm, int(maxPoints)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryData queries data by datasource plugin, the result is cached if cache enabled.
+func (srv *dataQueryService) queryData(ctx context.Context, ds *model.Datasource, cli plugin.DatasourcePlugin,
+	query *model.Query, timeRange model.TimeRange, meta *model.QueryMeta,
+) (model.Frames, error) {
+	dataQuery := func(ctx context.Context) (model.Frames, error) {
+		start := time.Now()
+		defer func() {
+			meta.BackendDuration = time.Since(start)
+		}()
+		return cli.DataQuery(ctx, query, timeRange)
+	}
+	if srv.cache == nil {
+		return dataQuery(ctx)
+	}
+	var (
+		frames model.Frames
+		loaded bool
+	)
+	data, cachedAt, hit, err := srv.cache.GetOrLoad(cacheKey(ds, query, timeRange), func() ([]byte, error) {
+		// other callers may wait for the result, cannot be canceled by the caller who starts loading
+		loadCtx, cancel := context.WithTimeout(detachedContext{Context: ctx}, cacheLoadTimeout)
+		defer cancel()
+		rs, err := dataQuery(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		frames, loaded = rs, true
+		return jsonMarshalFn(rs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		// decode cached frames, because frames may be modified by caller
+		if err := json.Unmarshal(data, &frames); err != nil {
+			return nil, err
+		}
+	}
+	meta.Cache = &model.QueryCacheInfo{Hit: hit, CachedAt: cachedAt.UnixMilli()}
+	return frames, nil
+}
+
+// detachedContext keeps the values of parent context, but isn't canceled when parent is canceled.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline.
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+// Done returns nil, never be canceled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil, never be canceled.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// shiftTimeRange moves the time range by offset(milliseconds), keeps the unset(zero) bound.
+func shiftTimeRange(timeRange model.TimeRange, offset int64) model.TimeRange {
+	if timeRange.From > 0 {
+		timeRange.From += offset
+	}
+	if timeRange.To > 0 {
+		timeRange.To += offset
+	}
+	return timeRange
+}
+
+// queryTimeRange represents the time range of data query request.
+type queryTimeRange struct {
+	raw model.RawTimeRange
+	now time.Time
+	// location is the time zone of request, nil if not set
+	location *time.Location
+	// timeRange is resolved in the time zone of request(local if not set)
+	timeRange model.TimeRange
+}
+
+// newQueryTimeRange resolves the time range of data query request, all queries use same current time.
+func newQueryTimeRange(req *model.QueryRequest) (*queryTimeRange, error) {
+	rs := &queryTimeRange{raw: req.Range, now: nowFn()}
+	loc := time.Local
+	if req.TimeZone != "" {
+		var err error
+		if loc, err = loadLocationFn(req.TimeZone); err != nil {
+			return nil, err
+		}
+		rs.location = loc
+	}
+	timeRange, err := req.Range.Resolve(rs.now, loc)
+	if err != nil {
+		return nil, err
+	}
+	rs.timeRange = timeRange
+	return rs, nil
+}
+
+// resolve returns the time range for datasource, the time range is resolved in the time zone of datasource
+// if request doesn't specify time zone, the time range without end is until the current time of request.
+func (r *queryTimeRange) resolve(ds *model.Datasource) (model.TimeRange, error) {
+	timeRange := r.timeRange
+	if r.location == nil && ds.TimeZone != "" {
+		loc, err := loadLocationFn(ds.TimeZone)
+		if err != nil {
+			return model.TimeRange{}, err
+		}
+		if timeRange, err = r.raw.Resolve(r.now, loc); err != nil {
+			return model.TimeRange{}, err
+		}
+	}
+	if timeRange.To <= 0 && timeRange.From > 0 {
+		timeRange.To = r.now.UnixMilli()
+	}
+	return timeRange, nil
+}
+
+// prepareQuery calculates the interval of query, then expands the variables of query request by datasource plugin
+// with built-in variables: $__from/$__to(milliseconds), $__interval(like 10s) and $__interval_ms, returns new query.
+// $__to is the current time of request(now) if time range has no end.
+func prepareQuery(cli plugin.DatasourcePlugin, query *model.Query,
+	timeRange model.TimeRange, now time.Time, vars *interpolate.Variables,
+) (*model.Query, error) {
+	var minInterval int64
+	if query.MinInterval != "" {
+		var err error
+		if minInterval, err = interval.Parse(query.MinInterval); err != nil {
+			return nil, fmt.Errorf("invalid min interval: %w", err)
+		}
+	}
+	to := timeRange.To
+	if to <= 0 {
+		to = now.UnixMilli()
+	}
+	intervalMs := interval.Calculate(timeRange.To-timeRange.From, query.MaxDataPoints, minInterval)
+	vars = vars.Clone().
+		SetBuiltin(interpolate.From, strconv.FormatInt(timeRange.From, 10)).
+		SetBuiltin(interpolate.To, strconv.FormatInt(to, 10)).
+		SetBuiltin(interpolate.Interval, interval.Format(intervalMs)).
+		SetBuiltin(interpolate.IntervalMs, strconv.FormatInt(intervalMs, 10))
+	req, err := plugin.InterpolateVariables(cli, query.Request, vars)
+	if err != nil {
+		return nil, err
+	}
+	// copy query, because query is shared with expression evaluation
+	rs := *query
+	rs.Request = req
+	rs.IntervalMs = intervalMs
+	return &rs, nil
+}
+
+// cacheKey returns the cache key of query, includes datasource uid/version, the normalized request, interval
+// and time range which is aligned to the interval of query(estimated by time range if not calculated).
+func cacheKey(ds *model.Datasource, query *model.Query, timeRange model.TimeRange) string {
+	request := []byte(query.Request)
+	var req any
+	if err := json.Unmarshal(query.Request, &req); err == nil {
+		// re-encode request, make sure the keys are sorted and no whitespace
+		request, _ = json.Marshal(req)
+	}
+	align := query.IntervalMs
+	if align <= 0 {
+		align = (timeRange.To - timeRange.From) / cacheAlignPoints
+		if align < time.Second.Milliseconds() {
+			align = time.Second.Milliseconds()
+		}
+	}
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s/%d/%d/%d/%d/", ds.UID, ds.UpdatedAt.UnixNano(),
+		timeRange.From-timeRange.From%align, timeRange.To-timeRange.To%align, query.IntervalMs)
+	_, _ = hash.Write(request)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// assignRefIDs generates refId for the query without refId, returns error if refId duplicate.
+func assignRefIDs(queries []*model.Query) error {
+	refIDs := make(map[string]struct{}, len(queries))
+	for _, query := range queries {
+		if query.RefID == "" {
+			continue
+		}
+		if _, ok := refIDs[query.RefID]; ok {
+			return fmt.Errorf("duplicate refId: %s", query.RefID)
+		}
+		refIDs[query.RefID] = struct{}{}
+	}
+	seq := 0
+	for _, query := range queries {
+		if query.RefID != "" {
+			continue
+		}
+		for {
+			refID := generateRefID(seq)
+			seq++
+			if _, ok := refIDs[refID]; !ok {
+				query.RefID = refID
+				refIDs[refID] = struct{}{}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// generateRefID generates refId by sequence, like A, B, ..., Z, AA, AB.
+func generateRefID(seq int) string {
+	var rs []byte
+	for seq >= 0 {
+		rs = append([]byte{byte('A' + seq%26)}, rs...)
+		seq = seq/26 - 1
+	}
+	return string(rs)
+}
@@ -0,0 +1,815 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lindb/common/pkg/ltoml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/linsight/config"
+	"github.com/lindb/linsight/model"
+	"github.com/lindb/linsight/plugin"
+	"github.com/lindb/linsight/plugin/datasource"
+	"github.com/lindb/linsight/plugin/datasource/expression"
+)
+
+func TestDataQueryService_DataQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+
+	frames := func() model.Frames {
+		return model.Frames{model.NewFrame("cpu", model.NewField("value", model.FieldTypeNumber, nil))}
+	}
+	newQuery := func(refID, uid string) *model.Query {
+		return &model.Query{RefID: refID, Datasource: model.TargetDatasource{UID: uid}}
+	}
+	newExpr := func(refID, req string) *model.Query {
+		return &model.Query{RefID: refID, Datasource: model.TargetDatasource{UID: expression.DatasourceUID}, Request: json.RawMessage(req)}
+	}
+	cases := []struct {
+		name    string
+		queries []*model.Query
+		format  model.DataFormat
+		prepare func()
+		assert  func(rs *model.QueryResponse, err error)
+	}{
+		{
+			name:    "duplicate refId",
+			queries: []*model.Query{newQuery("A", "uid"), newQuery("A", "uid")},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.EqualError(t, err, "duplicate refId: A")
+				assert.Nil(t, rs)
+			},
+		},
+		{
+			name:    "no queries",
+			queries: []*model.Query{nil},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, rs.Results)
+			},
+		},
+		{
+			name: "partial failure",
+			queries: []*model.Query{
+				newQuery("A", "ds_err"), newQuery("B", "plugin_err"),
+				newQuery("C", "query_err"), newQuery("D", "panic"), newQuery("E", "ok"),
+			},
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "ds_err").Return(nil, fmt.Errorf("ds err"))
+				for _, uid := range []string{"plugin_err", "query_err", "panic", "ok"} {
+					dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), uid).Return(&model.Datasource{UID: uid}, nil)
+				}
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).DoAndReturn(func(ds *model.Datasource) (plugin.DatasourcePlugin, error) {
+					if ds.UID == "plugin_err" {
+						return nil, fmt.Errorf("plugin err")
+					}
+					return cli, nil
+				}).Times(4)
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, req *model.Query, _ model.TimeRange) (any, error) {
+						switch req.Datasource.UID {
+						case "query_err":
+							return nil, fmt.Errorf("query err")
+						case "panic":
+							panic("panic err")
+						}
+						return frames(), nil
+					}).Times(3)
+			},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, rs.Results, 5)
+				assert.Equal(t, "ds err", rs.Results["A"].Error)
+				assert.Equal(t, "plugin err", rs.Results["B"].Error)
+				assert.Equal(t, "query err", rs.Results["C"].Error)
+				assert.Equal(t, "query data panic: panic err", rs.Results["D"].Error)
+				for _, refID := range []string{"A", "B", "C", "D"} {
+					assert.Equal(t, model.QueryStatusError, rs.Results[refID].Status)
+					assert.Nil(t, rs.Results[refID].Frames)
+				}
+				expect := frames()
+				expect[0].RefID = "E"
+				assert.Equal(t, &model.QueryResult{
+					RefID:    "E",
+					Status:   model.QueryStatusOK,
+					Duration: rs.Results["E"].Duration,
+					Frames:   expect,
+					Meta: &model.QueryMeta{
+						Datasource:      &model.QueryDatasource{UID: "ok"},
+						BackendDuration: rs.Results["E"].Meta.BackendDuration,
+					},
+				}, rs.Results["E"])
+				// datasource of failure query
+				assert.Nil(t, rs.Results["A"].Meta.Datasource)
+				assert.Equal(t, &model.QueryDatasource{UID: "query_err"}, rs.Results["C"].Meta.Datasource)
+			},
+		},
+		{
+			name:    "arrow format",
+			queries: []*model.Query{newQuery("A", "ok"), newQuery("B", "ok")},
+			format:  model.ArrowDataFormat,
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "ok").Return(&model.Datasource{}, nil).Times(2)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).Times(2)
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+						if req.RefID == "A" {
+							return frames(), nil
+						}
+						// invalid frame
+						frame := model.NewFrame("cpu", model.NewField("value", model.FieldTypeNumber, nil),
+							model.NewField("count", model.FieldTypeNumber, nil))
+						frame.Fields[0].Values = []any{1.0}
+						return model.Frames{frame}, nil
+					}).Times(2)
+			},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.NoError(t, err)
+				assert.Nil(t, rs.Results["A"].Frames)
+				assert.Len(t, rs.Results["A"].Arrow, 1)
+				frame, err := model.UnmarshalArrow(rs.Results["A"].Arrow[0])
+				assert.NoError(t, err)
+				assert.Equal(t, "A", frame.RefID)
+				assert.Equal(t, model.QueryStatusError, rs.Results["B"].Status)
+			},
+		},
+		{
+			name:    "generate refId if empty",
+			queries: []*model.Query{newQuery("", "ok"), newQuery("A", "ok"), newQuery("", "ok")},
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "ok").Return(&model.Datasource{}, nil).Times(3)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).Times(3)
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(3)
+			},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, rs.Results, 3)
+				assert.Contains(t, rs.Results, "A")
+				assert.Contains(t, rs.Results, "B")
+				assert.Contains(t, rs.Results, "C")
+			},
+		},
+		{
+			name: "expression queries",
+			queries: []*model.Query{
+				newExpr("C", `{"type":"math","expression":"$B * 2"}`),
+				{RefID: "A", Hide: true, Datasource: model.TargetDatasource{UID: "ok"}},
+				newExpr("B", `{"type":"reduce","expression":"A","reducer":"sum"}`),
+				{RefID: "D", Hide: true, Datasource: model.TargetDatasource{UID: "unused"}},
+				newExpr("E", `{"type":"unknown"}`),
+				newExpr("F", `{"type":"math","expression":"$E + 1"}`),
+				newExpr("G", `{"type":"math","expression":"$H + 1"}`),
+				newExpr("H", `{"type":"math","expression":"$G + 1"}`),
+				newExpr("I", `{"type":"math","expression":"$X + 1"}`),
+			},
+			prepare: func() {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), "ok").Return(&model.Datasource{}, nil)
+				dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil)
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.Frames{
+					model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"value": {1: 1, 2: 2}}),
+				}, nil)
+			},
+			assert: func(rs *model.QueryResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, rs.Results, 7)
+				assert.NotContains(t, rs.Results, "A")
+				assert.NotContains(t, rs.Results, "D")
+				assert.Equal(t, model.QueryStatusOK, rs.Results["B"].Status)
+				assert.Equal(t, []any{3.0}, rs.Results["B"].Frames[0].Fields[0].Values)
+				assert.Equal(t, model.QueryStatusOK, rs.Results["C"].Status)
+				assert.Equal(t, "C", rs.Results["C"].Frames[0].RefID)
+				assert.Equal(t, []any{6.0}, rs.Results["C"].Frames[0].Fields[0].Values)
+				assert.Equal(t, "expression type not support, type: unknown", rs.Results["E"].Error)
+				assert.Equal(t, "query 'E' failure: expression type not support, type: unknown", rs.Results["F"].Error)
+				assert.Equal(t, "circular dependency of expression, refId: G", rs.Results["G"].Error)
+				assert.Equal(t, "circular dependency of expression, refId: H", rs.Results["H"].Error)
+				assert.Equal(t, "query not found, refId: X", rs.Results["I"].Error)
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(_ *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{Queries: tt.queries, Format: tt.format})
+			tt.assert(rs, err)
+		})
+	}
+}
+
+func TestDataQueryService_MaxConcurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, &config.Query{MaxConcurrency: 2})
+
+	var running, maxRunning int32
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *model.Query, _ model.TimeRange) (any, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil, nil
+		}).Times(6)
+	var queries []*model.Query
+	for i := 0; i < 6; i++ {
+		queries = append(queries, &model.Query{})
+	}
+	rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{Queries: queries})
+	assert.NoError(t, err)
+	assert.Len(t, rs.Results, 6)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestDataQueryService_Cache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		jsonMarshalFn = json.Marshal
+		ctrl.Finish()
+	}()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, &config.Query{
+		Cache: &config.QueryCache{Enabled: true, TTL: ltoml.Duration(time.Minute), MaxMemory: 1024 * 1024},
+	})
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{UID: "ds"}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	query := func(req string, timeRange model.RawTimeRange) *model.QueryResult {
+		rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{
+			Range:   timeRange,
+			Queries: []*model.Query{{RefID: "A", Request: json.RawMessage(req)}},
+		})
+		assert.NoError(t, err)
+		return rs.Results["A"]
+	}
+	timeRange := model.RawTimeRange{From: "1680000000000", To: "1680003600000"}
+
+	// query failure not cached
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+	rs := query(`{"sql":"select 1"}`, timeRange)
+	assert.Equal(t, model.QueryStatusError, rs.Status)
+	assert.Nil(t, rs.Meta.Cache)
+	// encode frames failure
+	jsonMarshalFn = func(_ any) ([]byte, error) {
+		return nil, fmt.Errorf("err")
+	}
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	rs = query(`{"sql":"select 1"}`, timeRange)
+	assert.Equal(t, model.QueryStatusError, rs.Status)
+	jsonMarshalFn = json.Marshal
+
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.Frames{
+		model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"value": {1680000000000: 1}}),
+	}, nil)
+	rs = query(`{"sql":"select 1"}`, timeRange)
+	assert.Equal(t, model.QueryStatusOK, rs.Status)
+	assert.False(t, rs.Meta.Cache.Hit)
+	// same request(different format), time range in same interval
+	rs2 := query(`{ "sql": "select 1" }`, model.RawTimeRange{From: "1680000001000", To: "1680003601000"})
+	assert.Equal(t, model.QueryStatusOK, rs2.Status)
+	assert.True(t, rs2.Meta.Cache.Hit)
+	assert.Equal(t, rs.Meta.Cache.CachedAt, rs2.Meta.Cache.CachedAt)
+	assert.Equal(t, "A", rs2.Frames[0].RefID)
+	assert.Equal(t, []any{1.0}, rs2.Frames[0].Fields[1].Values)
+
+	// time range not in same interval
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	rs = query(`{"sql":"select 1"}`, model.RawTimeRange{From: "1680000060000", To: "1680003660000"})
+	assert.False(t, rs.Meta.Cache.Hit)
+}
+
+func TestDataQueryService_CacheLoaderCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, &config.Query{
+		Cache: &config.QueryCache{Enabled: true, TTL: ltoml.Duration(time.Minute), MaxMemory: 1024 * 1024},
+	})
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{UID: "ds"}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	inQuery := make(chan struct{})
+	finishQuery := make(chan struct{})
+	// only loads once, the waiter shares the result
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *model.Query, _ model.TimeRange) (model.Frames, error) {
+			close(inQuery)
+			<-finishQuery
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return model.Frames{
+				model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"value": {1680000000000: 1}}),
+			}, nil
+		})
+	req := &model.QueryRequest{
+		Range:   model.RawTimeRange{From: "1680000000000", To: "1680003600000"},
+		Queries: []*model.Query{{RefID: "A", Request: json.RawMessage(`{"sql":"select 1"}`)}},
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	loaderDone := make(chan struct{})
+	go func() {
+		defer close(loaderDone)
+		_, _ = srv.DataQuery(ctx, req)
+	}()
+	<-inQuery
+	waiterDone := make(chan *model.QueryResult)
+	go func() {
+		rs, err := srv.DataQuery(context.TODO(), req)
+		assert.NoError(t, err)
+		waiterDone <- rs.Results["A"]
+	}()
+	// caller who starts loading canceled
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(finishQuery)
+	rs := <-waiterDone
+	<-loaderDone
+	assert.Equal(t, model.QueryStatusOK, rs.Status)
+	assert.Len(t, rs.Frames, 1)
+}
+
+func TestDataQueryService_Variables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	interpolator := plugin.NewMockVariableInterpolator(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{UID: "ds"}, nil).AnyTimes()
+	query := func(req string, variables map[string]any) (*model.QueryResult, error) {
+		rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{
+			Range:     model.RawTimeRange{From: "1680000000000", To: "1680003600000"},
+			Queries:   []*model.Query{{RefID: "A", Request: json.RawMessage(req)}},
+			Variables: variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rs.Results["A"], nil
+	}
+
+	// invalid variable value
+	rs, err := query(`{}`, map[string]any{"host": map[string]any{}})
+	assert.Error(t, err)
+	assert.Nil(t, rs)
+
+	// expands variables of json request as csv
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).Times(2)
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+			assert.JSONEq(t, `{"host":"a,b","range":"1680000000000-1680003600000","interval":"5s/5000"}`, string(req.Request))
+			return nil, nil
+		})
+	rs, err = query(`{"host":"$host","range":"${__from}-${__to}","interval":"$__interval/$__interval_ms"}`,
+		map[string]any{"host": []any{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, model.QueryStatusOK, rs.Status)
+	rs, err = query(`{"host":"${host:unknown}"}`, map[string]any{"host": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.QueryStatusError, rs.Status)
+
+	// expands variables by plugin
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(&struct {
+		*plugin.MockDatasourcePlugin
+		*plugin.MockVariableInterpolator
+	}{cli, interpolator}, nil)
+	interpolator.EXPECT().InterpolateVariables(gomock.Any(), gomock.Any()).Return(json.RawMessage(`{"sql":"select 1"}`), nil)
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+			assert.JSONEq(t, `{"sql":"select 1"}`, string(req.Request))
+			return nil, nil
+		})
+	rs, err = query(`{"sql":"select $host"}`, map[string]any{"host": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.QueryStatusOK, rs.Status)
+}
+
+func TestDataQueryService_Interval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		nowFn = time.Now
+		ctrl.Finish()
+	}()
+	nowFn = func() time.Time {
+		return time.UnixMilli(1682592000000)
+	}
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{UID: "ds"}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	month := model.RawTimeRange{From: "1680000000000", To: "1682592000000"}
+
+	cases := []struct {
+		name      string
+		query     *model.Query
+		timeRange *model.RawTimeRange
+		interval  int64
+		request   string
+		err       string
+	}{
+		{
+			name:      "time range without end",
+			query:     &model.Query{Request: json.RawMessage(`{"interval":"$__interval"}`)},
+			timeRange: &model.RawTimeRange{From: "1680000000000"},
+			interval:  time.Hour.Milliseconds(),
+			request:   `{"interval":"1h"}`,
+		},
+		{
+			name:     "default max data points",
+			query:    &model.Query{Request: json.RawMessage(`{"interval":"$__interval"}`)},
+			interval: time.Hour.Milliseconds(),
+			request:  `{"interval":"1h"}`,
+		},
+		{
+			name:     "max data points",
+			query:    &model.Query{MaxDataPoints: 100, Request: json.RawMessage(`{"interval":"$__interval_ms"}`)},
+			interval: 12 * time.Hour.Milliseconds(),
+			request:  `{"interval":"43200000"}`,
+		},
+		{
+			name:     "min interval",
+			query:    &model.Query{MinInterval: "1d", Request: json.RawMessage(`{"interval":"$__interval"}`)},
+			interval: 24 * time.Hour.Milliseconds(),
+			request:  `{"interval":"1d"}`,
+		},
+		{
+			name:  "invalid min interval",
+			query: &model.Query{MinInterval: "1 day", Request: json.RawMessage(`{}`)},
+			err:   "invalid min interval: invalid interval: 1 day",
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == "" {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+						assert.Equal(t, tt.interval, req.IntervalMs)
+						assert.JSONEq(t, tt.request, string(req.Request))
+						return nil, nil
+					})
+			}
+			tt.query.RefID = "A"
+			timeRange := month
+			if tt.timeRange != nil {
+				timeRange = *tt.timeRange
+			}
+			rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{Range: timeRange, Queries: []*model.Query{tt.query}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.err, rs.Results["A"].Error)
+			// query of request not modified
+			assert.Zero(t, tt.query.IntervalMs)
+		})
+	}
+}
+
+func TestDataQueryService_TimeRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer func() {
+		nowFn = time.Now
+		ctrl.Finish()
+	}()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	now := time.Date(2023, time.March, 30, 10, 20, 30, 0, time.UTC)
+	nowFn = func() time.Time {
+		return now
+	}
+	startOfDay := func(loc *time.Location) int64 {
+		return time.Date(now.In(loc).Year(), now.In(loc).Month(), now.In(loc).Day(), 0, 0, 0, 0, loc).UnixMilli()
+	}
+
+	cases := []struct {
+		name       string
+		timeZone   string
+		dsTimeZone string
+		timeRange  model.RawTimeRange
+		expect     model.TimeRange
+		err        bool
+		queryErr   string
+	}{
+		{
+			name:      "relative time range",
+			timeRange: model.RawTimeRange{From: "now-6h", To: "now"},
+			expect:    model.TimeRange{From: now.Add(-6 * time.Hour).UnixMilli(), To: now.UnixMilli()},
+		},
+		{
+			name:      "time range without end",
+			timeRange: model.RawTimeRange{From: "now-6h"},
+			expect:    model.TimeRange{From: now.Add(-6 * time.Hour).UnixMilli(), To: now.UnixMilli()},
+		},
+		{
+			name:       "time range without end in time zone of datasource",
+			dsTimeZone: "Asia/Shanghai",
+			timeRange:  model.RawTimeRange{From: "now/d"},
+			expect:     model.TimeRange{From: startOfDay(shanghai), To: now.UnixMilli()},
+		},
+		{
+			name:       "rounded in time zone of request",
+			timeZone:   "UTC",
+			dsTimeZone: "Asia/Shanghai",
+			timeRange:  model.RawTimeRange{From: "now/d", To: "now"},
+			expect:     model.TimeRange{From: startOfDay(time.UTC), To: now.UnixMilli()},
+		},
+		{
+			name:       "rounded in time zone of datasource",
+			dsTimeZone: "Asia/Shanghai",
+			timeRange:  model.RawTimeRange{From: "now/d", To: "now"},
+			expect:     model.TimeRange{From: startOfDay(shanghai), To: now.UnixMilli()},
+		},
+		{
+			name:      "invalid time zone of request",
+			timeZone:  "Unknown/Zone",
+			timeRange: model.RawTimeRange{From: "now-6h", To: "now"},
+			err:       true,
+		},
+		{
+			name:      "invalid time range",
+			timeRange: model.RawTimeRange{From: "now", To: "now-6h"},
+			err:       true,
+		},
+		{
+			name:       "invalid time zone of datasource",
+			dsTimeZone: "Unknown/Zone",
+			timeRange:  model.RawTimeRange{From: "now-6h", To: "now"},
+			queryErr:   "unknown time zone Unknown/Zone",
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.err {
+				dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{TimeZone: tt.dsTimeZone}, nil)
+			}
+			if !tt.err && tt.queryErr == "" {
+				cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), tt.expect).DoAndReturn(
+					func(_ context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+						assert.JSONEq(t, fmt.Sprintf(`{"from":"%d","to":"%d"}`, tt.expect.From, tt.expect.To), string(req.Request))
+						return nil, nil
+					})
+			}
+			rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{
+				Range:    tt.timeRange,
+				TimeZone: tt.timeZone,
+				Queries:  []*model.Query{{RefID: "A", Request: json.RawMessage(`{"from":"$__from","to":"$__to"}`)}},
+			})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.queryErr, rs.Results["A"].Error)
+		})
+	}
+}
+
+func TestDataQueryService_TimeShift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, &config.Query{
+		Cache: &config.QueryCache{Enabled: true, TTL: ltoml.Duration(time.Minute), MaxMemory: 1024 * 1024},
+	})
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{UID: "ds"}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	day := 24 * time.Hour.Milliseconds()
+	from, to := int64(1680000000000), int64(1680003600000)
+	query := func(timeShift string) *model.QueryResult {
+		rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{
+			Range:   model.RawTimeRange{From: model.TimeExpr(fmt.Sprint(from)), To: model.TimeExpr(fmt.Sprint(to))},
+			Queries: []*model.Query{{RefID: "A", TimeShift: timeShift, Request: json.RawMessage(`{}`)}},
+		})
+		assert.NoError(t, err)
+		return rs.Results["A"]
+	}
+
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), model.TimeRange{From: from - day, To: to - day}).Return(model.Frames{
+		model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"value": {from - day: 1}}),
+	}, nil)
+	for i := 0; i < 2; i++ {
+		// second query hits cache
+		rs := query("1d")
+		assert.Equal(t, model.QueryStatusOK, rs.Status)
+		assert.Equal(t, i == 1, rs.Meta.Cache.Hit)
+		assert.Equal(t, []any{from}, rs.Frames[0].Fields[0].Values)
+	}
+
+	rs := query("1 day")
+	assert.Equal(t, "invalid time shift: invalid interval: 1 day", rs.Error)
+}
+
+func TestDataQueryService_Downsample(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *model.Query, _ model.TimeRange) (model.Frames, error) {
+			points := make(map[int64]float64)
+			for i := 0; i < 2000; i++ {
+				points[int64(i*1000)] = float64(i % 7)
+			}
+			return model.Frames{model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"value": points})}, nil
+		}).AnyTimes()
+
+	cases := []struct {
+		name   string
+		config string
+		query  *model.Query
+		rows   int
+		err    string
+	}{
+		{name: "no downsampling", query: &model.Query{}, rows: 2000},
+		{name: "downsample by query", query: &model.Query{Downsample: model.DownsampleLTTB, MaxDataPoints: 100}, rows: 100},
+		{name: "default max data points", query: &model.Query{Downsample: model.DownsampleLTTB}, rows: 1000},
+		{name: "downsample by datasource", config: `{"downsample":"lttb"}`, query: &model.Query{MaxDataPoints: 100}, rows: 100},
+		{
+			name:   "query takes precedence over datasource",
+			config: `{"downsample":"lttb"}`,
+			query:  &model.Query{Downsample: model.DownsampleNone, MaxDataPoints: 100},
+			rows:   2000,
+		},
+		{name: "invalid datasource config", config: `{"downsample":1}`, query: &model.Query{}, err: "json: cannot unmarshal"},
+		{name: "unknown algorithm", query: &model.Query{Downsample: "unknown"}, err: "downsampling algorithm not support: unknown"},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(&model.Datasource{Config: []byte(tt.config)}, nil)
+			tt.query.RefID = "A"
+			rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{Queries: []*model.Query{tt.query}})
+			assert.NoError(t, err)
+			result := rs.Results["A"]
+			if tt.err != "" {
+				assert.Contains(t, result.Error, tt.err)
+				return
+			}
+			assert.Equal(t, model.QueryStatusOK, result.Status)
+			assert.Equal(t, tt.rows, result.Frames[0].Rows())
+		})
+	}
+}
+
+func TestDataQueryService_Meta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dsSrv := NewMockDatasourceService(ctrl)
+	dsMgr := datasource.NewMockManager(ctrl)
+	cli := plugin.NewMockDatasourcePlugin(ctrl)
+	srv := NewDataQueryService(dsSrv, dsMgr, nil)
+	dsSrv.EXPECT().GetDatasourceByUID(gomock.Any(), gomock.Any()).Return(
+		&model.Datasource{UID: "ds", Name: "LinDB", Type: model.LinDBDatasource}, nil).AnyTimes()
+	dsMgr.EXPECT().GetPlugin(gomock.Any()).Return(cli, nil).AnyTimes()
+	cli.EXPECT().DataQuery(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *model.Query, _ model.TimeRange) (model.Frames, error) {
+			plugin.RecordExecutedQuery(ctx, "select usage from cpu")
+			if req.RefID == "B" {
+				return nil, fmt.Errorf("err")
+			}
+			frame1 := model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{
+				"idle":  {1000: 1, 2000: 2},
+				"usage": {1000: 1},
+			})
+			frame1.Meta.ExecutedQuery = "select usage from cpu"
+			frame2 := model.NewTimeSeriesFrame("cpu", nil, map[string]map[int64]float64{"usage": {1000: 1}})
+			frame2.Meta.ExecutedQuery = "select usage from cpu where host='b'"
+			if req.RefID == "D" {
+				return model.Frames{frame2}, nil
+			}
+			table := model.NewFrame("table", model.NewField("host", model.FieldTypeString, nil)).
+				SetMeta(&model.FrameMeta{Type: model.FrameTypeTable})
+			table.Fields[0].Values = []any{"a", "b", "c"}
+			return model.Frames{frame1, frame2, table}, nil
+		}).Times(3)
+
+	rs, err := srv.DataQuery(context.TODO(), &model.QueryRequest{
+		Queries: []*model.Query{
+			{RefID: "A"}, {RefID: "B"}, {RefID: "D"},
+			{RefID: "C", Datasource: model.TargetDatasource{UID: expression.DatasourceUID},
+				Request: json.RawMessage(`{"type":"math","expression":"$D * 2"}`)},
+		},
+	})
+	assert.NoError(t, err)
+	meta := rs.Results["A"].Meta
+	assert.Equal(t, &model.QueryDatasource{UID: "ds", Name: "LinDB", Type: model.LinDBDatasource}, meta.Datasource)
+	assert.Equal(t, []string{"select usage from cpu", "select usage from cpu where host='b'"}, meta.ExecutedQueries)
+	assert.Equal(t, 3, meta.Series)
+	assert.Equal(t, 4, meta.Points)
+	assert.Equal(t, 6, meta.Rows)
+	assert.Positive(t, meta.BackendDuration)
+	assert.Nil(t, meta.Cache)
+	// executed queries of failure query
+	assert.Equal(t, model.QueryStatusError, rs.Results["B"].Status)
+	assert.Equal(t, []string{"select usage from cpu"}, rs.Results["B"].Meta.ExecutedQueries)
+	// expression
+	assert.Equal(t, &model.QueryDatasource{UID: expression.DatasourceUID}, rs.Results["C"].Meta.Datasource)
+	assert.Equal(t, []string{"$D * 2"}, rs.Results["C"].Meta.ExecutedQueries)
+	assert.Equal(t, 1, rs.Results["C"].Meta.Points)
+}
+
+func TestShiftTimeRange(t *testing.T) {
+	assert.Equal(t, model.TimeRange{From: 500, To: 1500}, shiftTimeRange(model.TimeRange{From: 1000, To: 2000}, -500))
+	assert.Equal(t, model.TimeRange{From: 500}, shiftTimeRange(model.TimeRange{From: 1000}, -500))
+}
+
+func TestCacheKey(t *testing.T) {
+	ds := &model.Datasource{UID: "ds"}
+	timeRange := model.TimeRange{From: 1680000000000, To: 1680003600000}
+	key := cacheKey(ds, &model.Query{Request: json.RawMessage(`{"b":1,"a":2}`)}, timeRange)
+	assert.Equal(t, key, cacheKey(ds, &model.Query{Request: json.RawMessage(`{"a":2, "b":1}`)}, timeRange))
+	assert.NotEqual(t, key, cacheKey(ds, &model.Query{Request: json.RawMessage(`{"a":1,"b":1}`)}, timeRange))
+	// invalid request
+	assert.NotEmpty(t, cacheKey(ds, &model.Query{Request: json.RawMessage(`{`)}, timeRange))
+	// datasource modified
+	ds2 := &model.Datasource{UID: "ds"}
+	ds2.UpdatedAt = time.Now()
+	assert.NotEqual(t, key, cacheKey(ds2, &model.Query{Request: json.RawMessage(`{"b":1,"a":2}`)}, timeRange))
+	// different interval
+	assert.NotEqual(t, key, cacheKey(ds, &model.Query{Request: json.RawMessage(`{"b":1,"a":2}`), IntervalMs: 1000}, timeRange))
+	// min interval is 1s
+	assert.Equal(t, cacheKey(ds, &model.Query{}, model.TimeRange{From: 1000, To: 1001}),
+		cacheKey(ds, &model.Query{}, model.TimeRange{From: 1999, To: 1999}))
+	// aligned to query interval
+	query := &model.Query{IntervalMs: 60000}
+	assert.Equal(t, cacheKey(ds, query, model.TimeRange{From: 60000, To: 3660000}),
+		cacheKey(ds, query, model.TimeRange{From: 119999, To: 3719999}))
+	assert.NotEqual(t, cacheKey(ds, query, model.TimeRange{From: 60000, To: 3660000}),
+		cacheKey(ds, query, model.TimeRange{From: 120000, To: 3720000}))
+	query = &model.Query{IntervalMs: 10}
+	assert.NotEqual(t, cacheKey(ds, query, model.TimeRange{From: 1000, To: 2000}),
+		cacheKey(ds, query, model.TimeRange{From: 1010, To: 2010}))
+}
+
+func TestGenerateRefID(t *testing.T) {
+	assert.Equal(t, "A", generateRefID(0))
+	assert.Equal(t, "Z", generateRefID(25))
+	assert.Equal(t, "AA", generateRefID(26))
+	assert.Equal(t, "AB", generateRefID(27))
+	assert.Equal(t, "BA", generateRefID(52))
+}
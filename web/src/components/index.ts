@@ -39,6 +39,8 @@ export { default as DatasourceSelectForm } from '@src/components/input/Datasourc
 export { default as SliderInput } from '@src/components/input/SliderInput';
 export { default as LinSelect } from '@src/components/input/LinSelect';
 export { default as IntegrationSelect } from '@src/components/input/IntegrationSelect';
+export { default as HttpSettingForm } from '@src/components/input/HttpSettingForm';
+export * from '@src/components/input/HttpSettingForm';
 
 export { default as QueryEditor } from '@src/components/explore/QueryEditor';
 export { default as DataExplore } from '@src/components/explore/DataExplore';
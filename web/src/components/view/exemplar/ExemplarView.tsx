@@ -68,7 +68,7 @@ const ExemplarView: React.FC = () => {
   );
 
   useEffect(() => {
-    const exemplars = DataSetKit.createExemplarDatasets(result);
+    const exemplars = DataSetKit.createExemplarDatasets(result?.data);
     setExemplars(exemplars);
     setSelectExemplar(get(exemplars, '[0]', null));
   }, [result]);
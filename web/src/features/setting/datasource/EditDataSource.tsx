@@ -16,17 +16,25 @@ specific language governing permissions and limitations
 under the License.
 */
 import React, { useContext, useEffect, useLayoutEffect, useRef, useState } from 'react';
-import { isEmpty, get, filter } from 'lodash-es';
+import { isEmpty, get, filter, cloneDeep } from 'lodash-es';
 import { Button, Select, Card, Typography, Form, Space, Tag } from '@douyinfe/semi-ui';
 import { IconSaveStroked } from '@douyinfe/semi-icons';
 import { DatasourceSrv } from '@src/services';
 import { DatasourceCategory, DatasourcePlugin, DatasourceRepositoryInst, DatasourceSetting } from '@src/types';
 import { useNavigate, useSearchParams } from 'react-router-dom';
-import { DatasourceSelect, Icon, Notification } from '@src/components';
+import {
+  DatasourceSelect,
+  Icon,
+  Notification,
+  omitEmptySecrets,
+  toHeaderList,
+  toHeaderMap,
+} from '@src/components';
 import { ApiKit, DatasourceKit, ObjectKit, TimeKit } from '@src/utils';
 import { useRequest } from '@src/hooks';
 import { DatasourceStore } from '@src/stores';
 import DeleteDatasourceButton from './components/DeleteDatasourceButton';
+import DatasourcePermissionSetting from './components/DatasourcePermissionSetting';
 import { PlatformContext } from '@src/contexts';
 import moment from 'moment-timezone';
 import './datasource.scss';
@@ -79,7 +87,7 @@ const DatasourceSettingForm: React.FC<{
   const { SettingEditor, datasource, formApi } = props;
   useEffect(() => {
     if (datasource) {
-      formApi.setValues(datasource);
+      formApi.setValues({ ...datasource, config: toHeaderList(cloneDeep(datasource.config)) });
     }
   }, [datasource, formApi]);
   return <SettingEditor />;
@@ -162,6 +170,9 @@ const EditDataSource: React.FC = () => {
         onSubmit={async (values: any) => {
           try {
             setSubmitting(true);
+            toHeaderMap(values.config);
+            values.secureConfig = omitEmptySecrets(values.secureConfig);
+            delete values.secureConfigFields;
             if (uid) {
               values.uid = uid;
               ObjectKit.merge(datasource, values);
@@ -215,6 +226,31 @@ const EditDataSource: React.FC = () => {
         </Form.Select>
 
         <PluginSetting />
+        <Form.Section text="Query limits">
+          <Form.Input field="config.queryTimeout" label="Query timeout" placeholder="No limit, e.g. 30s" />
+          <Form.InputNumber
+            field="config.maxConcurrentQueries"
+            label="Max concurrent queries"
+            placeholder="No limit"
+            min={0}
+            style={{ width: '100%' }}
+          />
+          <Form.Input field="config.maxTimeRange" label="Max time range" placeholder="No limit, e.g. 30d" />
+          <Form.InputNumber
+            field="config.maxSeries"
+            label="Max series"
+            placeholder="No limit"
+            min={0}
+            style={{ width: '100%' }}
+          />
+          <Form.InputNumber
+            field="config.maxPoints"
+            label="Max points"
+            placeholder="No limit"
+            min={0}
+            style={{ width: '100%' }}
+          />
+        </Form.Section>
         <Form.Section text="Correlate">
           <DatasourceSelect
             style={{ width: '100%' }}
@@ -257,6 +293,7 @@ const EditDataSource: React.FC = () => {
           </Space>
         </Form.Slot>
       </Form>
+      {uid && <DatasourcePermissionSetting uid={uid} />}
     </Card>
   );
 };
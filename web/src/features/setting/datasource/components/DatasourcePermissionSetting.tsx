@@ -0,0 +1,140 @@
+/*
+Licensed to LinDB under one or more contributor
+license agreements. See the NOTICE file distributed with
+this work for additional information regarding copyright
+ownership. LinDB licenses this file to you under
+the Apache License, Version 2.0 (the "License"); you may
+not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+ 
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+import React, { useEffect, useRef, useState } from 'react';
+import { Button, Form, Select, Typography } from '@douyinfe/semi-ui';
+import { IconDeleteStroked, IconPlusStroked, IconSaveStroked } from '@douyinfe/semi-icons';
+import { get } from 'lodash-es';
+import { DatasourceSrv, TeamSrv } from '@src/services';
+import { DatasourceStore } from '@src/stores';
+import { Notification } from '@src/components';
+import { DatasourcePermission, RoleList, Team } from '@src/types';
+import { useRequest } from '@src/hooks';
+import { ApiKit } from '@src/utils';
+const { Text } = Typography;
+
+const ActionList = [
+  { label: 'Query', value: 'query', showTick: false },
+  { label: 'Edit', value: 'edit', showTick: false },
+];
+
+// subject of permission in form, like role:Viewer or team:<uid>
+const toSubject = (permission: DatasourcePermission): string => {
+  return permission.teamUid ? `team:${permission.teamUid}` : `role:${permission.role}`;
+};
+
+const toPermission = (item: { subject: string; action: DatasourcePermission['action'] }): DatasourcePermission => {
+  const [type, ...rest] = `${item.subject}`.split(':');
+  const value = rest.join(':');
+  return type === 'team' ? { teamUid: value, action: item.action } : { role: value, action: item.action };
+};
+
+const DatasourcePermissionSetting: React.FC<{ uid: string }> = (props) => {
+  const { uid } = props;
+  const formApi = useRef<any>();
+  const [submitting, setSubmitting] = useState(false);
+  const { result: permissions } = useRequest(['load-datasource-permissions', uid], () =>
+    DatasourceSrv.getDatasourcePermissions(uid)
+  );
+  const { result: teams } = useRequest(['load-datasource-permission-teams'], () => TeamSrv.fetchTeams({}));
+
+  useEffect(() => {
+    if (permissions && formApi.current) {
+      formApi.current.setValues({
+        permissions: permissions.map((p: DatasourcePermission) => ({ subject: toSubject(p), action: p.action })),
+      });
+    }
+  }, [permissions]);
+
+  return (
+    <Form
+      className="linsight-form datasource-form"
+      labelPosition="left"
+      labelAlign="right"
+      labelWidth={150}
+      getFormApi={(api: any) => (formApi.current = api)}
+      disabled={submitting}
+      onSubmit={async (values: any) => {
+        try {
+          setSubmitting(true);
+          await DatasourceSrv.saveDatasourcePermissions(uid, get(values, 'permissions', []).map(toPermission));
+          DatasourceStore.syncDatasources();
+          Notification.success('Datasource permissions saved');
+        } catch (err) {
+          Notification.error(ApiKit.getErrorMsg(err));
+        } finally {
+          setSubmitting(false);
+        }
+      }}>
+      <Form.Section text="Permissions">
+        <Form.ArrayField field="permissions">
+          {({ add, arrayFields }: any) => (
+            <Form.Slot label="Query/Edit">
+              <Text type="tertiary" size="small">
+                Admin can always edit. Viewer can query if no permission is set.
+              </Text>
+              {arrayFields.map(({ field, key, remove }: any) => (
+                <div key={key} style={{ display: 'flex', gap: 4 }}>
+                  <Form.Select
+                    field={`${field}.subject`}
+                    noLabel
+                    filter
+                    style={{ flex: 1 }}
+                    placeholder="Role or team"
+                    rules={[{ required: true, message: 'Role or team is required' }]}>
+                    <Select.OptGroup label="Role">
+                      {RoleList.map((role) => (
+                        <Select.Option key={role.value} value={`role:${role.value}`} showTick={false}>
+                          {role.label}
+                        </Select.Option>
+                      ))}
+                    </Select.OptGroup>
+                    <Select.OptGroup label="Team">
+                      {get(teams, 'teams', []).map((team: Team) => (
+                        <Select.Option key={team.uid} value={`team:${team.uid}`} showTick={false}>
+                          {team.name}
+                        </Select.Option>
+                      ))}
+                    </Select.OptGroup>
+                  </Form.Select>
+                  <Form.Select
+                    field={`${field}.action`}
+                    noLabel
+                    style={{ width: 120 }}
+                    initValue="query"
+                    optionList={ActionList}
+                  />
+                  <Button type="tertiary" icon={<IconDeleteStroked />} onClick={remove} />
+                </div>
+              ))}
+              <Button type="tertiary" icon={<IconPlusStroked />} onClick={add}>
+                Add permission
+              </Button>
+            </Form.Slot>
+          )}
+        </Form.ArrayField>
+      </Form.Section>
+      <Form.Slot>
+        <Button icon={<IconSaveStroked />} htmlType="submit" loading={submitting}>
+          Save permissions
+        </Button>
+      </Form.Slot>
+    </Form>
+  );
+};
+
+export default DatasourcePermissionSetting;
@@ -31,7 +31,7 @@ const getDataQuery = (
   dataset: DataSetType,
   defaultDatasourceUID?: string
 ): DataQuery => {
-  const dataQuery: DataQuery = { queries: [] };
+  const dataQuery: DataQuery = { queries: [], variables };
   (queries || []).forEach((q: Query) => {
     if (q.hide) {
       return;
@@ -81,8 +81,9 @@ export const useMetric = (queries: Query[], dataset: DataSetType, defaultDatasou
   );
   return {
     loading,
-    result,
-    error,
+    result: result?.data,
+    // shows errors of failure queries alongside data of successful queries
+    error: error || (isEmpty(result?.errors) ? null : result?.errors),
     refetch,
   };
 };
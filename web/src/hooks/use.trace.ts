@@ -75,7 +75,7 @@ export const useTrace = (queries: Query[]) => {
   );
 
   useEffect(() => {
-    const traces = TraceKit.createTraceDatasets(result);
+    const traces = TraceKit.createTraceDatasets(result?.data);
     setTraces(traces);
     const tree = TraceKit.buildTraceTree(traces);
     setTree(tree.tree);
@@ -87,7 +87,8 @@ export const useTrace = (queries: Query[]) => {
     traces,
     tree,
     spanMap,
-    error,
+    // shows errors of failure queries alongside data of successful queries
+    error: error || (isEmpty(result?.errors) ? null : result?.errors),
     refetch,
   };
 };
@@ -19,7 +19,7 @@ import { DataQuerySrv } from '@src/services';
 import { DataSetType, DatasourceAPI, DatasourceSetting, Query } from '@src/types';
 import { TemplateKit } from '@src/utils';
 import { isArray, isEmpty, isString, compact } from 'lodash-es';
-import { ConditionExpr, Operator } from './types';
+import { ConditionExpr, Operator, OrderBy } from './types';
 
 export class LinDBDatasource extends DatasourceAPI {
   constructor(setting: DatasourceSetting) {
@@ -27,11 +27,24 @@ export class LinDBDatasource extends DatasourceAPI {
   }
 
   rewriteQuery(query: Query, variables: {}, dataset?: DataSetType): Query | null {
+    if (query.request && query.request.raw) {
+      // raw LinQL, variables are expanded by server
+      if (isEmpty(query.request.sql)) {
+        return null;
+      }
+      query.request = { sql: query.request.sql };
+      return query;
+    }
     if (!query.request || isEmpty(query.request.metric) || isEmpty(query.request.fields)) {
       return null;
     }
+    delete query.request.sql;
 
     this.rewriteWhereCondition(query, variables);
+    if (isArray(query.request.orderBy)) {
+      // ignore order by without field
+      query.request.orderBy = query.request.orderBy.filter((o: OrderBy) => o && !isEmpty(o.field));
+    }
 
     if (dataset !== DataSetType.TimeSeries) {
       query.request.stats = true;
@@ -64,50 +77,67 @@ export class LinDBDatasource extends DatasourceAPI {
       return [];
     }
     const names: string[] = [];
-    query.request.where.forEach((w: any) => {
-      if (isString(w.value)) {
-        const name = TemplateKit.findTemplateName(w.value);
-        names.push(name);
-      } else if (isArray(w.value)) {
-        w.value.forEach((v: string) => {
-          const name = TemplateKit.findTemplateName(v);
+    const find = (conditions: ConditionExpr[]) => {
+      conditions.forEach((w: ConditionExpr) => {
+        if (!isEmpty(w.conditions)) {
+          find(w.conditions || []);
+        } else if (isString(w.value)) {
+          const name = TemplateKit.findTemplateName(w.value);
           names.push(name);
-        });
-      }
-    });
-    return compact(names);
-  }
-
-  rewriteWhereCondition(query: Query, variables: {}) {
-    if (!isEmpty(query.request.where)) {
-      const where: any[] = [];
-      query.request.where.forEach((w: any) => {
-        if (isString(w.value)) {
-          w.value = TemplateKit.template(w.value, variables);
         } else if (isArray(w.value)) {
-          const newValues: string[] = [];
           w.value.forEach((v: string) => {
-            const newVal = TemplateKit.template(v, variables);
-            if (isEmpty(newVal)) {
-              return;
-            } else if (isArray(newVal)) {
-              newValues.push(...newVal);
-            } else {
-              newValues.push(newVal);
-            }
+            const name = TemplateKit.findTemplateName(v);
+            names.push(name);
           });
-          w.value = newValues;
         }
-        if (isEmpty(w.value) && w.optional) {
-          // ignore empty condition, if it is optional
-          return;
-        }
-        where.push(w);
       });
-      query.request.where = where;
+    };
+    find(query.request.where);
+    return compact(names);
+  }
+
+  rewriteWhereCondition(query: Query, variables: {}) {
+    if (!isEmpty(query.request.where)) {
+      query.request.where = this.rewriteConditions(query.request.where, variables);
     }
   }
 
+  private rewriteConditions(conditions: ConditionExpr[], variables: {}): ConditionExpr[] {
+    const where: ConditionExpr[] = [];
+    conditions.forEach((w: ConditionExpr) => {
+      if (!isEmpty(w.conditions)) {
+        // nested conditions
+        w.conditions = this.rewriteConditions(w.conditions || [], variables);
+        if (!isEmpty(w.conditions)) {
+          where.push(w);
+        }
+        return;
+      }
+      if (isString(w.value)) {
+        w.value = TemplateKit.template(w.value, variables);
+      } else if (isArray(w.value)) {
+        const newValues: string[] = [];
+        w.value.forEach((v: string) => {
+          const newVal = TemplateKit.template(v, variables);
+          if (isEmpty(newVal)) {
+            return;
+          } else if (isArray(newVal)) {
+            newValues.push(...newVal);
+          } else {
+            newValues.push(newVal);
+          }
+        });
+        w.value = newValues;
+      }
+      if (isEmpty(w.value) && w.optional) {
+        // ignore empty condition, if it is optional
+        return;
+      }
+      where.push(w);
+    });
+    return where;
+  }
+
   async fetchMetricNames(namespace: string, prefix?: string): Promise<string[]> {
     const req: any = {
       type: 'metric',
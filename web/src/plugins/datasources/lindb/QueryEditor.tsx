@@ -31,12 +31,30 @@ import NamespaceSelect from './components/NamespaceSelect';
 
 const { Text } = Typography;
 
+const DownsampleOptions = [
+  { label: 'None', value: 'none' },
+  { label: 'LTTB', value: 'lttb' },
+  { label: 'Min/Max', value: 'minmax' },
+];
+
 const OptionsContent: React.FC<{ values: object }> = (props) => {
   const { values } = props;
   const legend = get(values, 'legendFormat', 'Auto');
   const info = [];
   info.push(<span key="legend">Legend: {isEmpty(legend) ? 'Auto' : legend}</span>);
   info.push(<span key="includeField">Include field: {`${get(values, 'includeField', false)}`}</span>);
+  const maxDataPoints = get(values, 'maxDataPoints');
+  const minInterval = get(values, 'minInterval');
+  info.push(<span key="maxDataPoints">Max data points: {maxDataPoints ? maxDataPoints : 'Auto'}</span>);
+  info.push(<span key="minInterval">Min interval: {isEmpty(minInterval) ? 'No limit' : minInterval}</span>);
+  const downsample = get(values, 'downsample');
+  if (!isEmpty(downsample)) {
+    info.push(<span key="downsample">Downsample: {downsample}</span>);
+  }
+  const timeShift = get(values, 'timeShift');
+  if (!isEmpty(timeShift)) {
+    info.push(<span key="timeShift">Time shift: {timeShift}</span>);
+  }
   return <span className="options-item">{info}</span>;
 };
 
@@ -101,6 +119,24 @@ const OptionsEditor: React.FC = () => {
             style={{ width: 180 }}
           />
           <Form.Checkbox label="Include field" field="includeField" onChange={() => formApi.current.submitForm()} />
+          <Form.InputNumber
+            field="maxDataPoints"
+            label="Max data points"
+            placeholder="1000"
+            min={1}
+            style={{ width: 120 }}
+          />
+          <Form.Input field="minInterval" label="Min interval" placeholder="e.g. 10s" style={{ width: 120 }} />
+          <Form.Input field="timeShift" label="Time shift" placeholder="e.g. 1d, 1w" style={{ width: 120 }} />
+          <Form.Select
+            field="downsample"
+            label="Downsample"
+            placeholder="Datasource default"
+            showClear
+            style={{ width: 160 }}
+            optionList={DownsampleOptions}
+            onChange={() => formApi.current.submitForm()}
+          />
         </Form>
       </div>
     </div>
@@ -149,27 +185,69 @@ const QueryEditor: React.FC<QueryEditorProps> = (props) => {
           // change query edit context's values
           modifyTarget({ request: values } as Query);
         }}>
-        {({ formApi }) => (
+        {({ formApi, values }) => (
           <>
-            {isEmpty(namespace) && (
+            <Form.Switch field="raw" label="Raw LinQL" onChange={() => formApi.submitForm()} />
+            {values.raw && (
+              <Form.TextArea
+                field="sql"
+                label="LinQL"
+                placeholder="e.g. select usage from 'system.cpu' where host in ($host) group by time($__interval)"
+                autosize
+                style={{ minWidth: 600 }}
+                onBlur={() => formApi.submitForm()}
+              />
+            )}
+            {!values.raw && isEmpty(namespace) && (
               <NamespaceSelect
                 label={get(datasource, 'setting.config.alias', 'Namespace')}
                 datasource={api}
                 style={{ minWidth: 240 }}
               />
             )}
-            <MetricNameSelect ns={namespace} datasource={api} style={{ minWidth: 240 }} />
-            <FieldSelect ns={namespace} datasource={api} style={{ minWidth: 240 }} />
-            <WhereConditonSelect ns={namespace} datasource={api} />
-            <TagKeySelect
-              ns={namespace}
-              field="groupBy"
-              placeholder="Please select group by tag key"
-              multiple
-              label="Group By"
-              datasource={api}
-              onFinished={() => formApi.submitForm()}
-            />
+            {!values.raw && (
+              <>
+                <MetricNameSelect ns={namespace} datasource={api} style={{ minWidth: 240 }} />
+                <FieldSelect ns={namespace} datasource={api} style={{ minWidth: 240 }} />
+                <WhereConditonSelect ns={namespace} datasource={api} />
+                <TagKeySelect
+                  ns={namespace}
+                  field="groupBy"
+                  placeholder="Please select group by tag key"
+                  multiple
+                  label="Group By"
+                  datasource={api}
+                  onFinished={() => formApi.submitForm()}
+                />
+                <Form.Input
+                  field="interval"
+                  label="Interval"
+                  placeholder="Auto, e.g. 1m"
+                  style={{ width: 120 }}
+                  onBlur={() => formApi.submitForm()}
+                />
+                <Form.Select
+                  field="orderBy[0].field"
+                  label="Order By"
+                  placeholder="Field"
+                  showClear
+                  style={{ minWidth: 160 }}
+                  optionList={(formApi.getValue('fields') || []).map((field: string) => {
+                    return { label: field, value: field, showTick: false };
+                  })}
+                  onChange={() => formApi.submitForm()}
+                />
+                <Form.Checkbox field="orderBy[0].desc" label="Desc" onChange={() => formApi.submitForm()} />
+                <Form.InputNumber
+                  field="limit"
+                  label="Limit"
+                  placeholder="No limit"
+                  min={0}
+                  style={{ width: 120 }}
+                  onBlur={() => formApi.submitForm()}
+                />
+              </>
+            )}
           </>
         )}
       </Form>
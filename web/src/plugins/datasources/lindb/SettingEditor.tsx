@@ -16,6 +16,7 @@ specific language governing permissions and limitations
 under the License.
 */
 import { Form } from '@douyinfe/semi-ui';
+import { HttpSettingForm } from '@src/components';
 import React from 'react';
 
 export const SettingEditor: React.FC = () => {
@@ -38,7 +39,20 @@ export const SettingEditor: React.FC = () => {
           <Form.Input field="config.alias" noLabel style={{ flex: 1 }} placeholder="Namespace alias" />
         </Form.InputGroup>
         <Form.Switch field="config.exemplar" label="Exemplar" />
+        <Form.Select
+          field="config.downsample"
+          label="Downsample"
+          placeholder="None"
+          showClear
+          style={{ width: 200 }}
+          optionList={[
+            { label: 'None', value: 'none' },
+            { label: 'LTTB', value: 'lttb' },
+            { label: 'Min/Max', value: 'minmax' },
+          ]}
+        />
       </Form.Section>
+      <HttpSettingForm auth={false} />
     </>
   );
 };
@@ -75,7 +75,7 @@ const TagValueSelect: React.FC<{
     <Select
       placeholder="Tag value"
       style={{ minWidth: 100 }}
-      multiple={condition.operator === Operator.In}
+      multiple={condition.operator === Operator.In || condition.operator === Operator.NotIn}
       filter
       remote
       allowCreate
@@ -137,14 +137,12 @@ const ConditionInput: React.FC<{
         className="operator"
         showArrow={false}
         defaultValue={condition.operator || Operator.Eq}
-        optionList={[
-          { label: Operator.Eq, value: Operator.Eq, showTick: false },
-          { label: Operator.In, value: Operator.In, showTick: false },
-          { label: Operator.Like, value: Operator.Like, showTick: false },
-        ]}
+        optionList={Object.values(Operator).map((op: Operator) => {
+          return { label: op, value: op, showTick: false };
+        })}
         onChange={(v: any) => {
           condition.operator = v;
-          if (v === Operator.In) {
+          if (v === Operator.In || v === Operator.NotIn) {
             condition.value = [condition.value as string];
           } else if (isArray(condition.value)) {
             condition.value = condition.value[0];
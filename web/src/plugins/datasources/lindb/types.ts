@@ -17,8 +17,23 @@ under the License.
 */
 export enum Operator {
   Eq = '=',
+  NotEq = '!=',
   In = 'in',
+  NotIn = 'not in',
   Like = 'like',
+  NotLike = 'not like',
+  Regexp = '=~',
+  NotRegexp = '!~',
+}
+
+export enum Logic {
+  And = 'and',
+  Or = 'or',
+}
+
+export interface OrderBy {
+  field: string;
+  desc?: boolean;
 }
 
 export interface ConditionExpr {
@@ -26,4 +41,8 @@ export interface ConditionExpr {
   operator: Operator;
   value: string | string[];
   optional?: boolean;
+  not?: boolean;
+  // nested conditions(in parentheses) joined by logic
+  logic?: Logic;
+  conditions?: ConditionExpr[];
 }
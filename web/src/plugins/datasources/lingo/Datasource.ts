@@ -15,6 +15,7 @@ KIND, either express or implied.  See the License for the
 specific language governing permissions and limitations
 under the License.
 */
+import { DataQuerySrv } from '@src/services';
 import { DataSetType, DatasourceAPI, DatasourceSetting, Query } from '@src/types';
 import { TemplateKit } from '@src/utils';
 import { isArray, isEmpty, isString, compact } from 'lodash-es';
@@ -28,9 +29,39 @@ export class LinGoDatasource extends DatasourceAPI {
     if (!query.request) {
       return null;
     }
+    if (query.request.type === 'search' && isArray(query.request.tags)) {
+      // tag filters are edited as key=value list
+      const tags: Record<string, string> = {};
+      query.request.tags.forEach((tag: string) => {
+        const idx = tag.indexOf('=');
+        if (idx > 0) {
+          tags[tag.substring(0, idx).trim()] = tag.substring(idx + 1).trim();
+        }
+      });
+      query.request = { ...query.request, tags: tags };
+    }
     return query;
   }
 
+  async fetchServices(): Promise<string[]> {
+    const rs = await DataQuerySrv.metadataQuery({
+      datasource: { uid: this.setting.uid },
+      request: { type: 'services' },
+    });
+    return rs || [];
+  }
+
+  async fetchOperations(service: string): Promise<string[]> {
+    if (isEmpty(service)) {
+      return [];
+    }
+    const rs = await DataQuerySrv.metadataQuery({
+      datasource: { uid: this.setting.uid },
+      request: { type: 'operations', service: service },
+    });
+    return rs || [];
+  }
+
   rewriteMetaQuery(query: Query, variables: {}, prefix?: string): Query | null {
     return null;
   }
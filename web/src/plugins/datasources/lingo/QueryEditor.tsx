@@ -19,7 +19,8 @@ import { Form } from '@douyinfe/semi-ui';
 import { QueryEditContext } from '@src/contexts';
 import { Query, QueryEditorProps, Tracker } from '@src/types';
 import { get } from 'lodash-es';
-import React, { MutableRefObject, useCallback, useContext, useEffect, useMemo, useRef } from 'react';
+import React, { MutableRefObject, useCallback, useContext, useEffect, useMemo, useRef, useState } from 'react';
+import { LinGoDatasource } from './Datasource';
 import './query-edit.scss';
 
 const QueryEditor: React.FC<QueryEditorProps> = (props) => {
@@ -27,6 +28,10 @@ const QueryEditor: React.FC<QueryEditorProps> = (props) => {
   const { target, modifyTarget } = useContext(QueryEditContext);
   const requestTracker = useRef() as MutableRefObject<Tracker<object>>;
   const formApi = useRef() as MutableRefObject<any>;
+  const [type, setType] = useState<string>(get(target, 'request.type', 'trace'));
+  const [services, setServices] = useState<string[]>([]);
+  const [operations, setOperations] = useState<string[]>([]);
+  const api = datasource.api as LinGoDatasource;
 
   const getInitRequest = useCallback(() => {
     return get(target, 'request', {});
@@ -40,6 +45,19 @@ const QueryEditor: React.FC<QueryEditorProps> = (props) => {
     formApi.current.setValues(getInitRequest());
   }, [datasource, getInitRequest]);
 
+  const loadOperations = async (service: string) => {
+    setOperations(await api.fetchOperations(service));
+  };
+
+  useEffect(() => {
+    if (type !== 'search') {
+      return;
+    }
+    api.fetchServices().then(setServices);
+    loadOperations(get(target, 'request.service', ''));
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [api, type]);
+
   return (
     <>
       <Form
@@ -57,7 +75,83 @@ const QueryEditor: React.FC<QueryEditorProps> = (props) => {
           // change query edit context's values
           modifyTarget({ request: values } as Query);
         }}>
-        <Form.Input label="TraceId" field="traceId" style={{ width: 400 }} />
+        <Form.RadioGroup
+          field="type"
+          type="button"
+          noLabel
+          initValue="trace"
+          onChange={(e: any) => {
+            setType(e.target.value);
+            formApi.current.submitForm();
+          }}>
+          <Form.Radio value="trace">Trace</Form.Radio>
+          <Form.Radio value="search">Search</Form.Radio>
+        </Form.RadioGroup>
+        {type === 'search' ? (
+          <>
+            <Form.Select
+              label="Service"
+              field="service"
+              filter
+              showClear
+              style={{ width: 200 }}
+              optionList={services.map((s: string) => ({ label: s, value: s }))}
+              onChange={(value: any) => {
+                formApi.current.setValue('operation', undefined);
+                loadOperations(value);
+                formApi.current.submitForm();
+              }}
+            />
+            <Form.Select
+              label="Operation"
+              field="operation"
+              filter
+              showClear
+              style={{ width: 200 }}
+              optionList={operations.map((o: string) => ({ label: o, value: o }))}
+              onChange={() => formApi.current.submitForm()}
+            />
+            <Form.TagInput
+              label="Tags"
+              field="tags"
+              placeholder="key=value"
+              style={{ width: 300 }}
+              onChange={() => formApi.current.submitForm()}
+            />
+            <Form.Input
+              label="Duration"
+              field="minDuration"
+              placeholder="Min, e.g. 100ms"
+              style={{ width: 130 }}
+              onEnterPress={() => formApi.current.submitForm()}
+              onBlur={() => formApi.current.submitForm()}
+            />
+            <Form.Input
+              noLabel
+              field="maxDuration"
+              placeholder="Max, e.g. 1.5s"
+              style={{ width: 130 }}
+              onEnterPress={() => formApi.current.submitForm()}
+              onBlur={() => formApi.current.submitForm()}
+            />
+            <Form.InputNumber
+              label="Limit"
+              field="limit"
+              placeholder="20"
+              min={1}
+              style={{ width: 100 }}
+              onBlur={() => formApi.current.submitForm()}
+            />
+          </>
+        ) : (
+          <Form.Input
+            label="TraceId"
+            field="traceId"
+            style={{ width: 400 }}
+            onEnterPress={() => formApi.current.submitForm()}
+            onBlur={() => formApi.current.submitForm()}
+          />
+        )}
       </Form>
     </>
   );
@@ -16,6 +16,7 @@ specific language governing permissions and limitations
 under the License.
 */
 import { Form } from '@douyinfe/semi-ui';
+import { HttpSettingForm } from '@src/components';
 import React from 'react';
 
 export const SettingEditor: React.FC = () => {
@@ -34,6 +35,7 @@ export const SettingEditor: React.FC = () => {
           rules={[{ required: true, message: 'Pipeline is required' }]}
         />
       </Form.Section>
+      <HttpSettingForm />
     </>
   );
 };
@@ -16,7 +16,7 @@ specific language governing permissions and limitations
 under the License.
 */
 import { ApiPath } from '@src/constants';
-import { DatasourceSetting } from '@src/types';
+import { DatasourcePermission, DatasourceSetting } from '@src/types';
 import { ApiKit } from '@src/utils';
 
 const createDatasource = (ds: DatasourceSetting): Promise<string> => {
@@ -39,10 +39,35 @@ const fetchDatasources = (): Promise<DatasourceSetting[]> => {
   return ApiKit.GET<DatasourceSetting[]>(ApiPath.Datasources);
 };
 
+const getDatasourcePermissions = (uid: string): Promise<DatasourcePermission[]> => {
+  return ApiKit.GET<DatasourcePermission[]>(`${ApiPath.Datasources}/${uid}/permissions`);
+};
+
+const saveDatasourcePermissions = (uid: string, permissions: DatasourcePermission[]): Promise<string> => {
+  return ApiKit.PUT<string>(`${ApiPath.Datasources}/${uid}/permissions`, permissions);
+};
+
+const getProxyURL = (uid: string, path: string): string => {
+  return `${ApiPath.Datasources}/${uid}/proxy/${path.replace(/^\/+/, '')}`;
+};
+
+const proxyGET = <T>(uid: string, path: string, params?: { [index: string]: any }): Promise<T> => {
+  return ApiKit.GET<T>(getProxyURL(uid, path), params);
+};
+
+const proxyPUT = <T>(uid: string, path: string, params?: { [index: string]: any }): Promise<T> => {
+  return ApiKit.PUT<T>(getProxyURL(uid, path), params);
+};
+
 export default {
   createDatasource,
   updateDatasource,
   getDatasource,
   deleteDatasource,
   fetchDatasources,
+  getDatasourcePermissions,
+  saveDatasourcePermissions,
+  getProxyURL,
+  proxyGET,
+  proxyPUT,
 };
@@ -16,11 +16,28 @@ specific language governing permissions and limitations
 under the License.
 */
 import { ApiPath } from '@src/constants';
-import { DataQuery, Query } from '@src/types';
-import { ApiKit } from '@src/utils';
+import { DataQuery, DataQueryResult, Query, QueryResponse } from '@src/types';
+import { ApiKit, FrameKit } from '@src/utils';
+import { forIn, isEmpty } from 'lodash-es';
 
-const dataQuery = (req: DataQuery): Promise<any> => {
-  return ApiKit.PUT<any>(ApiPath.DataQuery, req);
+/*
+ * Query data, returns data of each query keyed by refId, converts frames to the data format which panels consume.
+ * Returns errors of failure queries alongside data of successful queries, throws error if all queries failure.
+ */
+const dataQuery = async (req: DataQuery): Promise<DataQueryResult> => {
+  const resp = await ApiKit.PUT<QueryResponse>(ApiPath.DataQuery, req);
+  const rs: DataQueryResult = { data: {}, errors: [] };
+  forIn(resp?.results, (result, refId: string) => {
+    if (result.status === 'error') {
+      rs.errors.push(new Error(`${refId}: ${result.error}`));
+      return;
+    }
+    rs.data[refId] = FrameKit.toData(result.frames || []);
+  });
+  if (isEmpty(rs.data) && !isEmpty(rs.errors)) {
+    throw new Error(rs.errors.map((err: Error) => err.message).join('; '));
+  }
+  return rs;
 };
 
 const metadataQuery = (req: Query): Promise<any> => {
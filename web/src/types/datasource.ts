@@ -26,14 +26,19 @@ export enum DatasourceCategory {
   Trace = 'trace',
 }
 
+// from/to are epoch milliseconds or time expressions resolved by server, like now-6h, now/d
 export interface TimeRange {
-  from?: number;
-  to?: number;
+  from?: number | string;
+  to?: number | string;
 }
 
 export interface DataQuery {
   queries: Query[];
   range?: TimeRange;
+  // values of variables, expanded in query request by server
+  variables?: object;
+  // time zone which relative time is rounded in, use time zone of datasource if not set
+  timeZone?: string;
 }
 
 export interface Query {
@@ -43,6 +48,85 @@ export interface Query {
   request: any;
   legendFormat?: string;
   includeField?: boolean;
+  // max number of data points and lower limit of interval, used to calculate interval by server
+  maxDataPoints?: number;
+  minInterval?: string;
+  // query over the time range shifted back(like 1d/1w), result is moved to current time range by server
+  timeShift?: string;
+  // algorithm which reduces points of series to max data points(lttb/minmax/none), use datasource setting if not set
+  downsample?: string;
+}
+
+export interface QueryResult {
+  refId: string;
+  status: 'ok' | 'error';
+  error?: string;
+  duration: number;
+  frames?: Frame[];
+  meta?: QueryMeta;
+}
+
+export interface QueryMeta {
+  datasource?: { uid: string; name?: string; type?: string };
+  executedQueries?: string[];
+  // cost of backend query(nanoseconds), zero if result returned from cache
+  backendDuration: number;
+  series: number;
+  points: number;
+  rows: number;
+  cache?: { hit: boolean; cachedAt: number };
+}
+
+export enum FrameType {
+  TimeSeries = 'timeseries',
+  Table = 'table',
+  Logs = 'logs',
+  Trace = 'trace',
+}
+
+export enum FieldType {
+  Time = 'time',
+  Number = 'number',
+  Integer = 'integer',
+  String = 'string',
+  Boolean = 'boolean',
+  Other = 'other',
+}
+
+export interface FrameField {
+  name: string;
+  type: FieldType;
+  labels?: { [key: string]: string };
+  config?: { displayName?: string; unit?: string };
+}
+
+export interface FrameMeta {
+  type?: FrameType;
+  interval?: number;
+  timeRange?: TimeRange;
+  executedQuery?: string;
+  custom?: { [key: string]: any };
+}
+
+export interface Frame {
+  schema: {
+    name?: string;
+    refId?: string;
+    meta?: FrameMeta;
+    fields: FrameField[];
+  };
+  data: { values: any[][] };
+}
+
+export interface QueryResponse {
+  results: { [refId: string]: QueryResult };
+}
+
+export interface DataQueryResult {
+  // data of successful queries keyed by refId
+  data: { [refId: string]: any };
+  // errors of failure queries, message is prefixed by refId
+  errors: Error[];
 }
 
 export interface DatasourceInstance {
@@ -59,6 +143,16 @@ export interface DatasourceSetting {
   url: string;
   timeZone?: string;
   isDefault: boolean;
+  // secrets are write only, secureConfigFields shows which secrets are set(key like http.bearerToken)
+  secureConfig?: object;
+  secureConfigFields?: Record<string, boolean>;
+}
+
+// either role or teamUid is set, edit permission includes query permission
+export interface DatasourcePermission {
+  role?: string;
+  teamUid?: string;
+  action: 'query' | 'edit';
 }
 
 export interface QueryEditorProps {
@@ -69,7 +69,7 @@ async function DELETE<T>(url: string): Promise<T> {
 }
 
 const getErrorMsg = (err: any) => {
-  return _.get(err, 'response.data', 'Unknown internal error');
+  return _.get(err, 'response.data', _.get(err, 'message', 'Unknown internal error'));
 };
 
 const getErrorCode = (err: any) => {
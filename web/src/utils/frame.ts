@@ -0,0 +1,100 @@
+/*
+Licensed to LinDB under one or more contributor
+license agreements. See the NOTICE file distributed with
+this work for additional information regarding copyright
+ownership. LinDB licenses this file to you under
+the Apache License, Version 2.0 (the "License"); you may
+not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+ 
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+import { FieldType, Frame, FrameType, Trace } from '@src/types';
+import { every, isEmpty } from 'lodash-es';
+
+/*
+ * Convert time series frames to the result set of LinDB, one series per frame.
+ */
+const toResultSet = (frames: Frame[]): any => {
+  const first = frames[0];
+  const rs: any = {
+    metricName: first.schema.name,
+    startTime: first.schema.meta?.timeRange?.from,
+    endTime: first.schema.meta?.timeRange?.to,
+    interval: first.schema.meta?.interval,
+    series: [],
+  };
+  frames.forEach((frame: Frame) => {
+    const fields = frame.schema.fields;
+    const timeIdx = fields.findIndex((field) => field.type === FieldType.Time);
+    if (timeIdx < 0) {
+      return;
+    }
+    const timestamps = frame.data.values[timeIdx] || [];
+    const series: any = { tags: {}, fields: {} };
+    fields.forEach((field, idx) => {
+      if (idx === timeIdx) {
+        return;
+      }
+      series.tags = field.labels || series.tags;
+      const points: { [timestamp: number]: number } = {};
+      (frame.data.values[idx] || []).forEach((value: any, row: number) => {
+        if (value !== null && value !== undefined) {
+          points[timestamps[row]] = value;
+        }
+      });
+      series.fields[field.name] = points;
+    });
+    rs.series.push(series);
+  });
+  return rs;
+};
+
+/*
+ * Convert trace frames to traces, one trace per frame(process), one span per row.
+ */
+const toTraces = (frames: Frame[]): Trace[] => {
+  return frames.map((frame: Frame) => {
+    const fields = frame.schema.fields;
+    const rows = isEmpty(fields) ? 0 : (frame.data.values[0] || []).length;
+    const spans: any[] = [];
+    for (let row = 0; row < rows; row++) {
+      const span: any = {};
+      fields.forEach((field, idx) => {
+        span[field.name] = frame.data.values[idx][row];
+      });
+      spans.push(span);
+    }
+    return { process: frame.schema.meta?.custom?.process, spans: spans };
+  });
+};
+
+/*
+ * Convert frames of one query to the data format which panels consume,
+ * time series => result set of LinDB, trace => traces, others keep frames.
+ */
+const toData = (frames: Frame[]): any => {
+  if (isEmpty(frames)) {
+    return null;
+  }
+  const isType = (type: FrameType) => every(frames, (frame: Frame) => frame.schema.meta?.type === type);
+  if (isType(FrameType.TimeSeries)) {
+    return toResultSet(frames);
+  }
+  if (isType(FrameType.Trace)) {
+    return toTraces(frames);
+  }
+  return frames;
+};
+
+export default {
+  toResultSet,
+  toTraces,
+  toData,
+};
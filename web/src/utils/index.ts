@@ -30,3 +30,4 @@ export { default as DNDKit } from '@src/utils/dnd';
 export { default as TimeKit } from '@src/utils/time';
 export { default as TraceKit } from '@src/utils/trace';
 export { default as DatasourceKit } from '@src/utils/datasource';
+export { default as FrameKit } from '@src/utils/frame';